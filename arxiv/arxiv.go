@@ -63,7 +63,6 @@ import (
 	"fmt"
 	"io"
 	"iter"
-	"math"
 	"math/rand/v2"
 	"net/http"
 	"net/url"
@@ -76,14 +75,20 @@ import (
 
 // Client represents an arXiv API client.
 type Client struct {
-	BaseURL       string        // Base URL for the arXiv API
-	RequestMethod RequestMethod // HTTP request method to use
-	Timeout       time.Duration // Timeout for the HTTP request
-	RateLimit     time.Duration // How long to wait between requests
-	RetryConfig   *RetryConfig  // Configuration for retry
-	interceptors  []Interceptor // Interceptors for modifying search behavior
-	httpClient    *http.Client
-	rateLimiter   *rate.Limiter
+	BaseURL         string        // Base URL for the arXiv API
+	RequestMethod   RequestMethod // HTTP request method to use
+	Timeout         time.Duration // Timeout for the HTTP request
+	RateLimit       time.Duration // How long to wait between requests (DefaultRateLimit = shared per-host limiter)
+	RetryConfig     *RetryConfig  // Configuration for retry
+	RetryPolicy     RetryPolicy   // Policy deciding which failures are retried (nil = derived from RetryConfig)
+	MaxGetURLLength int           // GET requests with longer URLs are sent as POST instead (0 = never switch)
+	Concurrency     int           // Maximum number of searches SearchMany runs at once (0 = DefaultConcurrency)
+	DownloadBaseURL string        // Site PDFs and source files are downloaded from
+	FeedBaseURL     string        // Site announcement feeds are fetched from
+	interceptors    []Interceptor // Interceptors for modifying search behavior
+	backend         SearchFunc    // Answers searches in place of the API, if set
+	httpClient      *http.Client
+	limiter         Limiter
 }
 
 // RetryConfig configures retry behavior.
@@ -92,6 +97,9 @@ type RetryConfig struct {
 	InitialInterval time.Duration // Initial backoff interval
 	MaxInterval     time.Duration // Maximum backoff interval
 	Multiplier      float64       // Backoff multiplier (typically 2.0)
+	Jitter          float64       // Fraction of each backoff to randomize by (0 = 0.1, negative = no jitter)
+	Rand            *rand.Rand    // Source of randomness for jitter, e.g. a seeded rand.New for tests (nil = global source)
+	MaxElapsedTime  time.Duration // Total time a single call may spend on attempts and backoff (0 = unlimited)
 }
 
 type ClientOption func(*Client)
@@ -111,22 +119,34 @@ type Interceptor func(ctx context.Context, params SearchParams, next SearchFunc)
 // NewClient creates a new arXiv API client with the given options.
 func NewClient(options ...ClientOption) *Client {
 	client := &Client{
-		BaseURL:       "http://export.arxiv.org/api/query",
-		RequestMethod: RequestMethodGet,
-		Timeout:       10 * time.Second,
-		RateLimit:     3,
+		BaseURL:         "http://export.arxiv.org/api/query",
+		RequestMethod:   RequestMethodGet,
+		Timeout:         10 * time.Second,
+		RateLimit:       DefaultRateLimit,
+		MaxGetURLLength: DefaultMaxGetURLLength,
+		DownloadBaseURL: DefaultDownloadBaseURL,
+		FeedBaseURL:     DefaultFeedBaseURL,
 	}
 
 	for _, option := range options {
 		option(client)
 	}
 
-	if client.RateLimit > 0 {
-		client.rateLimiter = rate.NewLimiter(rate.Every(client.RateLimit), 1)
+	if client.limiter == nil {
+		switch {
+		case client.RateLimit == DefaultRateLimit:
+			// Share one schedule per host so that clients in the same
+			// process do not add up to more than arXiv allows.
+			client.limiter = SharedLimiter(client.BaseURL)
+		case client.RateLimit > 0:
+			client.limiter = NewIntervalLimiter(client.RateLimit)
+		}
 	}
 
-	client.httpClient = &http.Client{
-		Timeout: client.Timeout,
+	if client.httpClient == nil {
+		client.httpClient = &http.Client{
+			Timeout: client.Timeout,
+		}
 	}
 
 	return client
@@ -144,6 +164,15 @@ func WithRequestMethod(method RequestMethod) ClientOption {
 	}
 }
 
+// WithMaxGetURLLength sets the URL length above which GET requests are
+// automatically sent as POST, so that long ID lists and queries are not
+// rejected or truncated. A value of 0 disables the switch.
+func WithMaxGetURLLength(length int) ClientOption {
+	return func(c *Client) {
+		c.MaxGetURLLength = length
+	}
+}
+
 func WithTimeout(timeout time.Duration) ClientOption {
 	return func(c *Client) {
 		c.Timeout = timeout
@@ -156,15 +185,31 @@ func WithRateLimit(rateLimit time.Duration) ClientOption {
 	}
 }
 
+// WithHTTPClient sends requests with httpClient, for example one with a
+// custom transport. Its own Timeout applies instead of the client's.
 func WithHTTPClient(httpClient *http.Client) ClientOption {
 	return func(c *Client) {
 		c.httpClient = httpClient
 	}
 }
 
-func WithRateLimiter(rateLimiter *rate.Limiter) ClientOption {
+// WithRateLimiter paces requests with a *rate.Limiter from golang.org/x/time/rate.
+// A nil limiter disables rate limiting.
+func WithRateLimiter(limiter *rate.Limiter) ClientOption {
+	return func(c *Client) {
+		if limiter == nil {
+			c.limiter, c.RateLimit = nil, 0
+			return
+		}
+		c.limiter = rateLimiter{limiter: limiter}
+	}
+}
+
+// WithLimiter paces requests with the given Limiter, which may be shared with
+// other clients. It takes precedence over WithRateLimit.
+func WithLimiter(limiter Limiter) ClientOption {
 	return func(c *Client) {
-		c.rateLimiter = rateLimiter
+		c.limiter = limiter
 	}
 }
 
@@ -223,6 +268,16 @@ func WithInterceptor(interceptors ...Interceptor) ClientOption {
 	}
 }
 
+// WithSearchFunc answers searches with fn instead of requests to the arXiv
+// API, for example from a local corpus. Interceptors still wrap fn, and
+// SearchNext, SearchIter and the other methods built on Search work
+// unchanged as long as fn fills in the pagination fields of its results.
+func WithSearchFunc(fn SearchFunc) ClientOption {
+	return func(c *Client) {
+		c.backend = fn
+	}
+}
+
 // RequestMethod specifies the HTTP method for API requests. ArXiv's API supports
 // both GET and POST methods for search queries.
 type RequestMethod int
@@ -232,6 +287,10 @@ const (
 	RequestMethodPost
 )
 
+// DefaultMaxGetURLLength is the default URL length above which a client
+// configured for GET sends its request as POST instead.
+const DefaultMaxGetURLLength = 2000
+
 // SearchParams contains parameters for making a search request to the arXiv API.
 // See the [arXiv API documentation] for more information on the available
 // parameters and constructing queries.
@@ -329,130 +388,85 @@ type Link struct {
 	Title string `xml:"title,attr" json:"title,omitempty"`
 }
 
-// isRetryableError determines if an error is retryable.
-// Retryable errors include temporary network failures and specific HTTP status codes.
-func isRetryableError(err error, response *http.Response) bool {
-	if err != nil {
-		// Retry on timeout or temporary network errors
-		if errors.Is(err, context.DeadlineExceeded) {
-			return true
-		}
-		// Check for temporary network errors
-		if urlErr, ok := err.(*url.Error); ok && urlErr.Temporary() {
-			return true
-		}
-	}
-
-	if response != nil {
-		// Retry on specific HTTP status codes
-		switch response.StatusCode {
-		case http.StatusTooManyRequests, // 429
-			http.StatusRequestTimeout,      // 408
-			http.StatusInternalServerError, // 500
-			http.StatusBadGateway,          // 502
-			http.StatusServiceUnavailable,  // 503
-			http.StatusGatewayTimeout:      // 504
-			return true
-		}
-	}
-
-	return false
-}
-
-// calculateBackoff calculates the next backoff interval with jitter.
-func calculateBackoff(attempt int, config *RetryConfig) time.Duration {
-	if config == nil || attempt <= 0 {
-		return 0
-	}
-
-	// Calculate exponential backoff
-	backoff := float64(config.InitialInterval) * math.Pow(config.Multiplier, float64(attempt-1))
-
-	// Cap at max interval
-	if backoff > float64(config.MaxInterval) {
-		backoff = float64(config.MaxInterval)
-	}
-
-	// Add jitter (±10% randomization)
-	jitter := 0.1 * backoff * (2*rand.Float64() - 1)
-	backoff += jitter
-
-	return time.Duration(backoff)
+// Limiter returns the limiter pacing the client's requests, or nil if
+// requests are not rate limited.
+func (c *Client) Limiter() Limiter {
+	return c.limiter
 }
 
 // RawSearch makes a search request to the arXiv API and returns the raw HTTP response.
 // The caller is responsible for closing the response body.
-// If RetryConfig is set, the method will automatically retry on transient failures
+// If a retry policy is configured, the method will automatically retry on transient failures.
 func (c *Client) RawSearch(ctx context.Context, params SearchParams) (*http.Response, error) {
 	if err := params.Validate(); err != nil {
 		return nil, err
 	}
 
-	// Determine max attempts
-	maxAttempts := 1
-	if c.RetryConfig != nil && c.RetryConfig.MaxAttempts > 0 {
-		maxAttempts = c.RetryConfig.MaxAttempts
-	}
-
-	var lastErr error
-	var lastResponse *http.Response
-
-	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		// Apply rate limiting
-		if c.rateLimiter != nil {
-			if err := c.rateLimiter.Wait(ctx); err != nil {
-				return nil, err
+	return withRetry(ctx, c, func(ctx context.Context) (*http.Response, *http.Response, error) {
+		response, err := c.doRequest(ctx, params)
+		return response, response, err
+	}, func(response *http.Response) {
+		// Close the response body if it exists before retrying
+		if response != nil && response.Body != nil {
+			response.Body.Close()
+		}
+	})
+}
+
+// Get makes a GET request to url, paced by the client's rate limiter and
+// retried according to its retry policy, and returns the last response
+// whatever its status. It lets packages built on the client, such as oaipmh,
+// share its HTTP client, limiter and retry configuration. The caller is
+// responsible for closing the response body.
+func (c *Client) Get(ctx context.Context, url string) (*http.Response, error) {
+	return withRetry(ctx, c, func(ctx context.Context) (*http.Response, *http.Response, error) {
+		if c.limiter != nil {
+			if _, err := c.limiter.Wait(ctx); err != nil {
+				return nil, nil, err
 			}
 		}
-
-		// Make the request
-		var response *http.Response
-		var err error
-		if c.RequestMethod == RequestMethodGet {
-			response, err = DoGetRequest(ctx, c, params)
-		} else {
-			response, err = DoPostRequest(ctx, c, params)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, nil, err
 		}
-
-		// If successful, return immediately
-		if err == nil && response != nil && response.StatusCode == http.StatusOK {
-			return response, nil
+		response, err := c.httpClient.Do(req)
+		return response, response, err
+	}, func(response *http.Response) {
+		if response != nil && response.Body != nil {
+			response.Body.Close()
 		}
+	})
+}
 
-		// Store the last error and response for potential return
-		lastErr = err
-		lastResponse = response
-
-		// Check if we should retry
-		if attempt < maxAttempts && isRetryableError(err, response) {
-			// Close the response body if it exists before retrying
-			if response != nil && response.Body != nil {
-				response.Body.Close()
-			}
-
-			// Calculate backoff and wait
-			backoff := calculateBackoff(attempt, c.RetryConfig)
-			if backoff > 0 {
-				select {
-				case <-time.After(backoff):
-					// Continue to next attempt
-				case <-ctx.Done():
-					return nil, ctx.Err()
-				}
-			}
-		} else {
-			// Not retryable or last attempt, return the result
-			break
+// doRequest applies rate limiting and makes a single request to the API.
+func (c *Client) doRequest(ctx context.Context, params SearchParams) (*http.Response, error) {
+	if c.limiter != nil {
+		if _, err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
 		}
 	}
+	if c.RequestMethod == RequestMethodGet && !c.exceedsMaxGetURLLength(params) {
+		return DoGetRequest(ctx, c, params)
+	}
+	return DoPostRequest(ctx, c, params)
+}
 
-	return lastResponse, lastErr
+// exceedsMaxGetURLLength reports whether a GET request for params would have
+// a URL longer than the client allows.
+func (c *Client) exceedsMaxGetURLLength(params SearchParams) bool {
+	if c.MaxGetURLLength <= 0 {
+		return false
+	}
+	return len(c.BaseURL)+1+len(makeGetQuery(params)) > c.MaxGetURLLength
 }
 
 // Search makes a search request to the arXiv API and returns the parsed response.
 func (c *Client) Search(ctx context.Context, params SearchParams) (SearchResults, error) {
 	// Build the interceptor chain
 	searchFunc := c.doSearch
+	if c.backend != nil {
+		searchFunc = c.backend
+	}
 
 	// Apply interceptors in reverse order (first added = outermost)
 	for i := len(c.interceptors) - 1; i >= 0; i-- {
@@ -468,21 +482,43 @@ func (c *Client) Search(ctx context.Context, params SearchParams) (SearchResults
 }
 
 // doSearch performs the actual search operation.
-// This is the core implementation that interceptors wrap.
+// This is the core implementation that interceptors wrap. Parsing happens
+// inside the retry loop so that malformed and empty pages can be retried.
 func (c *Client) doSearch(ctx context.Context, params SearchParams) (SearchResults, error) {
-	response, err := c.RawSearch(ctx, params)
-	if err != nil {
+	if err := params.Validate(); err != nil {
 		return SearchResults{}, err
 	}
-	defer response.Body.Close()
 
-	parsedResponse, err := ParseResponse(response.Body)
-	if err != nil {
-		return SearchResults{}, err
+	results, err := withRetry(ctx, c, func(ctx context.Context) (SearchResults, *http.Response, error) {
+		response, err := c.doRequest(ctx, params)
+		if err != nil {
+			return SearchResults{}, response, err
+		}
+		defer response.Body.Close()
+
+		parsedResponse, err := ParseResponse(response.Body)
+		if err != nil {
+			return SearchResults{}, response, fmt.Errorf("%w: %w", ErrMalformedResponse, err)
+		}
+		parsedResponse.Params = params
+		if response.StatusCode == http.StatusOK && isEmptyPage(parsedResponse) {
+			return parsedResponse, response, ErrEmptyPage
+		}
+
+		return parsedResponse, response, nil
+	}, nil)
+	// An empty page is only an error while it may be retried; once the
+	// policy gives up, or without one, it is returned as arXiv sent it.
+	if errors.Is(err, ErrEmptyPage) {
+		return results, nil
 	}
-	parsedResponse.Params = params
+	return results, err
+}
 
-	return parsedResponse, nil
+// isEmptyPage reports whether a page is missing the entries its own
+// pagination metadata promises.
+func isEmptyPage(response SearchResults) bool {
+	return len(response.Entries) == 0 && response.ItemsPerPage > 0 && response.StartIndex < response.TotalResults
 }
 
 // SearchNext retrieves the next page of results based on the current SearchResults.
@@ -551,30 +587,15 @@ func DoGetRequest(ctx context.Context, client *Client, params SearchParams) (*ht
 }
 
 // DoPostRequest performs a POST request to the arXiv API with the specified parameters.
+// The parameters are sent as an application/x-www-form-urlencoded body using
+// the same encoding as the query string of a GET request.
 func DoPostRequest(ctx context.Context, client *Client, params SearchParams) (*http.Response, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, client.BaseURL, nil)
+	body := makeGetQuery(params)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, client.BaseURL, strings.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
-	if params.Query != "" {
-		req.Header.Add("search_query", params.Query)
-	}
-	if len(params.IdList) > 0 {
-		idListStr := strings.Join(params.IdList, ",")
-		req.Header.Add("id_list", idListStr)
-	}
-	if params.Start > 0 {
-		req.Header.Add("start", strconv.Itoa(params.Start))
-	}
-	if params.MaxResults > 0 {
-		req.Header.Add("max_results", strconv.Itoa(params.MaxResults))
-	}
-	if params.SortBy != "" {
-		req.Header.Add("sortBy", string(params.SortBy))
-	}
-	if params.SortOrder != "" {
-		req.Header.Add("sortOrder", string(params.SortOrder))
-	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	response, err := client.httpClient.Do(req)
 	if err != nil {
 		return nil, err
@@ -2,6 +2,8 @@ package arxiv
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -85,67 +87,98 @@ func TestMakeGetQuery(t *testing.T) {
 	}
 }
 
-func TestGetRequestGetsOKResponseWithDefaultConfig(t *testing.T) {
-	client := NewClient()
-	ctx := context.Background()
+func TestPostRequestWireFormat(t *testing.T) {
+	var gotMethod, gotContentType, gotBody, gotRawQuery string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotContentType = r.Header.Get("Content-Type")
+		gotRawQuery = r.URL.RawQuery
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer mockServer.Close()
+
+	client := NewClient(
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithRequestMethod(RequestMethodPost),
+	)
 	params := SearchParams{
-		Query: "all:electron",
+		Query:      "au:del_maestro ANDNOT ti:checkerboard",
+		IdList:     []string{"2408.03982", "2408.03988"},
+		Start:      10,
+		MaxResults: 5,
+		SortBy:     SortBySubmittedDate,
+		SortOrder:  SortOrderDescending,
 	}
-	resp, err := DoGetRequest(ctx, client, params)
+
+	resp, err := DoPostRequest(context.Background(), client, params)
 	if err != nil {
-		t.Errorf("DoGetRequest(%v) = %v; want nil", params, err)
-	}
-	if resp.StatusCode != 200 {
-		t.Errorf("DoGetRequest(%v) = %v; want 200", params, resp.StatusCode)
+		t.Fatalf("DoPostRequest() = %v; want nil", err)
 	}
-}
+	resp.Body.Close()
 
-func TestPostRequestGetsOKResponseWithDefaultConfig(t *testing.T) {
-	client := NewClient(WithRequestMethod(RequestMethodPost))
-	ctx := context.Background()
-	params := SearchParams{
-		Query: "all:electron",
+	if gotMethod != http.MethodPost {
+		t.Errorf("method = %v; want POST", gotMethod)
 	}
-	resp, err := DoPostRequest(ctx, client, params)
-	if err != nil {
-		t.Errorf("DoPostRequest(%v) = %v; want nil", params, err)
+	if gotContentType != "application/x-www-form-urlencoded" {
+		t.Errorf("Content-Type = %v; want application/x-www-form-urlencoded", gotContentType)
+	}
+	if gotRawQuery != "" {
+		t.Errorf("query string = %v; want empty", gotRawQuery)
 	}
-	if resp.StatusCode != 200 {
-		t.Errorf("DoPostRequest(%v) = %v; want 200", params, resp.StatusCode)
+	// Spaces are sent as + and colons and commas escaped, as form encoding
+	// requires, with the parameters sorted by name.
+	want := "id_list=2408.03982%2C2408.03988&max_results=5" +
+		"&search_query=au%3Adel_maestro+ANDNOT+ti%3Acheckerboard" +
+		"&sortBy=submittedDate&sortOrder=descending&start=10"
+	if gotBody != want {
+		t.Errorf("body = %v; want %v", gotBody, want)
 	}
 }
 
-func TestSearchWorksWithDefaultConfig(t *testing.T) {
-	client := NewClient()
+func TestGetSwitchesToPostForLongURLs(t *testing.T) {
+	var methods []string
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm() = %v", err)
+		}
+		if r.Form.Get("id_list") == "" {
+			t.Errorf("%s request is missing id_list", r.Method)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:opensearch="http://a9.com/-/spec/opensearch/1.1/">
+  <opensearch:totalResults>0</opensearch:totalResults>
+</feed>`))
+	}))
+	defer mockServer.Close()
+
+	client := NewClient(
+		WithBaseURL(mockServer.URL),
+		WithHTTPClient(mockServer.Client()),
+		WithRateLimit(0),
+		WithMaxGetURLLength(200),
+	)
 	ctx := context.Background()
-	params := SearchParams{
-		Query: "all:electron",
-	}
-	_, err := client.Search(ctx, params)
-	if err != nil {
-		t.Errorf("Search(%v) = %v; want nil", params, err)
+
+	short := SearchParams{IdList: []string{"2408.03982"}}
+	if _, err := client.Search(ctx, short); err != nil {
+		t.Fatalf("Search() = %v; want nil", err)
 	}
-}
 
-func TestSearchIteratesOverMultiplePages(t *testing.T) {
-	client := NewClient()
-	ctx := context.Background()
-	params := SearchParams{
-		Query:      "all:electron",
-		MaxResults: 10,
+	var long SearchParams
+	for i := 0; i < 50; i++ {
+		long.IdList = append(long.IdList, fmt.Sprintf("2408.%05d", i))
 	}
-	count := 0
-	for result := range client.SearchIter(ctx, params) {
-		if result.ID == "" {
-			t.Errorf("SearchIter() = %v; want non-empty string", result.ID)
-		}
-		count++
-		if count > 30 {
-			break
-		}
+	if _, err := client.Search(ctx, long); err != nil {
+		t.Fatalf("Search() = %v; want nil", err)
 	}
-	if count < 30 {
-		t.Errorf("SearchIter() = %v; want at least 30 results", count)
+
+	if len(methods) != 2 || methods[0] != http.MethodGet || methods[1] != http.MethodPost {
+		t.Errorf("methods = %v; want [GET POST]", methods)
 	}
 }
 
@@ -309,59 +342,6 @@ func TestRateLimit(t *testing.T) {
 	}
 }
 
-func TestSearchNext(t *testing.T) {
-	client := NewClient()
-	ctx := context.Background()
-	params := SearchParams{
-		Query:      "all:electron",
-		MaxResults: 10,
-	}
-
-	response, err := client.Search(ctx, params)
-	if err != nil {
-		t.Fatalf("Search() = %v; want nil", err)
-	}
-
-	if SearchHasMoreResults(response) {
-		nextResponse, err := client.SearchNext(ctx, response)
-		if err != nil {
-			t.Errorf("SearchNext() = %v; want nil", err)
-		}
-		if nextResponse.StartIndex != response.StartIndex+response.ItemsPerPage {
-			t.Errorf("SearchNext().StartIndex = %v; want %v", nextResponse.StartIndex, response.StartIndex+response.ItemsPerPage)
-		}
-	}
-}
-
-func TestSearchPrevious(t *testing.T) {
-	client := NewClient()
-	ctx := context.Background()
-	params := SearchParams{
-		Query:      "all:electron",
-		Start:      20,
-		MaxResults: 10,
-	}
-
-	response, err := client.Search(ctx, params)
-	if err != nil {
-		t.Fatalf("Search() = %v; want nil", err)
-	}
-
-	if SearchHasPreviousResults(response) {
-		prevResponse, err := client.SearchPrevious(ctx, response)
-		if err != nil {
-			t.Errorf("SearchPrevious() = %v; want nil", err)
-		}
-		expectedStart := response.StartIndex - response.ItemsPerPage
-		if expectedStart < 0 {
-			expectedStart = 0
-		}
-		if prevResponse.StartIndex != expectedStart {
-			t.Errorf("SearchPrevious().StartIndex = %v; want %v", prevResponse.StartIndex, expectedStart)
-		}
-	}
-}
-
 func TestClientOptions(t *testing.T) {
 	customTimeout := 30 * time.Second
 	customRateLimit := 5 * time.Second
@@ -388,6 +368,17 @@ func TestClientOptions(t *testing.T) {
 	}
 }
 
+func TestWithHTTPClient(t *testing.T) {
+	httpClient := &http.Client{Timeout: time.Minute}
+	client := NewClient(WithHTTPClient(httpClient), WithTimeout(time.Second))
+	if client.httpClient != httpClient {
+		t.Errorf("NewClient replaced the HTTP client given with WithHTTPClient")
+	}
+	if client := NewClient(WithTimeout(time.Second)); client.httpClient == nil || client.httpClient.Timeout != time.Second {
+		t.Errorf("default HTTP client = %+v; want Timeout 1s", client.httpClient)
+	}
+}
+
 func TestWithDefaultRetry(t *testing.T) {
 	client := NewClient(WithDefaultRetry())
 
@@ -433,6 +424,21 @@ func TestWithRateLimiter(t *testing.T) {
 	if client2 == nil {
 		t.Error("Client with multiple rate options should not be nil")
 	}
+
+	// A nil limiter disables rate limiting rather than panicking on use.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, testXMLResponse)
+	}))
+	defer server.Close()
+	unlimited := NewClient(WithBaseURL(server.URL), WithRateLimiter(nil))
+	if unlimited.Limiter() != nil {
+		t.Errorf("WithRateLimiter(nil) Limiter() = %v; want nil", unlimited.Limiter())
+	}
+	for range 2 {
+		if _, err := unlimited.Search(context.Background(), SearchParams{Query: "test"}); err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+	}
 }
 
 func TestValidate(t *testing.T) {
@@ -0,0 +1,202 @@
+// Package arxivtest provides a fake arXiv API server for tests.
+//
+// The server answers search requests from in-memory entries, evaluating
+// search_query with the same query syntax as the API and honoring id_list,
+// start, max_results, sortBy and sortOrder, so that code using an
+// arxiv.Client can be tested without the network:
+//
+//	server := arxivtest.NewServer(arxivtest.Generate(25)...)
+//	defer server.Close()
+//
+//	client := server.Client()
+//	for entry := range client.SearchIter(ctx, arxiv.SearchParams{Query: "cat:cs.LG", MaxResults: 10}) {
+//		...
+//	}
+//
+// Faults such as 503 responses, slow responses, empty pages and malformed
+// feeds can be injected into the following requests to exercise retries:
+//
+//	server.Inject(arxivtest.Unavailable("0"), arxivtest.EmptyPage())
+package arxivtest
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+	"github.com/Epistemic-Technology/arxiv/arxiv/index"
+)
+
+// Server is a fake arXiv API server. It is safe for concurrent use.
+type Server struct {
+	URL string // Base URL of the API, for arxiv.WithBaseURL
+
+	server   *httptest.Server
+	index    *index.Index
+	mu       sync.Mutex
+	faults   []Fault
+	requests []arxiv.SearchParams
+}
+
+// NewServer starts a server answering searches from entries. The caller
+// should call Close when finished, to shut it down.
+func NewServer(entries ...arxiv.EntryMetadata) *Server {
+	s := &Server{index: index.New()}
+	s.index.Add(entries...)
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.server.URL + "/api/query"
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Add adds entries to those the server answers from. An entry replaces one
+// with the same base ID unless that one is a later version.
+func (s *Server) Add(entries ...arxiv.EntryMetadata) {
+	s.index.Add(entries...)
+}
+
+// Client returns a client for the server, without rate limiting, configured
+// further by options.
+func (s *Server) Client(options ...arxiv.ClientOption) *arxiv.Client {
+	options = append([]arxiv.ClientOption{arxiv.WithBaseURL(s.URL), arxiv.WithRateLimit(0)}, options...)
+	return arxiv.NewClient(options...)
+}
+
+// Inject queues faults for the following requests, one request each, in
+// order. Requests after the last fault are answered normally.
+func (s *Server) Inject(faults ...Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, faults...)
+}
+
+// Requests returns the parameters of every request received, including
+// those answered with a fault.
+func (s *Server) Requests() []arxiv.SearchParams {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]arxiv.SearchParams(nil), s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	params, err := parseParams(r)
+	s.mu.Lock()
+	s.requests = append(s.requests, params)
+	var fault Fault
+	if len(s.faults) > 0 {
+		fault, s.faults = s.faults[0], s.faults[1:]
+	}
+	s.mu.Unlock()
+
+	if fault.Delay > 0 {
+		timer := time.NewTimer(fault.Delay)
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		}
+	}
+	if fault.Status != 0 {
+		if fault.RetryAfter != "" {
+			w.Header().Set("Retry-After", fault.RetryAfter)
+		}
+		http.Error(w, http.StatusText(fault.Status), fault.Status)
+		return
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	results, err := s.index.SearchFunc()(context.Background(), params)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if fault.EmptyPage {
+		results.Entries = nil
+	}
+	data, err := xml.MarshalIndent(newFeed(results), "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if fault.Malformed {
+		data = data[:len(data)/2]
+	}
+	w.Header().Set("Content-Type", "application/atom+xml; charset=UTF-8")
+	w.Write([]byte(xml.Header))
+	w.Write(data)
+}
+
+// parseParams reads the search parameters of a GET or POST request.
+func parseParams(r *http.Request) (arxiv.SearchParams, error) {
+	if err := r.ParseForm(); err != nil {
+		return arxiv.SearchParams{}, err
+	}
+	params := arxiv.SearchParams{
+		Query:     r.Form.Get("search_query"),
+		SortBy:    arxiv.SortBy(r.Form.Get("sortBy")),
+		SortOrder: arxiv.SortOrder(r.Form.Get("sortOrder")),
+	}
+	if ids := r.Form.Get("id_list"); ids != "" {
+		params.IdList = strings.Split(ids, ",")
+	}
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{{"start", &params.Start}, {"max_results", &params.MaxResults}} {
+		if v := r.Form.Get(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return params, fmt.Errorf("%s must be a non-negative integer: %s", p.name, v)
+			}
+			*p.dst = n
+		}
+	}
+	switch params.SortBy {
+	case "", arxiv.SortByRelevance, arxiv.SortByLastUpdatedDate, arxiv.SortBySubmittedDate:
+	default:
+		return params, fmt.Errorf("unknown sortBy: %s", params.SortBy)
+	}
+	switch params.SortOrder {
+	case "", arxiv.SortOrderAscending, arxiv.SortOrderDescending:
+	default:
+		return params, fmt.Errorf("unknown sortOrder: %s", params.SortOrder)
+	}
+	return params, nil
+}
+
+// writeError answers a bad request the way arXiv does, with status 400 and
+// a feed holding a single entry that describes the error.
+func writeError(w http.ResponseWriter, err error) {
+	f := feed{
+		Title:        "arXiv Query: error",
+		ID:           "http://arxiv.org/api/errors",
+		Updated:      time.Now().UTC().Format(time.RFC3339),
+		TotalResults: 1,
+		ItemsPerPage: 1,
+		Entries: []entry{{
+			ID:      "http://arxiv.org/api/errors#" + strings.ReplaceAll(err.Error(), " ", "_"),
+			Title:   "Error",
+			Summary: err.Error(),
+		}},
+	}
+	data, _ := xml.MarshalIndent(f, "", "  ")
+	w.Header().Set("Content-Type", "application/atom+xml; charset=UTF-8")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write([]byte(xml.Header))
+	w.Write(data)
+}
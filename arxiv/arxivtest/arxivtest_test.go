@@ -0,0 +1,185 @@
+package arxivtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+func titles(entries []arxiv.EntryMetadata) []string {
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.Title)
+	}
+	return out
+}
+
+func TestServerSearch(t *testing.T) {
+	server := NewServer(Generate(12)...)
+	defer server.Close()
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		method arxiv.RequestMethod
+		params arxiv.SearchParams
+		want   []string
+		total  int
+	}{
+		{"query", arxiv.RequestMethodGet, arxiv.SearchParams{Query: "cat:cs.LG", SortBy: arxiv.SortBySubmittedDate},
+			[]string{"Paper 11", "Paper 6", "Paper 1"}, 3},
+		{"post", arxiv.RequestMethodPost, arxiv.SearchParams{Query: "cat:cs.LG", SortBy: arxiv.SortBySubmittedDate, SortOrder: arxiv.SortOrderAscending},
+			[]string{"Paper 1", "Paper 6", "Paper 11"}, 3},
+		{"paging", arxiv.RequestMethodGet, arxiv.SearchParams{Query: `au:"Common Author"`, SortBy: arxiv.SortBySubmittedDate, Start: 2, MaxResults: 3},
+			[]string{"Paper 10", "Paper 9", "Paper 8"}, 12},
+		{"boolean", arxiv.RequestMethodGet, arxiv.SearchParams{Query: "ti:paper AND (ti:3 OR ti:4) ANDNOT cat:math.PR"},
+			[]string{"Paper 4"}, 1},
+		{"id list", arxiv.RequestMethodGet, arxiv.SearchParams{IdList: []string{"2401.00005", "2401.00002v1"}},
+			[]string{"Paper 5", "Paper 2"}, 2},
+		{"id list and query", arxiv.RequestMethodGet, arxiv.SearchParams{Query: "cat:cs.AI", IdList: []string{"2401.00005", "2401.00002v1"}},
+			[]string{"Paper 2"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := server.Client(arxiv.WithRequestMethod(tt.method))
+			results, err := client.Search(ctx, tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := titles(results.Entries); !slices.Equal(got, tt.want) {
+				t.Errorf("entries = %v, want %v", got, tt.want)
+			}
+			if results.TotalResults != tt.total || results.StartIndex != tt.params.Start {
+				t.Errorf("TotalResults = %d, StartIndex = %d", results.TotalResults, results.StartIndex)
+			}
+		})
+	}
+}
+
+func TestServerEntriesRoundTrip(t *testing.T) {
+	want := arxiv.EntryMetadata{
+		ID:               "http://arxiv.org/abs/2402.01234v2",
+		Title:            "A Title & <Markup>",
+		Summary:          "An abstract.",
+		Published:        time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC),
+		Updated:          time.Date(2024, 2, 3, 10, 0, 0, 0, time.UTC),
+		Authors:          []arxiv.Author{{Name: "Ada Lovelace", Affiliation: "Analytical Engines"}},
+		PrimaryCategory:  arxiv.Category{Term: "cs.LG"},
+		Categories:       []arxiv.Category{{Term: "cs.LG"}, {Term: "stat.ML"}},
+		Comment:          "10 pages",
+		JournalReference: "J. Mach. Learn. Res. 25 (2024) 1-10",
+		DOI:              "10.1234/example",
+	}
+	server := NewServer(want)
+	defer server.Close()
+
+	results, err := server.Client().Search(context.Background(), arxiv.SearchParams{IdList: []string{"2402.01234"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results.Entries) != 1 {
+		t.Fatalf("got %d entries", len(results.Entries))
+	}
+	got := results.Entries[0]
+	if got.AbstractUrl != "http://arxiv.org/abs/2402.01234v2" || got.PDFUrl != "http://arxiv.org/pdf/2402.01234v2" {
+		t.Errorf("AbstractUrl = %q, PDFUrl = %q", got.AbstractUrl, got.PDFUrl)
+	}
+	got.Links, got.AbstractUrl, got.PDFUrl = nil, "", ""
+	if got.Title != want.Title || !got.Published.Equal(want.Published) || !got.Updated.Equal(want.Updated) ||
+		!slices.Equal(got.Authors, want.Authors) || got.PrimaryCategory != want.PrimaryCategory ||
+		!slices.Equal(got.Categories, want.Categories) || got.Comment != want.Comment ||
+		got.JournalReference != want.JournalReference || got.DOI != want.DOI || got.Summary != want.Summary {
+		t.Errorf("entry = %+v\nwant %+v", got, want)
+	}
+}
+
+func TestServerIterates(t *testing.T) {
+	server := NewServer(Generate(23)...)
+	defer server.Close()
+	n := 0
+	for range server.Client().SearchIter(context.Background(), arxiv.SearchParams{Query: "all:paper", MaxResults: 10}) {
+		n++
+	}
+	if n != 23 {
+		t.Errorf("SearchIter returned %d entries, want 23", n)
+	}
+	if requests := server.Requests(); len(requests) != 3 || requests[2].Start != 20 {
+		t.Errorf("requests = %+v", requests)
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	server := NewServer(Generate(5)...)
+	defer server.Close()
+	ctx := context.Background()
+	params := arxiv.SearchParams{Query: "all:paper", MaxResults: 2}
+
+	// Retried faults are invisible to the caller.
+	client := server.Client(arxiv.WithRetry(arxiv.RetryConfig{MaxAttempts: 5, InitialInterval: time.Millisecond}))
+	server.Inject(Unavailable("0"), EmptyPage(), Malformed(), Status(http.StatusBadGateway))
+	results, err := client.Search(ctx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results.Entries) != 2 || len(server.Requests()) != 5 {
+		t.Errorf("got %d entries after %d requests", len(results.Entries), len(server.Requests()))
+	}
+
+	// Without retries, each fault surfaces, and an empty page is returned
+	// as is.
+	client = server.Client()
+	server.Inject(EmptyPage(), Malformed())
+	if results, err := client.Search(ctx, params); err != nil || len(results.Entries) != 0 {
+		t.Errorf("empty page = %d entries, %v", len(results.Entries), err)
+	}
+	if _, err := client.Search(ctx, params); !errors.Is(err, arxiv.ErrMalformedResponse) {
+		t.Errorf("malformed feed error = %v", err)
+	}
+
+	// A slow response runs into the client's deadline.
+	server.Inject(Slow(time.Second))
+	ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.Search(ctx, params); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("slow response error = %v", err)
+	}
+}
+
+func TestServerBadRequests(t *testing.T) {
+	server := NewServer(Generate(3)...)
+	defer server.Close()
+	for _, query := range []string{
+		"search_query=xx:paper",
+		"search_query=all:paper&max_results=-1",
+		"search_query=all:paper&sortBy=title",
+		"",
+	} {
+		response, err := http.Get(server.URL + "?" + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results, err := arxiv.ParseResponse(response.Body)
+		response.Body.Close()
+		if response.StatusCode != http.StatusBadRequest || err != nil || len(results.Entries) != 1 ||
+			!strings.HasPrefix(results.Entries[0].ID, "http://arxiv.org/api/errors#") {
+			t.Errorf("%q: status %d, %+v, %v", query, response.StatusCode, results, err)
+		}
+	}
+
+	form := url.Values{"search_query": {"all:paper"}}
+	response, err := http.PostForm(server.URL, form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("POST status = %d", response.StatusCode)
+	}
+}
@@ -0,0 +1,321 @@
+package arxivtest
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Mode tells a Cassette what to do with requests.
+type Mode int
+
+const (
+	// ModeReplay answers requests from recorded responses, failing those
+	// that were never recorded. It needs no network.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests on and records their responses,
+	// replacing earlier recordings of the same requests.
+	ModeRecord
+	// ModePassthrough sends requests on without recording or replaying.
+	ModePassthrough
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeReplay:
+		return "replay"
+	case ModeRecord:
+		return "record"
+	case ModePassthrough:
+		return "passthrough"
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// ParseMode parses the name of a mode, as returned by Mode.String. An empty
+// name is ModeReplay, so that a mode can come from an environment variable
+// that is usually unset:
+//
+//	mode, err := arxivtest.ParseMode(os.Getenv("ARXIV_CASSETTE"))
+func ParseMode(name string) (Mode, error) {
+	switch strings.ToLower(name) {
+	case "", "replay":
+		return ModeReplay, nil
+	case "record":
+		return ModeRecord, nil
+	case "passthrough":
+		return ModePassthrough, nil
+	}
+	return 0, fmt.Errorf("arxivtest: unknown cassette mode %q (want record, replay or passthrough)", name)
+}
+
+// UnmatchedError is returned in replay mode for a request with no recorded
+// response.
+type UnmatchedError struct {
+	Key  string // Normalized form of the request
+	File string // File the response was looked for in
+}
+
+func (e *UnmatchedError) Error() string {
+	return fmt.Sprintf("arxivtest: no recorded response for %s (expected in %s; run in record mode to record it)", e.Key, e.File)
+}
+
+// Cassette is an http.RoundTripper that records responses to files and
+// replays them, so that tests can run against real arXiv responses without
+// the network. Plug it into a client with arxiv.WithHTTPClient:
+//
+//	cassette := arxivtest.NewCassette("test_data/cassettes", mode)
+//	client := arxiv.NewClient(arxiv.WithHTTPClient(cassette.Client()), arxiv.WithRateLimit(0))
+//
+// Each response is stored in its own file in the directory, named after a
+// normalized form of its request: the URL path and parameters in sorted
+// order, ignoring the host and whether the parameters were sent with GET
+// or POST. The file holds the response as sent on the wire, headers and
+// body, after a first line giving the request. A Cassette is safe for
+// concurrent use.
+type Cassette struct {
+	dir       string
+	mode      Mode
+	transport http.RoundTripper
+
+	mu        sync.Mutex
+	unmatched []string
+}
+
+// CassetteOption configures a Cassette.
+type CassetteOption func(*Cassette)
+
+// WithTransport sets the transport that requests are sent on in record and
+// passthrough modes (default http.DefaultTransport).
+func WithTransport(transport http.RoundTripper) CassetteOption {
+	return func(c *Cassette) {
+		c.transport = transport
+	}
+}
+
+// NewCassette returns a cassette keeping its recordings in dir, which is
+// created when the first response is recorded.
+func NewCassette(dir string, mode Mode, options ...CassetteOption) *Cassette {
+	c := &Cassette{dir: dir, mode: mode, transport: http.DefaultTransport}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// Client returns an HTTP client using the cassette as its transport.
+func (c *Cassette) Client() *http.Client {
+	return &http.Client{Transport: c}
+}
+
+// Mode returns the cassette's mode.
+func (c *Cassette) Mode() Mode {
+	return c.mode
+}
+
+// Unmatched returns the keys of the requests that found no recording in
+// replay mode, in order.
+func (c *Cassette) Unmatched() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.unmatched)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	if c.mode == ModePassthrough {
+		return c.transport.RoundTrip(req)
+	}
+	// A RoundTripper must not modify the request, so a body that cannot be
+	// read again is moved to a copy of it.
+	if req.GetBody == nil && req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("arxivtest: reading request body: %w", err)
+		}
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	key, err := RequestKey(req)
+	if err != nil {
+		return nil, err
+	}
+	file := filepath.Join(c.dir, fileName(key))
+
+	if c.mode == ModeReplay {
+		response, err := readRecording(file, req)
+		if errors.Is(err, os.ErrNotExist) {
+			c.mu.Lock()
+			c.unmatched = append(c.unmatched, key)
+			c.mu.Unlock()
+			return nil, &UnmatchedError{Key: key, File: file}
+		}
+		return response, err
+	}
+
+	response, err := c.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.record(file, key, response); err != nil {
+		response.Body.Close()
+		return nil, err
+	}
+	return response, nil
+}
+
+// record writes a response to file, leaving its body readable.
+func (c *Cassette) record(file, key string, response *http.Response) error {
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return err
+	}
+	// The body is recorded whole, however it was sent.
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	response.TransferEncoding = nil
+	response.ContentLength = int64(len(body))
+	dump, err := httputil.DumpResponse(response, false)
+	if err != nil {
+		return err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("arxivtest: recording %s: %w", key, err)
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s\n", key)
+	buf.Write(dump)
+	buf.Write(body)
+	if err := os.WriteFile(file, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("arxivtest: recording %s: %w", key, err)
+	}
+	return nil
+}
+
+// readRecording reads the response recorded in file.
+func readRecording(file string, req *http.Request) (*http.Response, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	_, wire, ok := bytes.Cut(data, []byte("\n"))
+	if !ok {
+		return nil, fmt.Errorf("arxivtest: %s is not a recording", file)
+	}
+	response, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(wire)), req)
+	if err != nil {
+		return nil, fmt.Errorf("arxivtest: reading %s: %w", file, err)
+	}
+	// The recorded body is complete, whatever length the headers gave.
+	body, err := io.ReadAll(response.Body)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("arxivtest: reading %s: %w", file, err)
+	}
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	response.ContentLength = int64(len(body))
+	response.Header.Del("Content-Length")
+	response.TransferEncoding = nil
+	return response, nil
+}
+
+// RequestKey returns the normalized form of a request under which a
+// Cassette records its response: the URL path, then the parameters from
+// the query string and any form body, sorted, as in
+// "/api/query?max_results=10&search_query=all%3Aelectron". The body is read
+// from GetBody when the request has one, leaving the request untouched, and
+// otherwise replaced by a copy, so that it stays readable.
+func RequestKey(req *http.Request) (string, error) {
+	params := url.Values{}
+	for name, values := range req.URL.Query() {
+		params[name] = append(params[name], values...)
+	}
+	if req.Body != nil && req.Body != http.NoBody &&
+		strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		var body []byte
+		if req.GetBody != nil {
+			rc, err := req.GetBody()
+			if err != nil {
+				return "", fmt.Errorf("arxivtest: reading request body: %w", err)
+			}
+			body, err = io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				return "", fmt.Errorf("arxivtest: reading request body: %w", err)
+			}
+		} else {
+			var err error
+			body, err = io.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return "", fmt.Errorf("arxivtest: reading request body: %w", err)
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return "", fmt.Errorf("arxivtest: parsing request body: %w", err)
+		}
+		for name, values := range form {
+			params[name] = append(params[name], values...)
+		}
+	}
+	for name, values := range params {
+		var kept []string
+		for _, v := range values {
+			if v = strings.TrimSpace(v); v != "" {
+				kept = append(kept, v)
+			}
+		}
+		if len(kept) == 0 {
+			delete(params, name)
+			continue
+		}
+		slices.Sort(kept)
+		params[name] = kept
+	}
+	key := req.URL.Path
+	if len(params) > 0 {
+		key += "?" + params.Encode()
+	}
+	return key, nil
+}
+
+// fileName returns the name of the file recording the response to the
+// request with the given key: a readable part of the key, shortened, and a
+// hash of it.
+func fileName(key string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range key {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= 60 {
+			break
+		}
+	}
+	sum := sha256.Sum256([]byte(key))
+	return strings.TrimSuffix(b.String(), "-") + "-" + hex.EncodeToString(sum[:4]) + ".http"
+}
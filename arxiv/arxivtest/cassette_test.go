@@ -0,0 +1,189 @@
+package arxivtest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+func cassetteClient(c *Cassette, baseURL string, options ...arxiv.ClientOption) *arxiv.Client {
+	options = append([]arxiv.ClientOption{arxiv.WithBaseURL(baseURL), arxiv.WithRateLimit(0), arxiv.WithHTTPClient(c.Client())}, options...)
+	return arxiv.NewClient(options...)
+}
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	server := NewServer(Generate(15)...)
+	baseURL := server.URL
+	ctx := context.Background()
+	params := arxiv.SearchParams{Query: "all:paper", MaxResults: 10, SortBy: arxiv.SortBySubmittedDate}
+
+	var recorded []string
+	recorder := NewCassette(dir, ModeRecord)
+	for e := range cassetteClient(recorder, baseURL).SearchIter(ctx, params) {
+		recorded = append(recorded, e.Title)
+	}
+	server.Close()
+	files, _ := os.ReadDir(dir)
+	if len(recorded) != 15 || len(files) != 2 {
+		t.Fatalf("recorded %d entries in %d files", len(recorded), len(files))
+	}
+
+	// Replay needs no server, and matches POST requests to recorded GETs.
+	player := NewCassette(dir, ModeReplay)
+	for _, method := range []arxiv.RequestMethod{arxiv.RequestMethodGet, arxiv.RequestMethodPost} {
+		var replayed []string
+		for e := range cassetteClient(player, "http://elsewhere.example/api/query", arxiv.WithRequestMethod(method)).SearchIter(ctx, params) {
+			replayed = append(replayed, e.Title)
+		}
+		if !slices.Equal(replayed, recorded) {
+			t.Errorf("method %d replayed %v, want %v", method, replayed, recorded)
+		}
+	}
+
+	_, err := cassetteClient(player, baseURL).Search(ctx, arxiv.SearchParams{Query: "all:other"})
+	var unmatched *UnmatchedError
+	if !errors.As(err, &unmatched) || unmatched.Key != "/api/query?search_query=all%3Aother" {
+		t.Fatalf("unrecorded request error = %v", err)
+	}
+	if !strings.Contains(err.Error(), "record mode") || !strings.HasPrefix(unmatched.File, dir) {
+		t.Errorf("error does not say how to record: %v", err)
+	}
+	if got := player.Unmatched(); !slices.Equal(got, []string{unmatched.Key}) {
+		t.Errorf("Unmatched() = %v", got)
+	}
+}
+
+func TestCassetteRecordsFailures(t *testing.T) {
+	dir := t.TempDir()
+	server := NewServer(Generate(3)...)
+	defer server.Close()
+	server.Inject(Unavailable("7"))
+	ctx := context.Background()
+
+	recorder := NewCassette(dir, ModeRecord)
+	response, err := recorder.Client().Get(server.URL + "?search_query=all:paper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+
+	player := NewCassette(dir, ModeReplay)
+	response, err = player.Client().Get(server.URL + "?search_query=all%3Apaper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusServiceUnavailable || response.Header.Get("Retry-After") != "7" {
+		t.Errorf("replayed %d with Retry-After %q", response.StatusCode, response.Header.Get("Retry-After"))
+	}
+
+	// Passthrough records nothing.
+	passthrough := NewCassette(filepath.Join(dir, "passthrough"), ModePassthrough)
+	if _, err := cassetteClient(passthrough, server.URL).Search(ctx, arxiv.SearchParams{Query: "all:paper"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "passthrough")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("passthrough created its directory: %v", err)
+	}
+}
+
+func TestCassetteLeavesRequestUntouched(t *testing.T) {
+	dir := t.TempDir()
+	server := NewServer(Generate(3)...)
+	defer server.Close()
+	form := "search_query=all%3Apaper"
+	post := func(withGetBody bool) *http.Request {
+		req, _ := http.NewRequest("POST", server.URL, strings.NewReader(form))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if !withGetBody {
+			req.GetBody = nil
+		}
+		return req
+	}
+
+	if response, err := NewCassette(dir, ModeRecord).RoundTrip(post(false)); err != nil {
+		t.Fatal(err)
+	} else {
+		response.Body.Close()
+	}
+
+	player := NewCassette(dir, ModeReplay)
+	for _, withGetBody := range []bool{true, false} {
+		req := post(withGetBody)
+		body := req.Body
+		response, err := player.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("GetBody %v: %v", withGetBody, err)
+		}
+		response.Body.Close()
+		if req.Body != body || (req.GetBody == nil) == withGetBody {
+			t.Errorf("GetBody %v: RoundTrip modified the request", withGetBody)
+		}
+		if withGetBody {
+			if data, _ := io.ReadAll(req.Body); string(data) != form {
+				t.Errorf("RoundTrip read the body of a request with GetBody: %q left", data)
+			}
+		}
+	}
+}
+
+func TestRequestKey(t *testing.T) {
+	tests := []struct {
+		method, url, body string
+		want              string
+	}{
+		{"GET", "http://export.arxiv.org/api/query?search_query=all:electron&max_results=10", "", "/api/query?max_results=10&search_query=all%3Aelectron"},
+		{"GET", "http://localhost:1234/api/query?max_results=10&search_query=all%3Aelectron&start=", "", "/api/query?max_results=10&search_query=all%3Aelectron"},
+		{"POST", "http://export.arxiv.org/api/query", "search_query=all%3Aelectron&max_results=10", "/api/query?max_results=10&search_query=all%3Aelectron"},
+		{"GET", "http://arxiv.org/pdf/2401.00001v1", "", "/pdf/2401.00001v1"},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+		if tt.body != "" {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		got, err := RequestKey(req)
+		if err != nil || got != tt.want {
+			t.Errorf("RequestKey(%s %s) = %q, %v, want %q", tt.method, tt.url, got, err, tt.want)
+		}
+		if tt.body != "" {
+			want := url.Values{"search_query": {"all:electron"}, "max_results": {"10"}}
+			if err := req.ParseForm(); err != nil || req.PostForm.Encode() != want.Encode() {
+				t.Errorf("request body not readable after RequestKey: %v", req.PostForm)
+			}
+		}
+	}
+
+	// A request with GetBody, as http.NewRequest makes, is left as it was.
+	req, _ := http.NewRequest("POST", "http://export.arxiv.org/api/query", strings.NewReader("search_query=all%3Aelectron"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	body := req.Body
+	if _, err := RequestKey(req); err != nil || req.Body != body {
+		t.Errorf("RequestKey replaced the body of a request with GetBody: %v", err)
+	}
+
+	if name := fileName("/api/query?max_results=10&search_query=all%3Aelectron"); !strings.HasPrefix(name, "api-query-max-results-10-search-query-all-3Aelectron-") || !strings.HasSuffix(name, ".http") {
+		t.Errorf("fileName = %q", name)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for name, want := range map[string]Mode{"": ModeReplay, "replay": ModeReplay, "RECORD": ModeRecord, "passthrough": ModePassthrough} {
+		if got, err := ParseMode(name); err != nil || got != want {
+			t.Errorf("ParseMode(%q) = %v, %v, want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseMode("rewind"); err == nil {
+		t.Error("ParseMode(\"rewind\") succeeded")
+	}
+}
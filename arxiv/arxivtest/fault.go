@@ -0,0 +1,74 @@
+package arxivtest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// Fault changes how the server answers one request.
+type Fault struct {
+	Status     int           // Respond with this HTTP status instead of a feed (0 = 200 with a feed)
+	RetryAfter string        // Retry-After header sent with Status, in seconds or as an HTTP date
+	Delay      time.Duration // Wait before responding, or until the request is canceled
+	EmptyPage  bool          // Respond with the page's pagination but no entries, as arXiv intermittently does
+	Malformed  bool          // Respond with a feed cut off halfway
+}
+
+// Unavailable returns a fault answering with 503 Service Unavailable and
+// the given Retry-After header, if not empty.
+func Unavailable(retryAfter string) Fault {
+	return Fault{Status: http.StatusServiceUnavailable, RetryAfter: retryAfter}
+}
+
+// Status returns a fault answering with the given HTTP status.
+func Status(code int) Fault {
+	return Fault{Status: code}
+}
+
+// Slow returns a fault answering normally after a delay.
+func Slow(delay time.Duration) Fault {
+	return Fault{Delay: delay}
+}
+
+// EmptyPage returns a fault answering with no entries although the total
+// says there are some.
+func EmptyPage() Fault {
+	return Fault{EmptyPage: true}
+}
+
+// Malformed returns a fault answering with a truncated feed.
+func Malformed() Fault {
+	return Fault{Malformed: true}
+}
+
+// generatedCategories are the categories Generate assigns in turn.
+var generatedCategories = []string{"cs.LG", "cs.AI", "math.PR", "hep-th", "q-bio.NC"}
+
+// Generate returns n entries for use as fixtures, with IDs 2401.00001v1,
+// 2401.00002v1 and so on, titled "Paper 1", "Paper 2" and so on. Entry i
+// is published i hours after the start of 2024, has two authors and a
+// primary category cycling through cs.LG, cs.AI, math.PR, hep-th and
+// q-bio.NC.
+func Generate(n int) []arxiv.EntryMetadata {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := make([]arxiv.EntryMetadata, n)
+	for i := range entries {
+		num := i + 1
+		published := start.Add(time.Duration(num) * time.Hour)
+		cat := arxiv.Category{Term: generatedCategories[i%len(generatedCategories)]}
+		entries[i] = arxiv.EntryMetadata{
+			ID:              fmt.Sprintf("http://arxiv.org/abs/2401.%05dv1", num),
+			Title:           fmt.Sprintf("Paper %d", num),
+			Summary:         fmt.Sprintf("The abstract of paper %d.", num),
+			Published:       published,
+			Updated:         published,
+			Authors:         []arxiv.Author{{Name: fmt.Sprintf("Author %d", num)}, {Name: "Common Author"}},
+			PrimaryCategory: cat,
+			Categories:      []arxiv.Category{cat},
+		}
+	}
+	return entries
+}
@@ -0,0 +1,125 @@
+package arxivtest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// The Atom feed written by the server, in the form the arXiv API uses.
+
+const arxivNS = "http://arxiv.org/schemas/atom"
+
+type feed struct {
+	XMLName      xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+	Links        []link   `xml:"link"`
+	Title        string   `xml:"title"`
+	ID           string   `xml:"id"`
+	Updated      string   `xml:"updated"`
+	TotalResults int      `xml:"http://a9.com/-/spec/opensearch/1.1/ totalResults"`
+	StartIndex   int      `xml:"http://a9.com/-/spec/opensearch/1.1/ startIndex"`
+	ItemsPerPage int      `xml:"http://a9.com/-/spec/opensearch/1.1/ itemsPerPage"`
+	Entries      []entry  `xml:"entry"`
+}
+
+type entry struct {
+	ID              string     `xml:"id"`
+	Updated         string     `xml:"updated,omitempty"`
+	Published       string     `xml:"published,omitempty"`
+	Title           string     `xml:"title"`
+	Summary         string     `xml:"summary"`
+	Authors         []author   `xml:"author"`
+	DOI             string     `xml:"http://arxiv.org/schemas/atom doi,omitempty"`
+	Links           []link     `xml:"link"`
+	Comment         string     `xml:"http://arxiv.org/schemas/atom comment,omitempty"`
+	JournalRef      string     `xml:"http://arxiv.org/schemas/atom journal_ref,omitempty"`
+	PrimaryCategory *category  `xml:"http://arxiv.org/schemas/atom primary_category"`
+	Categories      []category `xml:"category"`
+}
+
+type author struct {
+	Name        string `xml:"name"`
+	Affiliation string `xml:"http://arxiv.org/schemas/atom affiliation,omitempty"`
+}
+
+type link struct {
+	Href  string `xml:"href,attr"`
+	Rel   string `xml:"rel,attr,omitempty"`
+	Type  string `xml:"type,attr,omitempty"`
+	Title string `xml:"title,attr,omitempty"`
+}
+
+type category struct {
+	Term   string `xml:"term,attr"`
+	Scheme string `xml:"scheme,attr"`
+}
+
+func newFeed(results arxiv.SearchResults) feed {
+	p := results.Params
+	query := fmt.Sprintf("search_query=%s&id_list=%s&start=%d&max_results=%d",
+		p.Query, strings.Join(p.IdList, ","), p.Start, p.MaxResults)
+	f := feed{
+		Links:        []link{{Href: "http://arxiv.org/api/query?" + query, Rel: "self", Type: "application/atom+xml"}},
+		Title:        "arXiv Query: " + query,
+		ID:           "http://arxiv.org/api/arxivtest",
+		Updated:      time.Now().UTC().Format(time.RFC3339),
+		TotalResults: results.TotalResults,
+		StartIndex:   results.StartIndex,
+		ItemsPerPage: results.ItemsPerPage,
+	}
+	for _, e := range results.Entries {
+		f.Entries = append(f.Entries, newEntry(e))
+	}
+	return f
+}
+
+func newEntry(e arxiv.EntryMetadata) entry {
+	out := entry{
+		ID:         e.ID,
+		Updated:    formatTime(e.Updated),
+		Published:  formatTime(e.Published),
+		Title:      e.Title,
+		Summary:    e.Summary,
+		DOI:        e.DOI,
+		Comment:    e.Comment,
+		JournalRef: e.JournalReference,
+	}
+	for _, a := range e.Authors {
+		out.Authors = append(out.Authors, author{Name: a.Name, Affiliation: a.Affiliation})
+	}
+	for _, l := range e.Links {
+		out.Links = append(out.Links, link(l))
+	}
+	if len(out.Links) == 0 {
+		// Links as arXiv gives them, from which the client fills in
+		// AbstractUrl and PDFUrl.
+		abs, pdf := e.AbstractUrl, e.PDFUrl
+		if abs == "" {
+			abs = "http://arxiv.org/abs/" + e.ArxivID()
+		}
+		if pdf == "" {
+			pdf = "http://arxiv.org/pdf/" + e.ArxivID()
+		}
+		out.Links = []link{
+			{Href: abs, Rel: "alternate", Type: "text/html"},
+			{Href: pdf, Rel: "related", Type: "application/pdf", Title: "pdf"},
+		}
+	}
+	if e.PrimaryCategory.Term != "" {
+		out.PrimaryCategory = &category{Term: e.PrimaryCategory.Term, Scheme: arxivNS}
+	}
+	for _, c := range e.Categories {
+		out.Categories = append(out.Categories, category{Term: c.Term, Scheme: arxivNS})
+	}
+	return out
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,327 @@
+package arxiv
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// authorPageSize is the page size of the searches made by AuthorPapers.
+const authorPageSize = 100
+
+// profileSimilarity is the cosine similarity of category profiles above
+// which GroupAuthorPapers joins identities with no co-author or affiliation
+// in common.
+const profileSimilarity = 0.6
+
+// AuthorIdentity is a person who may have written papers under a name, as
+// found by AuthorPapers. The names, affiliations, categories and co-authors
+// are hints for telling identities apart.
+type AuthorIdentity struct {
+	Name         PersonName      `json:"name"`                   // Fullest form of the name on the papers.
+	Names        []string        `json:"names"`                  // Forms of the name as written on the papers, most common first.
+	Affiliations []string        `json:"affiliations,omitempty"` // Affiliations given on the papers, most common first.
+	Categories   []string        `json:"categories,omitempty"`   // Categories of the papers, most common first.
+	CoAuthors    []string        `json:"coAuthors,omitempty"`    // Co-authors, most frequent first.
+	Papers       []EntryMetadata `json:"papers"`                 // Papers, newest first.
+}
+
+// AuthorPapers finds the papers by an author, searching with every au:
+// variant of name from SearchQuery.AuthorName and paging through all
+// results as SearchIter does, and groups them into identities with
+// GroupAuthorPapers. A name such as "G. Hinton" may stand for several
+// people; identities are ordered by number of papers, most first.
+//
+// Unlike SearchIter, AuthorPapers reports an error that stops the search,
+// along with no identities.
+func (c *Client) AuthorPapers(ctx context.Context, name string) ([]AuthorIdentity, error) {
+	harvest := c.Harvest(SearchParams{
+		Query:      NewSearchQuery().AuthorName(name).String(),
+		MaxResults: authorPageSize,
+		SortBy:     SortBySubmittedDate,
+		SortOrder:  SortOrderDescending,
+	}, HarvestOptions{})
+	var entries []EntryMetadata
+	for entry := range harvest.Entries(ctx) {
+		entries = append(entries, entry)
+	}
+	if err := harvest.Err(); err != nil {
+		return nil, err
+	}
+	return GroupAuthorPapers(name, entries), nil
+}
+
+// GroupAuthorPapers groups the entries with an author matching name, as
+// told by PersonName.Matches, into identities. Entries by the same person
+// are recognized by shared co-authors or affiliations and then by similar
+// category profiles; names that cannot be the same person, such as
+// "Geoffrey Hinton" and "Gregory Hinton", are never grouped. Entries with
+// no matching author are dropped, and versions of an entry are counted
+// once.
+func GroupAuthorPapers(name string, entries []EntryMetadata) []AuthorIdentity {
+	query := ParseName(name)
+	entries = Dedup(entries)
+
+	var mentions []*authorCluster
+	for _, entry := range entries {
+		if m := newMention(query, entry); m != nil {
+			mentions = append(mentions, m)
+		}
+	}
+
+	// Join mentions sharing a co-author or an affiliation, starting from the
+	// fullest names and the oldest papers, so that a name that is only
+	// initials joins the earliest identity it shares something with.
+	slices.SortStableFunc(mentions, func(a, b *authorCluster) int {
+		if c := compareFullness(b.names[0], a.names[0]); c != 0 {
+			return c
+		}
+		if c := a.papers[0].Published.Compare(b.papers[0].Published); c != 0 {
+			return c
+		}
+		return strings.Compare(a.papers[0].ID, b.papers[0].ID)
+	})
+	parent := make([]int, len(mentions))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(i, j int) {
+		i, j = find(i), find(j)
+		if i == j || !mentions[i].compatible(mentions[j]) {
+			return
+		}
+		if j < i {
+			i, j = j, i
+		}
+		mentions[i].merge(mentions[j])
+		parent[j] = i
+	}
+	seen := map[string][]int{}
+	for i, m := range mentions {
+		for _, signal := range m.signals() {
+			for _, j := range seen[signal] {
+				union(j, i)
+			}
+			seen[signal] = append(seen[signal], i)
+		}
+	}
+	var clusters []*authorCluster
+	for i, m := range mentions {
+		if find(i) == i {
+			clusters = append(clusters, m)
+		}
+	}
+
+	// Join the smaller identities left to a larger one with a similar
+	// category profile.
+	slices.SortStableFunc(clusters, func(a, b *authorCluster) int {
+		return cmp.Compare(len(b.papers), len(a.papers))
+	})
+	for i := len(clusters) - 1; i > 0; i-- {
+		best, bestSimilarity := -1, profileSimilarity
+		for j := range i {
+			if s := clusters[i].categories.similarity(&clusters[j].categories); s >= bestSimilarity && clusters[i].compatible(clusters[j]) {
+				best, bestSimilarity = j, s
+			}
+		}
+		if best >= 0 {
+			clusters[best].merge(clusters[i])
+			clusters = slices.Delete(clusters, i, i+1)
+		}
+	}
+
+	identities := make([]AuthorIdentity, len(clusters))
+	for i, c := range clusters {
+		identities[i] = c.identity()
+	}
+	slices.SortStableFunc(identities, func(a, b AuthorIdentity) int {
+		if c := cmp.Compare(len(b.Papers), len(a.Papers)); c != 0 {
+			return c
+		}
+		return b.Papers[0].Published.Compare(a.Papers[0].Published)
+	})
+	return identities
+}
+
+// authorCluster gathers the papers of one possible identity and the hints
+// that tell it apart.
+type authorCluster struct {
+	papers       []EntryMetadata
+	names        []PersonName
+	written      counter
+	affiliations counter
+	categories   counter
+	coAuthors    counter
+}
+
+// newMention returns a cluster of a single entry, or nil if no author of
+// the entry matches query.
+func newMention(query PersonName, entry EntryMetadata) *authorCluster {
+	author := slices.IndexFunc(entry.Authors, func(a Author) bool {
+		return query.Matches(a.ParsedName())
+	})
+	if author < 0 {
+		return nil
+	}
+	a := entry.Authors[author]
+	m := &authorCluster{papers: []EntryMetadata{entry}, names: []PersonName{a.ParsedName()}}
+	m.written.add(normalizeSpace(a.Name), normalizeSpace(a.Name))
+	if a.Affiliation != "" {
+		m.affiliations.add(NormalizeName(a.Affiliation), normalizeSpace(a.Affiliation))
+	}
+	for _, c := range append([]Category{entry.PrimaryCategory}, entry.Categories...) {
+		if c.Term != "" && !slices.Contains(m.categories.keys, c.Term) {
+			m.categories.add(c.Term, c.Term)
+		}
+	}
+	for i, co := range entry.Authors {
+		if key := co.ParsedName().Key(); i != author && key != "" {
+			m.coAuthors.add(key, normalizeSpace(co.Name))
+		}
+	}
+	return m
+}
+
+// signals returns the co-authors and affiliations of a cluster, each of
+// which joins it to any other cluster sharing it.
+func (c *authorCluster) signals() []string {
+	var signals []string
+	for _, key := range c.coAuthors.keys {
+		signals = append(signals, "co:"+key)
+	}
+	for _, key := range c.affiliations.keys {
+		signals = append(signals, "af:"+key)
+	}
+	return signals
+}
+
+// compatible reports whether every name in c may belong to the same person
+// as every name in other.
+func (c *authorCluster) compatible(other *authorCluster) bool {
+	for _, a := range c.names {
+		for _, b := range other.names {
+			if !a.Matches(b) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// merge adds the papers and hints of other to c.
+func (c *authorCluster) merge(other *authorCluster) {
+	c.papers = append(c.papers, other.papers...)
+	for _, n := range other.names {
+		if !slices.Contains(c.names, n) {
+			c.names = append(c.names, n)
+		}
+	}
+	c.written.merge(&other.written)
+	c.affiliations.merge(&other.affiliations)
+	c.categories.merge(&other.categories)
+	c.coAuthors.merge(&other.coAuthors)
+}
+
+func (c *authorCluster) identity() AuthorIdentity {
+	papers := slices.Clone(c.papers)
+	slices.SortStableFunc(papers, func(a, b EntryMetadata) int {
+		return b.Published.Compare(a.Published)
+	})
+	name := slices.MaxFunc(c.names, compareFullness)
+	return AuthorIdentity{
+		Name:         name,
+		Names:        c.written.ranked(),
+		Affiliations: c.affiliations.ranked(),
+		Categories:   c.categories.ranked(),
+		CoAuthors:    c.coAuthors.ranked(),
+		Papers:       papers,
+	}
+}
+
+// compareFullness orders names by how much they tell: the number of given
+// names, then their length, so that "Geoffrey E. Hinton" is fuller than
+// "Geoffrey Hinton", which is fuller than "G. Hinton".
+func compareFullness(a, b PersonName) int {
+	if c := cmp.Compare(len(givenNames(a.Given)), len(givenNames(b.Given))); c != 0 {
+		return c
+	}
+	return cmp.Compare(len(a.String()), len(b.String()))
+}
+
+// counter counts occurrences of keys, remembering the first form of each
+// seen and the order keys were first seen in.
+type counter struct {
+	keys    []string
+	counts  map[string]int
+	display map[string]string
+}
+
+func (c *counter) add(key, display string) {
+	c.addN(key, display, 1)
+}
+
+func (c *counter) addN(key, display string, n int) {
+	if c.counts == nil {
+		c.counts = map[string]int{}
+		c.display = map[string]string{}
+	}
+	if _, ok := c.counts[key]; !ok {
+		c.keys = append(c.keys, key)
+		c.display[key] = display
+	}
+	c.counts[key] += n
+}
+
+func (c *counter) merge(other *counter) {
+	for _, key := range other.keys {
+		c.addN(key, other.display[key], other.counts[key])
+	}
+}
+
+// ranked returns the forms of the keys, most common first.
+func (c *counter) ranked() []string {
+	keys := slices.Clone(c.keys)
+	slices.SortStableFunc(keys, func(a, b string) int {
+		return cmp.Compare(c.counts[b], c.counts[a])
+	})
+	var out []string
+	for _, key := range keys {
+		out = append(out, c.display[key])
+	}
+	return out
+}
+
+// similarity returns the cosine similarity of two counters.
+func (c *counter) similarity(other *counter) float64 {
+	var dot, a, b float64
+	for key, n := range c.counts {
+		dot += float64(n * other.counts[key])
+		a += float64(n * n)
+	}
+	for _, n := range other.counts {
+		b += float64(n * n)
+	}
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(a*b)
+}
+
+// String returns the fullest form of the identity's name and its number of
+// papers, as in "Geoffrey E. Hinton (12 papers)".
+func (id AuthorIdentity) String() string {
+	if len(id.Papers) == 1 {
+		return id.Name.String() + " (1 paper)"
+	}
+	return fmt.Sprintf("%s (%d papers)", id.Name, len(id.Papers))
+}
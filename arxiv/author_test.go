@@ -0,0 +1,157 @@
+package arxiv_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+	"github.com/Epistemic-Technology/arxiv/arxiv/arxivtest"
+)
+
+func authorEntry(id string, day int, categories []string, authors ...arxiv.Author) arxiv.EntryMetadata {
+	e := arxiv.EntryMetadata{
+		ID:        "http://arxiv.org/abs/" + id,
+		Title:     "Paper " + id,
+		Published: time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC),
+		Authors:   authors,
+	}
+	e.Updated = e.Published
+	e.PrimaryCategory = arxiv.Category{Term: categories[0]}
+	for _, c := range categories {
+		e.Categories = append(e.Categories, arxiv.Category{Term: c})
+	}
+	return e
+}
+
+func hintonPapers() []arxiv.EntryMetadata {
+	toronto := "University of Toronto"
+	v2 := authorEntry("2402.00001v2", 1, []string{"cs.LG"},
+		arxiv.Author{Name: "Geoffrey E. Hinton", Affiliation: toronto}, arxiv.Author{Name: "Yann LeCun"})
+	v2.Updated = v2.Updated.AddDate(0, 1, 0)
+	return []arxiv.EntryMetadata{
+		authorEntry("2402.00001v1", 1, []string{"cs.LG"},
+			arxiv.Author{Name: "Geoffrey E. Hinton", Affiliation: toronto}, arxiv.Author{Name: "Yann LeCun"}),
+		authorEntry("2402.00002v1", 2, []string{"cs.LG"},
+			arxiv.Author{Name: "Yann Lecun"}, arxiv.Author{Name: "G. Hinton"}),
+		authorEntry("2402.00003v1", 3, []string{"cs.LG", "cs.NE"},
+			arxiv.Author{Name: "Geoffrey Hinton", Affiliation: toronto}, arxiv.Author{Name: "Ilya Sutskever"}),
+		// Shares no co-author and no category with the papers above.
+		authorEntry("2402.00004v1", 4, []string{"physics.chem-ph"},
+			arxiv.Author{Name: "G. Hinton"}, arxiv.Author{Name: "Marie Curie"}),
+		// Shares a co-author, but cannot be Geoffrey.
+		authorEntry("2402.00005v1", 5, []string{"math.PR"},
+			arxiv.Author{Name: "Gregory Hinton"}, arxiv.Author{Name: "Yann LeCun"}),
+		authorEntry("2402.00006v1", 6, []string{"cs.LG"}, arxiv.Author{Name: "Alan Turing"}),
+		v2,
+		// Joined by its category profile alone.
+		authorEntry("2402.00007v1", 7, []string{"cs.LG"}, arxiv.Author{Name: "Hinton, G."}),
+	}
+}
+
+func paperIDs(papers []arxiv.EntryMetadata) []string {
+	var ids []string
+	for _, p := range papers {
+		ids = append(ids, p.ArxivID())
+	}
+	return ids
+}
+
+func checkHintonIdentities(t *testing.T, identities []arxiv.AuthorIdentity) {
+	t.Helper()
+	want := []struct {
+		name   string
+		papers []string
+	}{
+		{"Geoffrey E. Hinton", []string{"2402.00007v1", "2402.00003v1", "2402.00002v1", "2402.00001v2"}},
+		{"Gregory Hinton", []string{"2402.00005v1"}},
+		{"G. Hinton", []string{"2402.00004v1"}},
+	}
+	if len(identities) != len(want) {
+		t.Fatalf("got %d identities %v, want %d", len(identities), identities, len(want))
+	}
+	for i, w := range want {
+		id := identities[i]
+		if got := id.Name.String(); got != w.name {
+			t.Errorf("identity %d name = %q, want %q", i, got, w.name)
+		}
+		if got := paperIDs(id.Papers); !slices.Equal(got, w.papers) {
+			t.Errorf("identity %d papers = %v, want %v", i, got, w.papers)
+		}
+	}
+
+	geoffrey := identities[0]
+	if want := []string{"Geoffrey E. Hinton", "Geoffrey Hinton", "G. Hinton", "Hinton, G."}; !slices.Equal(geoffrey.Names, want) {
+		t.Errorf("Names = %q, want %q", geoffrey.Names, want)
+	}
+	if want := []string{"University of Toronto"}; !slices.Equal(geoffrey.Affiliations, want) {
+		t.Errorf("Affiliations = %q, want %q", geoffrey.Affiliations, want)
+	}
+	if want := []string{"cs.LG", "cs.NE"}; !slices.Equal(geoffrey.Categories, want) {
+		t.Errorf("Categories = %q, want %q", geoffrey.Categories, want)
+	}
+	if want := []string{"Yann LeCun", "Ilya Sutskever"}; !slices.Equal(geoffrey.CoAuthors, want) {
+		t.Errorf("CoAuthors = %q, want %q", geoffrey.CoAuthors, want)
+	}
+	if got, want := geoffrey.String(), "Geoffrey E. Hinton (4 papers)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestGroupAuthorPapers(t *testing.T) {
+	checkHintonIdentities(t, arxiv.GroupAuthorPapers("G. Hinton", hintonPapers()))
+	// The identities do not depend on the order of the entries.
+	reversed := hintonPapers()
+	slices.Reverse(reversed)
+	checkHintonIdentities(t, arxiv.GroupAuthorPapers("G. Hinton", reversed))
+
+	// A fuller name leaves out the papers of other people.
+	identities := arxiv.GroupAuthorPapers("Geoffrey Hinton", hintonPapers())
+	var names []string
+	for _, id := range identities {
+		names = append(names, id.String())
+	}
+	if want := []string{"Geoffrey E. Hinton (4 papers)", "G. Hinton (1 paper)"}; !slices.Equal(names, want) {
+		t.Errorf("identities = %q, want %q", names, want)
+	}
+
+	if got := arxiv.GroupAuthorPapers("Ada Lovelace", hintonPapers()); len(got) != 0 {
+		t.Errorf("identities = %v, want none", got)
+	}
+}
+
+func TestAuthorPapers(t *testing.T) {
+	server := arxivtest.NewServer(hintonPapers()...)
+	server.Add(arxivtest.Generate(150)...)
+	defer server.Close()
+	client := server.Client()
+
+	identities, err := client.AuthorPapers(context.Background(), "G. Hinton")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkHintonIdentities(t, identities)
+	requests := server.Requests()
+	if len(requests) != 1 || requests[0].Query != "au:Hinton_G" {
+		t.Errorf("requests = %+v, want one for au:Hinton_G", requests)
+	}
+
+	// Every page of a prolific author is fetched.
+	identities, err = client.AuthorPapers(context.Background(), "Common Author")
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, id := range identities {
+		total += len(id.Papers)
+	}
+	if total != 150 {
+		t.Errorf("found %d papers, want 150", total)
+	}
+
+	server.Inject(arxivtest.Status(500))
+	if _, err := client.AuthorPapers(context.Background(), "G. Hinton"); err == nil {
+		t.Error("AuthorPapers succeeded despite a server error")
+	}
+}
@@ -0,0 +1,115 @@
+package arxiv
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// bibtexEscaper escapes characters that have a special meaning in BibTeX
+// field values.
+var bibtexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+// BibTeXKey returns a citation key for the entry made of the first author's
+// surname, the year of publication and the first word of the title, such as
+// "hinton2006fast".
+func BibTeXKey(entry EntryMetadata) string {
+	var key strings.Builder
+	if len(entry.Authors) > 0 {
+		fields := strings.Fields(entry.Authors[0].Name)
+		if len(fields) > 0 {
+			key.WriteString(keyPart(fields[len(fields)-1]))
+		}
+	}
+	if !entry.Published.IsZero() {
+		fmt.Fprintf(&key, "%d", entry.Published.Year())
+	}
+	for _, word := range strings.Fields(entry.Title) {
+		if part := keyPart(word); len(part) > 3 {
+			key.WriteString(part)
+			break
+		}
+	}
+	if key.Len() == 0 {
+		return "arxiv" + keyPart(entry.BaseID())
+	}
+	return key.String()
+}
+
+// keyPart lowercases s and drops everything but ASCII letters and digits.
+func keyPart(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// BibTeX formats the entry as a BibTeX @misc record following the
+// conventions arXiv itself uses for eprints.
+func BibTeX(entry EntryMetadata) string {
+	type field struct{ name, value string }
+	fields := []field{
+		{"title", bibtexEscaper.Replace(normalizeSpace(entry.Title))},
+		{"author", bibtexEscaper.Replace(strings.Join(entry.AuthorNames(), " and "))},
+	}
+	if !entry.Published.IsZero() {
+		fields = append(fields, field{"year", fmt.Sprint(entry.Published.Year())})
+	}
+	fields = append(fields,
+		field{"eprint", entry.BaseID()},
+		field{"archivePrefix", "arXiv"},
+	)
+	if entry.PrimaryCategory.Term != "" {
+		fields = append(fields, field{"primaryClass", entry.PrimaryCategory.Term})
+	}
+	if entry.DOI != "" {
+		fields = append(fields, field{"doi", entry.DOI})
+	}
+	if entry.JournalReference != "" {
+		fields = append(fields, field{"note", bibtexEscaper.Replace(normalizeSpace(entry.JournalReference))})
+	}
+	if entry.AbstractUrl != "" {
+		fields = append(fields, field{"url", entry.AbstractUrl})
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "@misc{%s,\n", BibTeXKey(entry))
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "  %-13s = {%s},\n", f.name, f.value)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// WriteBibTeX writes the entries to w as BibTeX records separated by blank lines.
+func WriteBibTeX(w io.Writer, entries []EntryMetadata) error {
+	for i, entry := range entries {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, BibTeX(entry)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
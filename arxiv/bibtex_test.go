@@ -0,0 +1,96 @@
+package arxiv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBibTeX(t *testing.T) {
+	entry := EntryMetadata{
+		ID:        "http://arxiv.org/abs/2401.01234v2",
+		Title:     "Fast  Learning\n  of 100% of {Things} & Stuff",
+		Published: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC),
+		Authors: []Author{
+			{Name: "Geoffrey E. Hinton"},
+			{Name: "Yann LeCun"},
+		},
+		PrimaryCategory:  Category{Term: "cs.LG"},
+		DOI:              "10.1000/xyz",
+		JournalReference: "Phys. Rev. D 98, 030001 (2018)",
+		AbstractUrl:      "http://arxiv.org/abs/2401.01234v2",
+	}
+
+	want := `@misc{hinton2024fast,
+  title         = {Fast Learning of 100\% of \{Things\} \& Stuff},
+  author        = {Geoffrey E. Hinton and Yann LeCun},
+  year          = {2024},
+  eprint        = {2401.01234},
+  archivePrefix = {arXiv},
+  primaryClass  = {cs.LG},
+  doi           = {10.1000/xyz},
+  note          = {Phys. Rev. D 98, 030001 (2018)},
+  url           = {http://arxiv.org/abs/2401.01234v2},
+}
+`
+	if got := BibTeX(entry); got != want {
+		t.Errorf("BibTeX() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestBibTeXKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry EntryMetadata
+		want  string
+	}{
+		{
+			name: "author year and title word",
+			entry: EntryMetadata{
+				Title:     "A Neural Approach",
+				Authors:   []Author{{Name: "Ada Lovelace"}},
+				Published: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			want: "lovelace2020neural",
+		},
+		{
+			name: "non-ASCII surname",
+			entry: EntryMetadata{
+				Title:     "Über Dinge",
+				Authors:   []Author{{Name: "Kurt Gödel"}},
+				Published: time.Date(1931, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			want: "gdel1931dinge",
+		},
+		{
+			name:  "no metadata falls back to ID",
+			entry: EntryMetadata{ID: "http://arxiv.org/abs/cond-mat/0102536v1"},
+			want:  "arxivcondmat0102536",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BibTeXKey(tt.entry); got != tt.want {
+				t.Errorf("BibTeXKey() = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteBibTeX(t *testing.T) {
+	entries := []EntryMetadata{
+		{ID: "http://arxiv.org/abs/2401.00001v1", Title: "First"},
+		{ID: "http://arxiv.org/abs/2401.00002v1", Title: "Second"},
+	}
+	var buf bytes.Buffer
+	if err := WriteBibTeX(&buf, entries); err != nil {
+		t.Fatalf("WriteBibTeX() = %v", err)
+	}
+	if got := strings.Count(buf.String(), "@misc{"); got != 2 {
+		t.Errorf("WriteBibTeX() wrote %d records; want 2", got)
+	}
+	if !strings.Contains(buf.String(), "}\n\n@misc{") {
+		t.Error("WriteBibTeX() records should be separated by a blank line")
+	}
+}
@@ -0,0 +1,268 @@
+package arxiv
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Confidence tells how reliably a value was extracted from a free-text field.
+type Confidence int
+
+const (
+	ConfidenceNone Confidence = iota // Nothing was found; the value is the zero value
+	ConfidenceLow                    // Guessed from a weak cue, such as a year mentioned in passing
+	ConfidenceHigh                   // Matched an explicit pattern, such as "12 pages"
+)
+
+func (c Confidence) String() string {
+	switch c {
+	case ConfidenceLow:
+		return "low"
+	case ConfidenceHigh:
+		return "high"
+	}
+	return "none"
+}
+
+// PublicationStatus is the publication state of a paper as its comment
+// describes it.
+type PublicationStatus string
+
+const (
+	StatusUnknown     PublicationStatus = ""
+	StatusSubmitted   PublicationStatus = "submitted"
+	StatusUnderReview PublicationStatus = "under review"
+	StatusAccepted    PublicationStatus = "accepted"
+	StatusPublished   PublicationStatus = "published"
+	StatusWithdrawn   PublicationStatus = "withdrawn"
+)
+
+// CommentInfo holds the facts extracted from an entry's comment, such as
+// "12 pages, 5 figures, accepted at NeurIPS 2023".
+type CommentInfo struct {
+	Pages      int               // Number of pages
+	Figures    int               // Number of figures
+	Tables     int               // Number of tables
+	Venue      string            // Conference or journal the paper was submitted to or appeared in, without the year
+	Status     PublicationStatus // Publication status
+	Year       int               // Year of the venue, or a year the comment mentions
+	Confidence CommentConfidence // How reliably each field was extracted
+}
+
+// CommentConfidence tells how reliably each field of a CommentInfo was
+// extracted. A field with ConfidenceNone was not found.
+type CommentConfidence struct {
+	Pages, Figures, Tables, Venue, Status, Year Confidence
+}
+
+// CommentInfo extracts page, figure and table counts, the venue, the
+// publication status and the year from the entry's comment.
+func (e EntryMetadata) CommentInfo() CommentInfo {
+	return ParseComment(e.Comment)
+}
+
+var (
+	numberWord  = `\d+|one|two|three|four|five|six|seven|eight|nine|ten|eleven|twelve|thirteen|fourteen|fifteen|sixteen|seventeen|eighteen|nineteen|twenty`
+	figureWords = `(?:(?:colou?r(?:ed)?|b&w|black[- ]and[- ]white|eps|postscript|ps|png|jpe?g|pdf|embedded|additional|main|supplementary|new)\s+){0,2}`
+
+	pagesPattern   = regexp.MustCompile(`(?i)\b(` + numberWord + `)\s*(?:\+\s*(\d+)\s*)?(?:pages?\b|pp\b\.?|pgs?\b\.?)`)
+	pageAdjPattern = regexp.MustCompile(`(?i)\b(\d+)[- ]page\b`)
+	figuresPattern = regexp.MustCompile(`(?i)\b(` + numberWord + `|no)\s+` + figureWords + `(?:figures?\b|figs?\b\.?)`)
+	tablesPattern  = regexp.MustCompile(`(?i)\b(` + numberWord + `|no)\s+` + figureWords + `(?:tables?\b|tabs?\b\.?)`)
+	yearPattern    = regexp.MustCompile(`\b(19[5-9]\d|20\d{2})\b`)
+	shortYear      = regexp.MustCompile(`['’](\d{2})\b`)
+	venuePattern   = regexp.MustCompile(`\b(NeurIPS|NIPS|ICML|ICLR|CVPR|ICCV|ECCV|ACL|EMNLP|NAACL|EACL|COLING|AAAI|IJCAI|KDD|WWW|SIGIR|CHI|UAI|AISTATS|COLT|STOC|FOCS|SODA|ICALP|ICRA|IROS|CoRL|MICCAI|INTERSPEECH|Interspeech|ICASSP|WSDM|CIKM|RecSys|SIGMOD|VLDB|ICDE|OSDI|SOSP|NSDI|PLDI|POPL|ICSE|FSE|CCS|NDSS|LICS|CAV|TACAS)(?:\s*['’]?\s*(\d{4}|\d{2})\b)?`)
+)
+
+// statusPhrases are the phrases that state a publication status, explicit
+// phrases first and then by how advanced the status is. The phrases marked
+// with venue end in a preposition naming the venue, as in "accepted at".
+var statusPhrases = []struct {
+	status     PublicationStatus
+	confidence Confidence
+	venue      bool
+	pattern    *regexp.Regexp
+}{
+	{StatusWithdrawn, ConfidenceHigh, false, regexp.MustCompile(`(?i)\bwithdrawn\b`)},
+	{StatusPublished, ConfidenceHigh, true, regexp.MustCompile(`(?i)\b(?:published|appeared|appears)\s+(?:online\s+)?(?:in|at|by)\b`)},
+	{StatusAccepted, ConfidenceHigh, true, regexp.MustCompile(`(?i)\baccepted\s+(?:for\s+publication\s+|as\s+an?\s+(?:(?:full|short|long|oral|poster|spotlight|workshop|conference|regular|main)\s+)*(?:paper|poster|talk|presentation|oral|contribution)\s+)?(?:in|at|to|by|for)\b`)},
+	{StatusAccepted, ConfidenceHigh, true, regexp.MustCompile(`(?i)\b(?:to\s+(?:appear|be\s+published)\s+(?:in|at)|forthcoming\s+in|in\s+press\s+(?:in|at))\b`)},
+	{StatusAccepted, ConfidenceHigh, false, regexp.MustCompile(`(?i)\bin\s+press\b`)},
+	{StatusUnderReview, ConfidenceHigh, true, regexp.MustCompile(`(?i)\bunder\s+(?:review|submission|revision)\s+(?:at|in|for|by|to)\b`)},
+	{StatusUnderReview, ConfidenceHigh, false, regexp.MustCompile(`(?i)\bunder\s+(?:review|submission|revision)\b`)},
+	{StatusSubmitted, ConfidenceHigh, true, regexp.MustCompile(`(?i)\bsubmitted\s+(?:for\s+publication\s+)?(?:to|in|at)\b`)},
+	{StatusPublished, ConfidenceLow, true, regexp.MustCompile(`(?i)\b(?:presented\s+at|talk\s+(?:given\s+)?at|poster\s+(?:presented\s+)?at|proceedings\s+of)\b`)},
+	{StatusAccepted, ConfidenceLow, false, regexp.MustCompile(`(?i)\b(?:accepted|camera[- ]ready)\b`)},
+	{StatusSubmitted, ConfidenceLow, false, regexp.MustCompile(`(?i)\bsubmitted\b`)},
+}
+
+// ParseComment extracts page, figure and table counts, the venue, the
+// publication status and the year from a free-text comment. Extraction is
+// best effort: Confidence tells which fields were found and how reliably.
+func ParseComment(comment string) CommentInfo {
+	var info CommentInfo
+	comment = normalizeSpace(comment)
+	if comment == "" {
+		return info
+	}
+
+	if m := pagesPattern.FindStringSubmatch(comment); m != nil {
+		info.Pages = parseCount(m[1])
+		if m[2] != "" {
+			extra, _ := strconv.Atoi(m[2])
+			info.Pages += extra
+		}
+		info.Confidence.Pages = ConfidenceHigh
+	} else if m := pageAdjPattern.FindStringSubmatch(comment); m != nil {
+		info.Pages, _ = strconv.Atoi(m[1])
+		info.Confidence.Pages = ConfidenceHigh
+	}
+	if m := figuresPattern.FindStringSubmatch(comment); m != nil {
+		info.Figures = parseCount(m[1])
+		info.Confidence.Figures = ConfidenceHigh
+	}
+	if m := tablesPattern.FindStringSubmatch(comment); m != nil {
+		info.Tables = parseCount(m[1])
+		info.Confidence.Tables = ConfidenceHigh
+	}
+
+	for _, phrase := range statusPhrases {
+		loc := phrase.pattern.FindStringIndex(comment)
+		if loc == nil {
+			continue
+		}
+		info.Status = phrase.status
+		info.Confidence.Status = phrase.confidence
+		if !phrase.venue {
+			break
+		}
+		if venue, year := venueAfter(comment[loc[1]:]); venue != "" {
+			info.Venue = venue
+			info.Confidence.Venue = ConfidenceHigh
+			if year != 0 {
+				info.Year = year
+				info.Confidence.Year = ConfidenceHigh
+			}
+		}
+		break
+	}
+
+	if info.Venue == "" {
+		if m := venuePattern.FindStringSubmatch(comment); m != nil {
+			info.Venue = m[1]
+			info.Confidence.Venue = ConfidenceLow
+			if year := expandYear(m[2]); year != 0 {
+				info.Year = year
+				info.Confidence.Year = ConfidenceHigh
+			}
+		}
+	}
+	if info.Year == 0 {
+		if m := yearPattern.FindStringSubmatch(comment); m != nil {
+			info.Year, _ = strconv.Atoi(m[1])
+			info.Confidence.Year = ConfidenceLow
+		}
+	}
+	return info
+}
+
+// venueAfter returns the venue named at the start of s, the text following
+// a phrase such as "accepted at", and the year given with it.
+func venueAfter(s string) (string, int) {
+	// The venue runs to the end of the clause, keeping parenthesized
+	// acronyms such as "(NeurIPS 2023)" with it.
+	depth, end := 0, len(s)
+scan:
+	for i, r := range s {
+		switch r {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			if depth == 0 {
+				end = i
+				break scan
+			}
+			depth--
+		case ',', ';':
+			if depth == 0 {
+				end = i
+				break scan
+			}
+		case '.':
+			if depth == 0 && endsSentence(s[:i], s[i+1:]) {
+				end = i
+				break scan
+			}
+		}
+	}
+	venue := strings.TrimSpace(s[:end])
+	if strings.HasPrefix(venue, "(") {
+		return "", 0
+	}
+
+	var year int
+	if m := yearPattern.FindStringIndex(venue); m != nil {
+		year, _ = strconv.Atoi(venue[m[0]:m[1]])
+		venue = venue[:m[0]] + venue[m[1]:]
+	} else if m := shortYear.FindStringSubmatchIndex(venue); m != nil {
+		year = expandYear(venue[m[2]:m[3]])
+		venue = venue[:m[0]] + venue[m[1]:]
+	}
+	venue = strings.NewReplacer("( ", "(", " )", ")", "()", "", "[]", "").Replace(normalizeSpace(venue))
+	venue = strings.TrimRight(normalizeSpace(venue), " .:-")
+	for _, prefix := range []string{"the ", "The "} {
+		venue = strings.TrimPrefix(venue, prefix)
+	}
+	if venue == "" || len(strings.Fields(venue)) > 15 {
+		return "", year
+	}
+	return venue, year
+}
+
+// endsSentence reports whether a period between before and after ends a
+// sentence rather than an abbreviation such as "Phys.": it must be followed
+// by a space and come after a number or an acronym such as "ICML".
+func endsSentence(before, after string) bool {
+	if after != "" && after[0] != ' ' {
+		return false
+	}
+	word := before[strings.LastIndexByte(before, ' ')+1:]
+	if word == "" {
+		return false
+	}
+	if _, err := strconv.Atoi(word); err == nil {
+		return true
+	}
+	return len(word) >= 2 && strings.ToUpper(word) == word && strings.ToLower(word) != word
+}
+
+// parseCount parses a count written in digits or as a word from "one" to
+// "twenty"; "no" is zero.
+func parseCount(s string) int {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n
+	}
+	words := strings.Split(numberWord, "|")[1:]
+	for i, word := range words {
+		if strings.EqualFold(s, word) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// expandYear turns a four-digit year or a two-digit one such as "23" into a
+// year; it returns 0 for an empty string.
+func expandYear(s string) int {
+	n, err := strconv.Atoi(s)
+	switch {
+	case err != nil:
+		return 0
+	case len(s) == 2 && n < 50:
+		return 2000 + n
+	case len(s) == 2:
+		return 1900 + n
+	}
+	return n
+}
@@ -0,0 +1,200 @@
+package arxiv
+
+import "testing"
+
+func TestParseComment(t *testing.T) {
+	const (
+		low  = ConfidenceLow
+		high = ConfidenceHigh
+	)
+	tests := []struct {
+		comment string
+		want    CommentInfo
+	}{
+		{"", CommentInfo{}},
+		{"12 pages, 5 figures, accepted at NeurIPS 2023", CommentInfo{
+			Pages: 12, Figures: 5, Venue: "NeurIPS", Status: StatusAccepted, Year: 2023,
+			Confidence: CommentConfidence{Pages: high, Figures: high, Venue: high, Status: high, Year: high},
+		}},
+		{"37 pages, 15 figures", CommentInfo{
+			Pages: 37, Figures: 15,
+			Confidence: CommentConfidence{Pages: high, Figures: high},
+		}},
+		{"8 pages, 3 figures, 2 tables", CommentInfo{
+			Pages: 8, Figures: 3, Tables: 2,
+			Confidence: CommentConfidence{Pages: high, Figures: high, Tables: high},
+		}},
+		{"9 pages, no figures", CommentInfo{
+			Pages:      9,
+			Confidence: CommentConfidence{Pages: high, Figures: high},
+		}},
+		{"8+2 pages, 4 color figures", CommentInfo{
+			Pages: 10, Figures: 4,
+			Confidence: CommentConfidence{Pages: high, Figures: high},
+		}},
+		{"23pp, 7 eps figs.", CommentInfo{
+			Pages: 23, Figures: 7,
+			Confidence: CommentConfidence{Pages: high, Figures: high},
+		}},
+		{"Two pages, one figure", CommentInfo{
+			Pages: 2, Figures: 1,
+			Confidence: CommentConfidence{Pages: high, Figures: high},
+		}},
+		{"A 4-page workshop paper with 2 tables", CommentInfo{
+			Pages: 4, Tables: 2,
+			Confidence: CommentConfidence{Pages: high, Tables: high},
+		}},
+		{"LaTeX, 14 pages, 3 tables, 6 PostScript figures", CommentInfo{
+			Pages: 14, Figures: 6, Tables: 3,
+			Confidence: CommentConfidence{Pages: high, Figures: high, Tables: high},
+		}},
+		{"To appear in Graphs and Combinatorics", CommentInfo{
+			Venue: "Graphs and Combinatorics", Status: StatusAccepted,
+			Confidence: CommentConfidence{Venue: high, Status: high},
+		}},
+		{"Accepted for publication in The Astrophysical Journal; 20 pages", CommentInfo{
+			Pages: 20, Venue: "Astrophysical Journal", Status: StatusAccepted,
+			Confidence: CommentConfidence{Pages: high, Venue: high, Status: high},
+		}},
+		{"Accepted as a spotlight paper at ICLR 2024", CommentInfo{
+			Venue: "ICLR", Status: StatusAccepted, Year: 2024,
+			Confidence: CommentConfidence{Venue: high, Status: high, Year: high},
+		}},
+		{"Accepted by Physical Review Letters", CommentInfo{
+			Venue: "Physical Review Letters", Status: StatusAccepted,
+			Confidence: CommentConfidence{Venue: high, Status: high},
+		}},
+		{"accepted to the 37th Conference on Neural Information Processing Systems (NeurIPS 2023)", CommentInfo{
+			Venue: "37th Conference on Neural Information Processing Systems (NeurIPS)", Status: StatusAccepted, Year: 2023,
+			Confidence: CommentConfidence{Venue: high, Status: high, Year: high},
+		}},
+		{"Published in Nature Physics, 2016", CommentInfo{
+			Venue: "Nature Physics", Status: StatusPublished, Year: 2016,
+			Confidence: CommentConfidence{Venue: high, Status: high, Year: low},
+		}},
+		{"Appeared in Proceedings of STOC 2019", CommentInfo{
+			Venue: "Proceedings of STOC", Status: StatusPublished, Year: 2019,
+			Confidence: CommentConfidence{Venue: high, Status: high, Year: high},
+		}},
+		{"Submitted to Phys. Rev. B", CommentInfo{
+			Venue: "Phys. Rev. B", Status: StatusSubmitted,
+			Confidence: CommentConfidence{Venue: high, Status: high},
+		}},
+		{"submitted for publication to IEEE Transactions on Information Theory, 30 pages", CommentInfo{
+			Pages: 30, Venue: "IEEE Transactions on Information Theory", Status: StatusSubmitted,
+			Confidence: CommentConfidence{Pages: high, Venue: high, Status: high},
+		}},
+		{"Under review at ICML'24", CommentInfo{
+			Venue: "ICML", Status: StatusUnderReview, Year: 2024,
+			Confidence: CommentConfidence{Venue: high, Status: high, Year: high},
+		}},
+		{"Under review", CommentInfo{
+			Status:     StatusUnderReview,
+			Confidence: CommentConfidence{Status: high},
+		}},
+		{"This paper has been withdrawn by the author due to an error in Lemma 3", CommentInfo{
+			Status:     StatusWithdrawn,
+			Confidence: CommentConfidence{Status: high},
+		}},
+		{"In press", CommentInfo{
+			Status:     StatusAccepted,
+			Confidence: CommentConfidence{Status: high},
+		}},
+		{"Camera-ready version", CommentInfo{
+			Status:     StatusAccepted,
+			Confidence: CommentConfidence{Status: low},
+		}},
+		{"Accepted manuscript", CommentInfo{
+			Status:     StatusAccepted,
+			Confidence: CommentConfidence{Status: low},
+		}},
+		{"Submitted; comments welcome", CommentInfo{
+			Status:     StatusSubmitted,
+			Confidence: CommentConfidence{Status: low},
+		}},
+		{"Contribution to the proceedings of Lattice 2019, 7 pages", CommentInfo{
+			Pages: 7, Venue: "Lattice", Status: StatusPublished, Year: 2019,
+			Confidence: CommentConfidence{Pages: high, Venue: high, Status: low, Year: high},
+		}},
+		{"Talk given at the Moriond EW 2008 conference, 4 pages", CommentInfo{
+			Pages: 4, Venue: "Moriond EW conference", Status: StatusPublished, Year: 2008,
+			Confidence: CommentConfidence{Pages: high, Venue: high, Status: low, Year: high},
+		}},
+		{"Accepted at ICML 2023. To appear in the proceedings of ICML", CommentInfo{
+			Venue: "ICML", Status: StatusAccepted, Year: 2023,
+			Confidence: CommentConfidence{Venue: high, Status: high, Year: high},
+		}},
+		{"Code at https://github.com/example/repo. CVPR 2022", CommentInfo{
+			Venue: "CVPR", Year: 2022,
+			Confidence: CommentConfidence{Venue: low, Year: high},
+		}},
+		{"NeurIPS'21 workshop on Deep Generative Models", CommentInfo{
+			Venue: "NeurIPS", Year: 2021,
+			Confidence: CommentConfidence{Venue: low, Year: high},
+		}},
+		{"Extended version of the ACL paper", CommentInfo{
+			Venue:      "ACL",
+			Confidence: CommentConfidence{Venue: low},
+		}},
+		{"Revised version, data from the 2018 run", CommentInfo{
+			Year:       2018,
+			Confidence: CommentConfidence{Year: low},
+		}},
+		{"v2: typos corrected", CommentInfo{}},
+		{"RevTeX4, 10 pages", CommentInfo{
+			Pages:      10,
+			Confidence: CommentConfidence{Pages: high},
+		}},
+		{"Ph.D. thesis, 180 pages", CommentInfo{
+			Pages:      180,
+			Confidence: CommentConfidence{Pages: high},
+		}},
+		{"5 pages, 3 figures, Supplementary Material: 12 pages", CommentInfo{
+			Pages: 5, Figures: 3,
+			Confidence: CommentConfidence{Pages: high, Figures: high},
+		}},
+		{"ten pages, twelve figures", CommentInfo{
+			Pages: 10, Figures: 12,
+			Confidence: CommentConfidence{Pages: high, Figures: high},
+		}},
+		{"1 table", CommentInfo{
+			Tables:     1,
+			Confidence: CommentConfidence{Tables: high},
+		}},
+		{"forthcoming in Econometrica", CommentInfo{
+			Venue: "Econometrica", Status: StatusAccepted,
+			Confidence: CommentConfidence{Venue: high, Status: high},
+		}},
+		{"Accepted at (with minor revisions)", CommentInfo{
+			Status:     StatusAccepted,
+			Confidence: CommentConfidence{Status: high},
+		}},
+		{"Presented at the 2021 IEEE International Symposium on Information Theory (ISIT)", CommentInfo{
+			Venue: "IEEE International Symposium on Information Theory (ISIT)", Status: StatusPublished, Year: 2021,
+			Confidence: CommentConfidence{Venue: high, Status: low, Year: high},
+		}},
+		{"  12   pages,\n  5 figures  ", CommentInfo{
+			Pages: 12, Figures: 5,
+			Confidence: CommentConfidence{Pages: high, Figures: high},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.comment, func(t *testing.T) {
+			got := ParseComment(tt.comment)
+			if got != tt.want {
+				t.Errorf("ParseComment(%q)\n got %+v\nwant %+v", tt.comment, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntryCommentInfo(t *testing.T) {
+	e := EntryMetadata{Comment: "6 pages, accepted at ICASSP 2024"}
+	info := e.CommentInfo()
+	if info.Pages != 6 || info.Venue != "ICASSP" || info.Year != 2024 || info.Status != StatusAccepted {
+		t.Errorf("CommentInfo() = %+v", info)
+	}
+	if info.Confidence.Figures.String() != "none" || info.Confidence.Pages.String() != "high" {
+		t.Errorf("confidence = %+v", info.Confidence)
+	}
+}
@@ -0,0 +1,236 @@
+package arxiv
+
+import (
+	"iter"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// DedupPolicy chooses between two versions of the same paper: it reports
+// whether candidate should replace kept.
+type DedupPolicy func(kept, candidate EntryMetadata) bool
+
+// KeepNewest keeps the entry updated most recently, and of two updated at
+// the same time the higher version. It is the default policy.
+func KeepNewest(kept, candidate EntryMetadata) bool {
+	if c := candidate.Updated.Compare(kept.Updated); c != 0 {
+		return c > 0
+	}
+	return candidate.Version() > kept.Version()
+}
+
+// KeepHighestVersion keeps the entry with the highest version number.
+func KeepHighestVersion(kept, candidate EntryMetadata) bool {
+	return candidate.Version() > kept.Version()
+}
+
+// KeepFirst keeps the first entry seen of each paper.
+func KeepFirst(kept, candidate EntryMetadata) bool {
+	return false
+}
+
+// DedupOption configures a Deduper.
+type DedupOption func(*Deduper)
+
+// WithDedupPolicy sets the policy choosing which version of a paper to keep
+// (default KeepNewest). A nil policy is KeepNewest.
+func WithDedupPolicy(policy DedupPolicy) DedupOption {
+	return func(d *Deduper) {
+		if policy == nil {
+			policy = KeepNewest
+		}
+		d.policy = policy
+	}
+}
+
+// WithDedupStreaming makes DedupIter yield each paper as soon as it is first
+// seen, rather than once it has read all of its input. A later version can
+// then no longer replace the one yielded, so the first seen is yielded
+// whatever the policy, as with KeepFirst.
+func WithDedupStreaming() DedupOption {
+	return func(d *Deduper) {
+		d.streaming = true
+	}
+}
+
+// DedupEntry is a paper found one or more times.
+type DedupEntry struct {
+	Entry    EntryMetadata `json:"entry"`             // Version kept by the policy.
+	Sources  []string      `json:"sources,omitempty"` // Sources that returned the paper, in the order first seen.
+	Versions []string      `json:"versions"`          // arXiv IDs of the versions seen, in the order first seen.
+	Seen     int           `json:"seen"`              // Number of times the paper was added.
+}
+
+// DedupStats counts the duplicates a Deduper collapsed.
+type DedupStats struct {
+	Added        int `json:"added"`        // Entries added.
+	Unique       int `json:"unique"`       // Distinct papers.
+	Duplicates   int `json:"duplicates"`   // Entries collapsed into an earlier one of the same paper.
+	SameVersion  int `json:"sameVersion"`  // Duplicates of a version already seen.
+	OtherVersion int `json:"otherVersion"` // Duplicates of a version not seen before.
+	Replaced     int `json:"replaced"`     // Times the policy replaced the kept version with a duplicate.
+}
+
+// Deduper collapses entries of the same paper, keyed by base arXiv ID, such
+// as those returned by several searches, keeping one version of each
+// chosen by its policy and recording where each paper was found:
+//
+//	d := arxiv.NewDeduper()
+//	d.AddResults(client.SearchMany(ctx, params))
+//	for _, e := range d.Entries() {
+//		fmt.Println(e.Entry.ArxivID(), e.Sources)
+//	}
+//
+// Entries without an ID are never collapsed. A Deduper is safe for
+// concurrent use.
+type Deduper struct {
+	policy    DedupPolicy
+	streaming bool
+
+	mu      sync.Mutex
+	entries []DedupEntry
+	index   map[string]int
+	stats   DedupStats
+}
+
+// NewDeduper returns an empty Deduper configured by options.
+func NewDeduper(options ...DedupOption) *Deduper {
+	d := &Deduper{policy: KeepNewest, index: map[string]int{}}
+	for _, option := range options {
+		option(d)
+	}
+	return d
+}
+
+// Add adds an entry returned by source, which may be empty, and reports
+// whether it is the first entry of its paper.
+func (d *Deduper) Add(entry EntryMetadata, source string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stats.Added++
+	id := entry.BaseID()
+	i, ok := d.index[id]
+	if !ok || id == "" {
+		e := DedupEntry{Entry: entry, Versions: []string{entry.ArxivID()}, Seen: 1}
+		if source != "" {
+			e.Sources = []string{source}
+		}
+		if id != "" {
+			d.index[id] = len(d.entries)
+		}
+		d.entries = append(d.entries, e)
+		d.stats.Unique++
+		return true
+	}
+
+	e := &d.entries[i]
+	e.Seen++
+	d.stats.Duplicates++
+	if source != "" && !slices.Contains(e.Sources, source) {
+		e.Sources = append(e.Sources, source)
+	}
+	if version := entry.ArxivID(); slices.Contains(e.Versions, version) {
+		d.stats.SameVersion++
+	} else {
+		e.Versions = append(e.Versions, version)
+		d.stats.OtherVersion++
+	}
+	if d.policy(e.Entry, entry) {
+		e.Entry = entry
+		d.stats.Replaced++
+	}
+	return false
+}
+
+// AddResults adds the entries of successful searches, as returned by
+// SearchMany, with the query of each search, or its ID list, as source.
+// Unlike MergeQueryResults, it records sources by query rather than index,
+// chooses the version kept by the policy, and counts duplicates.
+func (d *Deduper) AddResults(results []QueryResult) {
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		source := result.Params.Query
+		if source == "" {
+			source = strings.Join(result.Params.IdList, ",")
+		}
+		for _, entry := range result.Results.Entries {
+			d.Add(entry, source)
+		}
+	}
+}
+
+// Filter returns an iterator over the entries of seq that are the first of
+// their paper, adding every entry of seq with source. Since later entries
+// are not known yet, the version yielded is the first seen; Entries gives
+// the versions kept by the policy once iteration is done.
+func (d *Deduper) Filter(seq iter.Seq[EntryMetadata], source string) iter.Seq[EntryMetadata] {
+	return func(yield func(EntryMetadata) bool) {
+		for entry := range seq {
+			if d.Add(entry, source) && !yield(entry) {
+				return
+			}
+		}
+	}
+}
+
+// Entries returns the papers added, in the order first seen.
+func (d *Deduper) Entries() []DedupEntry {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	entries := make([]DedupEntry, len(d.entries))
+	for i, e := range d.entries {
+		e.Sources = slices.Clone(e.Sources)
+		e.Versions = slices.Clone(e.Versions)
+		entries[i] = e
+	}
+	return entries
+}
+
+// Stats returns counts of the entries added and the duplicates collapsed.
+func (d *Deduper) Stats() DedupStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stats
+}
+
+// Dedup returns entries with one version of each paper, keyed by base arXiv
+// ID, in the order each paper was first seen. The version kept is the
+// newest by Updated unless options set another policy.
+func Dedup(entries []EntryMetadata, options ...DedupOption) []EntryMetadata {
+	d := NewDeduper(options...)
+	for _, entry := range entries {
+		d.Add(entry, "")
+	}
+	out := make([]EntryMetadata, len(d.entries))
+	for i, e := range d.entries {
+		out[i] = e.Entry
+	}
+	return out
+}
+
+// DedupIter returns an iterator over the entries of seq with one version of
+// each paper, as Dedup returns them. Whether the version kept is final is
+// only known once seq is exhausted, so DedupIter reads all of seq before
+// yielding, which for an unbounded search means every page. With
+// WithDedupStreaming it yields the first version of each paper as soon as
+// it is seen instead.
+func DedupIter(seq iter.Seq[EntryMetadata], options ...DedupOption) iter.Seq[EntryMetadata] {
+	return func(yield func(EntryMetadata) bool) {
+		d := NewDeduper(options...)
+		if d.streaming {
+			d.Filter(seq, "")(yield)
+			return
+		}
+		for entry := range seq {
+			d.Add(entry, "")
+		}
+		for _, e := range d.Entries() {
+			if !yield(e.Entry) {
+				return
+			}
+		}
+	}
+}
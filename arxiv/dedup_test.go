@@ -0,0 +1,169 @@
+package arxiv
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func dedupEntry(id string, day int) EntryMetadata {
+	updated := time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)
+	return EntryMetadata{ID: "http://arxiv.org/abs/" + id, Updated: updated}
+}
+
+func TestDedup(t *testing.T) {
+	entries := []EntryMetadata{
+		dedupEntry("2401.00001v2", 5),
+		dedupEntry("2401.00002v1", 1),
+		dedupEntry("2401.00001v1", 1),
+		dedupEntry("2401.00001v3", 3), // Updated before v2, as a stale mirror might give.
+		dedupEntry("2401.00002v1", 1),
+		dedupEntry("cond-mat/0102536v1", 1),
+	}
+	tests := []struct {
+		name   string
+		policy DedupPolicy
+		want   []string
+	}{
+		{"default", nil, []string{"2401.00001v2", "2401.00002v1", "cond-mat/0102536v1"}},
+		{"highest version", KeepHighestVersion, []string{"2401.00001v3", "2401.00002v1", "cond-mat/0102536v1"}},
+		{"first", KeepFirst, []string{"2401.00001v2", "2401.00002v1", "cond-mat/0102536v1"}},
+	}
+	for _, tt := range tests {
+		var options []DedupOption
+		if tt.policy != nil {
+			options = append(options, WithDedupPolicy(tt.policy))
+		}
+		var got []string
+		for _, e := range Dedup(entries, options...) {
+			got = append(got, e.ArxivID())
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: Dedup() = %q; want %q", tt.name, got, tt.want)
+		}
+	}
+
+	// A nil policy is the default rather than a panic on the first duplicate.
+	if got := Dedup(entries, WithDedupPolicy(nil)); len(got) != 3 || got[0].ArxivID() != "2401.00001v2" {
+		t.Errorf("Dedup(WithDedupPolicy(nil)) = %v; want the default", got)
+	}
+
+	// The same time breaks ties by version.
+	tied := []EntryMetadata{dedupEntry("2401.00001v2", 1), dedupEntry("2401.00001v1", 1), dedupEntry("2401.00001v3", 1)}
+	if got := Dedup(tied); len(got) != 1 || got[0].ArxivID() != "2401.00001v3" {
+		t.Errorf("Dedup(tied) = %v; want v3", got)
+	}
+}
+
+func TestDeduper(t *testing.T) {
+	results := []QueryResult{
+		{Index: 0, Params: SearchParams{Query: "cat:cs.LG"}, Results: SearchResults{Entries: []EntryMetadata{
+			dedupEntry("2401.00001v1", 1), dedupEntry("2401.00002v1", 1),
+		}}},
+		{Index: 1, Params: SearchParams{Query: "cat:cs.AI"}, Err: errors.New("failed"), Results: SearchResults{Entries: []EntryMetadata{
+			dedupEntry("2401.00009v1", 1),
+		}}},
+		{Index: 2, Params: SearchParams{IdList: []string{"2401.00001", "2401.00003"}}, Results: SearchResults{Entries: []EntryMetadata{
+			dedupEntry("2401.00001v2", 2), dedupEntry("2401.00003v1", 1),
+		}}},
+		{Index: 3, Params: SearchParams{Query: "cat:cs.CL"}, Results: SearchResults{Entries: []EntryMetadata{
+			dedupEntry("2401.00001v2", 2), dedupEntry("2401.00001v2", 2),
+		}}},
+	}
+	d := NewDeduper()
+	d.AddResults(results)
+
+	entries := d.Entries()
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.Entry.ArxivID())
+	}
+	if want := []string{"2401.00001v2", "2401.00002v1", "2401.00003v1"}; !slices.Equal(ids, want) {
+		t.Fatalf("Entries() = %q; want %q", ids, want)
+	}
+	first := entries[0]
+	if want := []string{"cat:cs.LG", "2401.00001,2401.00003", "cat:cs.CL"}; !slices.Equal(first.Sources, want) {
+		t.Errorf("Sources = %q; want %q", first.Sources, want)
+	}
+	if want := []string{"2401.00001v1", "2401.00001v2"}; !slices.Equal(first.Versions, want) || first.Seen != 4 {
+		t.Errorf("Versions = %q, Seen = %d; want %q, 4", first.Versions, first.Seen, want)
+	}
+
+	want := DedupStats{Added: 6, Unique: 3, Duplicates: 3, SameVersion: 2, OtherVersion: 1, Replaced: 1}
+	if got := d.Stats(); got != want {
+		t.Errorf("Stats() = %+v; want %+v", got, want)
+	}
+
+	// Entries returns copies.
+	entries[0].Sources[0] = "changed"
+	if d.Entries()[0].Sources[0] != "cat:cs.LG" {
+		t.Error("Entries() shares its slices with the Deduper")
+	}
+
+	// Entries without an ID are kept apart.
+	if !d.Add(EntryMetadata{Title: "a"}, "") || !d.Add(EntryMetadata{Title: "b"}, "") {
+		t.Error("Add() collapsed entries without an ID")
+	}
+}
+
+func TestDedupIter(t *testing.T) {
+	entries := []EntryMetadata{
+		dedupEntry("2401.00001v1", 1),
+		dedupEntry("2401.00002v1", 1),
+		dedupEntry("2401.00001v2", 2),
+		dedupEntry("2401.00003v1", 1),
+	}
+
+	tests := []struct {
+		name    string
+		options []DedupOption
+		want    []string
+		pulled  int // Entries of seq read before the first is yielded
+	}{
+		{"default", nil, []string{"2401.00001v2", "2401.00002v1", "2401.00003v1"}, 4},
+		{"nil policy", []DedupOption{WithDedupPolicy(nil)}, []string{"2401.00001v2", "2401.00002v1", "2401.00003v1"}, 4},
+		{"first", []DedupOption{WithDedupPolicy(KeepFirst)}, []string{"2401.00001v1", "2401.00002v1", "2401.00003v1"}, 4},
+		{"streaming", []DedupOption{WithDedupStreaming()}, []string{"2401.00001v1", "2401.00002v1", "2401.00003v1"}, 1},
+	}
+	for _, tt := range tests {
+		pulled, firstPulled := 0, 0
+		seq := func(yield func(EntryMetadata) bool) {
+			for _, e := range entries {
+				pulled++
+				if !yield(e) {
+					return
+				}
+			}
+		}
+		var got []string
+		for e := range DedupIter(seq, tt.options...) {
+			if got == nil {
+				firstPulled = pulled
+			}
+			got = append(got, e.ArxivID())
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: DedupIter() = %q; want %q", tt.name, got, tt.want)
+		}
+		if firstPulled != tt.pulled {
+			t.Errorf("%s: DedupIter() read %d entries before yielding; want %d", tt.name, firstPulled, tt.pulled)
+		}
+	}
+
+	// Stopping early stops adding.
+	d := NewDeduper()
+	for range d.Filter(slices.Values(entries), "search") {
+		break
+	}
+	if got := d.Stats().Added; got != 1 {
+		t.Errorf("Added = %d after stopping at the first entry; want 1", got)
+	}
+
+	d = NewDeduper()
+	for range d.Filter(slices.Values(entries), "search") {
+	}
+	if got := d.Entries()[0]; got.Entry.ArxivID() != "2401.00001v2" || !slices.Equal(got.Sources, []string{"search"}) {
+		t.Errorf("Entries()[0] = %+v; want v2 from search", got)
+	}
+}
@@ -0,0 +1,401 @@
+package arxiv
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultDownloadBaseURL is the site PDFs and source files are downloaded from.
+const DefaultDownloadBaseURL = "https://arxiv.org"
+
+// ErrUnexpectedContentType is returned when a download is served with a
+// content type that does not match the requested file, typically an HTML
+// error page in place of a PDF.
+var ErrUnexpectedContentType = errors.New("arxiv: unexpected content type")
+
+// ErrIncompleteDownload is returned when the connection ends before the
+// number of bytes announced by the server has been received. Downloads
+// failing this way are retried from where they stopped.
+var ErrIncompleteDownload = errors.New("arxiv: incomplete download")
+
+// DownloadKind selects the file downloaded for a paper.
+type DownloadKind string
+
+const (
+	DownloadKindPDF    DownloadKind = "pdf"    // The PDF rendering of the paper
+	DownloadKindSource DownloadKind = "source" // The e-print source, usually a gzipped tar archive
+)
+
+// contentTypes lists the content types accepted for each kind of download.
+var contentTypes = map[DownloadKind][]string{
+	DownloadKindPDF: {"application/pdf"},
+	DownloadKindSource: {
+		"application/x-eprint-tar",
+		"application/x-eprint",
+		"application/gzip",
+		"application/x-gzip",
+		"application/x-tar",
+		"application/octet-stream",
+		"application/pdf", // Submissions made as PDF have no other source
+	},
+}
+
+// DownloadResult describes a completed download.
+type DownloadResult struct {
+	URL         string // URL the file was downloaded from
+	ContentType string // Content type reported by the server
+	Size        int64  // Size of the complete file in bytes
+	Written     int64  // Bytes transferred by this call; less than Size when a download was resumed
+	SHA256      string // Hex-encoded SHA-256 checksum of the complete file
+	Resumed     bool   // Whether the download continued a partial one
+}
+
+// WithDownloadBaseURL sets the site PDFs and source files are downloaded
+// from, for example a mirror or a local test server.
+func WithDownloadBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.DownloadBaseURL = baseURL
+	}
+}
+
+// DownloadURL returns the URL of the file of the given kind for the paper
+// with the given arXiv ID. A version suffix selects that version; without one
+// the latest version is downloaded.
+func (c *Client) DownloadURL(kind DownloadKind, id string) (string, error) {
+	id = strings.TrimSpace(id)
+	if i := strings.Index(id, "/abs/"); i >= 0 {
+		id = id[i+len("/abs/"):]
+	}
+	if id == "" {
+		return "", errors.New("arxiv: empty arXiv ID")
+	}
+	base := strings.TrimSuffix(c.DownloadBaseURL, "/")
+	switch kind {
+	case DownloadKindPDF:
+		return base + "/pdf/" + id, nil
+	case DownloadKindSource:
+		return base + "/e-print/" + id, nil
+	}
+	return "", fmt.Errorf("arxiv: unknown download kind %q", kind)
+}
+
+// DownloadPDF streams the PDF of the paper with the given arXiv ID to w.
+// The download is paced by the client's rate limiter and retried according
+// to its retry policy; a transfer cut short is resumed where it stopped.
+func (c *Client) DownloadPDF(ctx context.Context, id string, w io.Writer) (DownloadResult, error) {
+	return c.Download(ctx, DownloadKindPDF, id, w)
+}
+
+// DownloadSource streams the e-print source of the paper with the given arXiv
+// ID to w. See DownloadPDF.
+func (c *Client) DownloadSource(ctx context.Context, id string, w io.Writer) (DownloadResult, error) {
+	return c.Download(ctx, DownloadKindSource, id, w)
+}
+
+// Download streams the file of the given kind for the paper with the given
+// arXiv ID to w.
+func (c *Client) Download(ctx context.Context, kind DownloadKind, id string, w io.Writer) (DownloadResult, error) {
+	url, err := c.DownloadURL(kind, id)
+	if err != nil {
+		return DownloadResult{}, err
+	}
+	return c.download(ctx, kind, url, w, sha256.New(), partial{})
+}
+
+// DownloadFile downloads the file of the given kind for the paper with the
+// given arXiv ID to path. Data is written to path+".part" and renamed once
+// complete, so a download interrupted by an error or a cancelled context is
+// resumed by calling DownloadFile again with the same path. The ETag or
+// Last-Modified time of the file is kept in path+".part.validator", and the
+// download starts over if the file has changed since, as it does when a new
+// version of a paper is announced.
+func (c *Client) DownloadFile(ctx context.Context, kind DownloadKind, id, path string) (DownloadResult, error) {
+	url, err := c.DownloadURL(kind, id)
+	if err != nil {
+		return DownloadResult{}, err
+	}
+
+	partPath, validatorPath := path+".part", path+".part.validator"
+	f, err := os.OpenFile(partPath, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return DownloadResult{}, err
+	}
+	defer f.Close()
+
+	restart := func() error {
+		if err := f.Truncate(0); err != nil {
+			return err
+		}
+		_, err := f.Seek(0, io.SeekStart)
+		return err
+	}
+
+	// Hash what an earlier attempt left behind so that the checksum covers
+	// the whole file. Without a validator, there is no telling whether it is
+	// part of the file served now, so it is discarded.
+	h := sha256.New()
+	p := partial{restart: restart}
+	if validator, err := os.ReadFile(validatorPath); err == nil {
+		p.validator = string(validator)
+		if p.offset, err = io.Copy(h, f); err != nil {
+			return DownloadResult{}, err
+		}
+	} else if err := restart(); err != nil {
+		return DownloadResult{}, err
+	}
+	p.validated = func(validator string) error {
+		return os.WriteFile(validatorPath, []byte(validator), 0o644)
+	}
+
+	result, err := c.download(ctx, kind, url, f, h, p)
+	if err != nil {
+		return result, err
+	}
+	if err := f.Close(); err != nil {
+		return result, err
+	}
+	if err := os.Rename(partPath, path); err != nil {
+		return result, err
+	}
+	if err := os.Remove(validatorPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return result, err
+	}
+	return result, nil
+}
+
+// partial describes the part of a file written before a download starts.
+type partial struct {
+	offset    int64  // Bytes already written
+	validator string // ETag or Last-Modified time of the response they came from
+
+	// restart discards the bytes written so the download can start over
+	// when the file has changed; it is nil if they cannot be discarded.
+	restart func() error
+	// validated, if set, records the validator of a response before its
+	// body is written.
+	validated func(validator string) error
+}
+
+// download fetches url and writes it to w, which already holds the part p of
+// the file, hashed into h. A range request made to continue the file is
+// conditional on its validator, so that the bytes of two different files are
+// never joined.
+func (c *Client) download(ctx context.Context, kind DownloadKind, url string, w io.Writer, h hash.Hash, p partial) (DownloadResult, error) {
+	offset := p.offset
+	result := DownloadResult{URL: url, Resumed: offset > 0}
+	written := offset
+	// An attempt fails with a nil result; the result is only set once the
+	// whole file has been written.
+	_, err := withRetry(ctx, c, func(ctx context.Context) (struct{}, *http.Response, error) {
+		response, err := c.downloadRequest(ctx, url, written, p.validator)
+		if err != nil {
+			return struct{}{}, nil, err
+		}
+		defer response.Body.Close()
+
+		switch response.StatusCode {
+		case http.StatusOK, http.StatusPartialContent:
+		case http.StatusRequestedRangeNotSatisfiable:
+			// The file is already complete if the range starts at its end.
+			if size, ok := contentRangeSize(response.Header.Get("Content-Range")); ok && size == written {
+				result.Size = size
+				return struct{}{}, nil, nil
+			}
+			return struct{}{}, response, fmt.Errorf("arxiv: download of %s failed: %s", url, response.Status)
+		default:
+			return struct{}{}, response, fmt.Errorf("arxiv: download of %s failed: %s", url, response.Status)
+		}
+
+		contentType := response.Header.Get("Content-Type")
+		if mediaType, _, err := mime.ParseMediaType(contentType); err != nil || !acceptsContentType(kind, mediaType) {
+			return struct{}{}, nil, fmt.Errorf("%w: %s for %s", ErrUnexpectedContentType, contentType, url)
+		}
+		result.ContentType = contentType
+
+		validator := responseValidator(response)
+		if response.StatusCode == http.StatusOK && written > 0 && (validator == "" || validator != p.validator) {
+			// The file changed since the part written was received, or
+			// the server cannot say it did not.
+			if p.restart == nil {
+				return struct{}{}, nil, fmt.Errorf("arxiv: %s changed during the download", url)
+			}
+			if err := p.restart(); err != nil {
+				return struct{}{}, nil, err
+			}
+			h.Reset()
+			written, offset = 0, 0
+			result.Resumed = false
+		}
+		if validator != p.validator {
+			p.validator = validator
+			if p.validated != nil {
+				if err := p.validated(validator); err != nil {
+					return struct{}{}, nil, err
+				}
+			}
+		}
+
+		body := io.Reader(response.Body)
+		size := int64(-1)
+		if response.StatusCode == http.StatusPartialContent {
+			start, total, ok := parseContentRange(response.Header.Get("Content-Range"))
+			if !ok || start != written {
+				return struct{}{}, nil, fmt.Errorf("%w: unexpected Content-Range %q", ErrIncompleteDownload, response.Header.Get("Content-Range"))
+			}
+			size = total
+		} else {
+			// The server ignored the range and sent the same file whole;
+			// skip the part that has already been written.
+			if written > 0 {
+				if _, err := io.CopyN(io.Discard, body, written); err != nil {
+					return struct{}{}, nil, fmt.Errorf("%w: %w", ErrIncompleteDownload, err)
+				}
+			}
+			if response.ContentLength >= 0 {
+				size = response.ContentLength
+			}
+		}
+
+		n, err := io.Copy(io.MultiWriter(w, h), body)
+		written += n
+		if err != nil {
+			if ctx.Err() != nil {
+				return struct{}{}, nil, ctx.Err()
+			}
+			return struct{}{}, nil, fmt.Errorf("%w: %w", ErrIncompleteDownload, err)
+		}
+		if size >= 0 && written != size {
+			return struct{}{}, nil, fmt.Errorf("%w: received %d of %d bytes", ErrIncompleteDownload, written, size)
+		}
+		result.Size = written
+		return struct{}{}, nil, nil
+	}, nil)
+
+	result.Written = written - offset
+	if err != nil {
+		return result, err
+	}
+	result.SHA256 = hex.EncodeToString(h.Sum(nil))
+	return result, nil
+}
+
+// downloadRequest waits for the rate limiter and requests url from the given
+// byte offset, if the file still has the given validator. The client's
+// timeout bounds the wait for the response headers, but not the transfer of
+// the body, which may take much longer.
+func (c *Client) downloadRequest(ctx context.Context, url string, offset int64, validator string) (*http.Response, error) {
+	if c.limiter != nil {
+		if _, err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		if validator != "" {
+			req.Header.Set("If-Range", validator)
+		}
+	}
+
+	client := *c.httpClient
+	client.Timeout = 0
+	var timer *time.Timer
+	if c.Timeout > 0 {
+		timer = time.AfterFunc(c.Timeout, cancel)
+	}
+	response, err := client.Do(req)
+	if timer != nil && !timer.Stop() && err == nil {
+		// The timeout fired just as the headers arrived.
+		response.Body.Close()
+		err = context.DeadlineExceeded
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	response.Body = cancelOnClose{response.Body, cancel}
+	return response, nil
+}
+
+// cancelOnClose releases the request's context when its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// responseValidator returns the value of the response's strong ETag, or
+// failing that its Last-Modified time, as sent in an If-Range header.
+func responseValidator(response *http.Response) string {
+	if etag := response.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return response.Header.Get("Last-Modified")
+}
+
+func acceptsContentType(kind DownloadKind, mediaType string) bool {
+	for _, accepted := range contentTypes[kind] {
+		if strings.EqualFold(mediaType, accepted) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseContentRange parses a Content-Range header of the form
+// "bytes start-end/size" and returns the start and the size.
+func parseContentRange(value string) (start, size int64, ok bool) {
+	spec, found := strings.CutPrefix(value, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	rng, total, found := strings.Cut(spec, "/")
+	if !found {
+		return 0, 0, false
+	}
+	first, _, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	size, err = strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, size, true
+}
+
+// contentRangeSize returns the size from a Content-Range header of the form
+// "bytes */size" sent with a 416 response.
+func contentRangeSize(value string) (int64, bool) {
+	total, found := strings.CutPrefix(value, "bytes */")
+	if !found {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(total, 10, 64)
+	return size, err == nil
+}
@@ -0,0 +1,201 @@
+package arxiv
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// downloadServer serves content at /pdf/<id> and /e-print/<id> with the given
+// content type and an ETag of its checksum, honoring Range requests. The
+// first response is cut off after cutAfter bytes if cutAfter is positive. It
+// records the Range header of each request.
+func downloadServer(t *testing.T, content []byte, contentType string, cutAfter int) (*httptest.Server, *[]string) {
+	t.Helper()
+	var mu sync.Mutex
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		first := len(ranges) == 1
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("ETag", etag(content))
+		if first && cutAfter > 0 {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			w.Write(content[:cutAfter])
+			return // The server closes the connection short of Content-Length
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(server.Close)
+	return server, &ranges
+}
+
+func etag(b []byte) string {
+	return `"` + checksum(b)[:16] + `"`
+}
+
+func checksum(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDownload(t *testing.T) {
+	content := bytes.Repeat([]byte("%PDF-1.5 0123456789\n"), 500)
+
+	tests := []struct {
+		name        string
+		kind        DownloadKind
+		contentType string
+		cutAfter    int
+		retry       bool
+		wantErr     error
+		wantRanges  []string
+	}{
+		{name: "pdf", kind: DownloadKindPDF, contentType: "application/pdf", wantRanges: []string{""}},
+		{name: "source", kind: DownloadKindSource, contentType: "application/x-eprint-tar", wantRanges: []string{""}},
+		{name: "html error page", kind: DownloadKindPDF, contentType: "text/html; charset=utf-8", wantErr: ErrUnexpectedContentType},
+		{name: "resumed after cut", kind: DownloadKindPDF, contentType: "application/pdf", cutAfter: 1234, retry: true,
+			wantRanges: []string{"", "bytes=1234-"}},
+		{name: "cut without retry", kind: DownloadKindPDF, contentType: "application/pdf", cutAfter: 1234, wantErr: ErrIncompleteDownload},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, ranges := downloadServer(t, content, tt.contentType, tt.cutAfter)
+			options := []ClientOption{WithDownloadBaseURL(server.URL), WithRateLimit(0)}
+			if tt.retry {
+				options = append(options, WithRetry(RetryConfig{MaxAttempts: 3, InitialInterval: time.Millisecond}))
+			}
+			client := NewClient(options...)
+
+			var buf bytes.Buffer
+			result, err := client.Download(context.Background(), tt.kind, "2401.00001v2", &buf)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Download error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), content) {
+				t.Errorf("downloaded %d bytes that differ from the %d served", buf.Len(), len(content))
+			}
+			if result.Size != int64(len(content)) || result.Written != int64(len(content)) || result.SHA256 != checksum(content) {
+				t.Errorf("result = %+v", result)
+			}
+			wantPath := "/pdf/2401.00001v2"
+			if tt.kind == DownloadKindSource {
+				wantPath = "/e-print/2401.00001v2"
+			}
+			if result.URL != server.URL+wantPath {
+				t.Errorf("URL = %s, want path %s", result.URL, wantPath)
+			}
+			if strings.Join(*ranges, ",") != strings.Join(tt.wantRanges, ",") {
+				t.Errorf("Range headers = %q, want %q", *ranges, tt.wantRanges)
+			}
+		})
+	}
+}
+
+func TestDownloadFileResumes(t *testing.T) {
+	content := bytes.Repeat([]byte("source "), 1000)
+	newVersion := bytes.Repeat([]byte("revised source "), 1000)
+
+	tests := []struct {
+		name        string
+		validator   string // Validator saved with the partial file, if any
+		served      []byte
+		wantResumed bool
+		wantRanges  []string
+	}{
+		{"same file", etag(content), content, true, []string{"bytes=3000-"}},
+		{"new version", etag(content), newVersion, false, []string{"bytes=3000-"}},
+		{"no validator", "", content, false, []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, ranges := downloadServer(t, tt.served, "application/gzip", 0)
+			client := NewClient(WithDownloadBaseURL(server.URL), WithRateLimit(0))
+
+			path := filepath.Join(t.TempDir(), "2401.00001.tar.gz")
+			if err := os.WriteFile(path+".part", content[:3000], 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.validator != "" {
+				if err := os.WriteFile(path+".part.validator", []byte(tt.validator), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			result, err := client.DownloadFile(context.Background(), DownloadKindSource, "2401.00001", path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantWritten := len(tt.served)
+			if tt.wantResumed {
+				wantWritten -= 3000
+			}
+			if result.Resumed != tt.wantResumed || result.Written != int64(wantWritten) || result.Size != int64(len(tt.served)) {
+				t.Errorf("result = %+v", result)
+			}
+			if result.SHA256 != checksum(tt.served) {
+				t.Errorf("checksum does not cover the whole file")
+			}
+			if strings.Join(*ranges, ",") != strings.Join(tt.wantRanges, ",") {
+				t.Errorf("Range headers = %q, want %q", *ranges, tt.wantRanges)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, tt.served) {
+				t.Errorf("file differs from the content served")
+			}
+			for _, leftover := range []string{path + ".part", path + ".part.validator"} {
+				if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+					t.Errorf("%s was not removed: %v", filepath.Base(leftover), err)
+				}
+			}
+		})
+	}
+}
+
+func TestDownloadFileSavesValidator(t *testing.T) {
+	content := bytes.Repeat([]byte("%PDF-1.5 "), 1000)
+	server, ranges := downloadServer(t, content, "application/pdf", 1234)
+	client := NewClient(WithDownloadBaseURL(server.URL), WithRateLimit(0))
+
+	// The first download is cut short, leaving a partial file whose
+	// validator lets the next one continue it.
+	path := filepath.Join(t.TempDir(), "2401.00001.pdf")
+	if _, err := client.DownloadFile(context.Background(), DownloadKindPDF, "2401.00001", path); !errors.Is(err, ErrIncompleteDownload) {
+		t.Fatalf("DownloadFile error = %v, want ErrIncompleteDownload", err)
+	}
+	if validator, err := os.ReadFile(path + ".part.validator"); err != nil || string(validator) != etag(content) {
+		t.Fatalf("saved validator %q, %v; want %s", validator, err, etag(content))
+	}
+	result, err := client.DownloadFile(context.Background(), DownloadKindPDF, "2401.00001", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Resumed || result.SHA256 != checksum(content) {
+		t.Errorf("result = %+v", result)
+	}
+	if want := []string{"", "bytes=1234-"}; strings.Join(*ranges, ",") != strings.Join(want, ",") {
+		t.Errorf("Range headers = %q, want %q", *ranges, want)
+	}
+}
@@ -0,0 +1,68 @@
+package arxiv
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ArxivID returns the arXiv identifier of the entry, such as "2408.03982v1"
+// or "cond-mat/0102536v1", taken from the end of its ID URL.
+func (e EntryMetadata) ArxivID() string {
+	id := e.ID
+	if i := strings.Index(id, "/abs/"); i >= 0 {
+		id = id[i+len("/abs/"):]
+	}
+	return id
+}
+
+// BaseID returns the arXiv identifier of the entry without its version
+// suffix, such as "2408.03982" or "cond-mat/0102536".
+func (e EntryMetadata) BaseID() string {
+	return BaseID(e.ArxivID())
+}
+
+// BaseID strips the version suffix from an arXiv identifier, turning
+// "2408.03982v2" into "2408.03982". Identifiers without a version are
+// returned unchanged.
+func BaseID(id string) string {
+	i := strings.LastIndexByte(id, 'v')
+	if i <= 0 || i == len(id)-1 {
+		return id
+	}
+	for _, r := range id[i+1:] {
+		if r < '0' || r > '9' {
+			return id
+		}
+	}
+	return id[:i]
+}
+
+// Version returns the version number of the entry, such as 2 for
+// "2408.03982v2", or 0 if its ID has no version.
+func (e EntryMetadata) Version() int {
+	id := e.ArxivID()
+	base := BaseID(id)
+	if base == id {
+		return 0
+	}
+	n, err := strconv.Atoi(id[len(base)+1:])
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// AuthorNames returns the names of the entry's authors in order.
+func (e EntryMetadata) AuthorNames() []string {
+	names := make([]string, len(e.Authors))
+	for i, author := range e.Authors {
+		names[i] = author.Name
+	}
+	return names
+}
+
+// normalizeSpace collapses runs of whitespace, including the line breaks
+// arXiv leaves in titles and abstracts, into single spaces.
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
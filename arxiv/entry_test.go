@@ -0,0 +1,57 @@
+package arxiv
+
+import "testing"
+
+func TestArxivID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"http://arxiv.org/abs/2408.03982v1", "2408.03982v1"},
+		{"http://arxiv.org/abs/cond-mat/0102536v1", "cond-mat/0102536v1"},
+		{"2408.03982", "2408.03982"},
+	}
+	for _, tt := range tests {
+		if got := (EntryMetadata{ID: tt.id}).ArxivID(); got != tt.want {
+			t.Errorf("ArxivID(%q) = %q; want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestBaseID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{"2408.03982v1", "2408.03982"},
+		{"2408.03982v12", "2408.03982"},
+		{"2408.03982", "2408.03982"},
+		{"cond-mat/0102536v3", "cond-mat/0102536"},
+		{"solv-int/9901001", "solv-int/9901001"},
+		{"v1", "v1"},
+		{"2408.03982v", "2408.03982v"},
+	}
+	for _, tt := range tests {
+		if got := BaseID(tt.id); got != tt.want {
+			t.Errorf("BaseID(%q) = %q; want %q", tt.id, got, tt.want)
+		}
+	}
+}
+
+func TestVersion(t *testing.T) {
+	tests := []struct {
+		id   string
+		want int
+	}{
+		{"http://arxiv.org/abs/2408.03982v1", 1},
+		{"http://arxiv.org/abs/2408.03982v12", 12},
+		{"http://arxiv.org/abs/cond-mat/0102536v3", 3},
+		{"http://arxiv.org/abs/solv-int/9901001", 0},
+		{"2408.03982", 0},
+	}
+	for _, tt := range tests {
+		if got := (EntryMetadata{ID: tt.id}).Version(); got != tt.want {
+			t.Errorf("Version(%q) = %d; want %d", tt.id, got, tt.want)
+		}
+	}
+}
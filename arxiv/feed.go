@@ -0,0 +1,222 @@
+package arxiv
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultFeedBaseURL is the site serving arXiv's daily announcement feeds.
+const DefaultFeedBaseURL = "https://rss.arxiv.org"
+
+// AnnounceType tells why an item appears in an announcement feed.
+type AnnounceType string
+
+const (
+	AnnounceNew          AnnounceType = "new"           // A new submission in the category
+	AnnounceCrossList    AnnounceType = "cross"         // A new submission cross-listed from another category
+	AnnounceReplace      AnnounceType = "replace"       // A new version of an earlier submission
+	AnnounceReplaceCross AnnounceType = "replace-cross" // A new version of a submission cross-listed from another category
+)
+
+// Feed is a daily announcement feed of one or more categories.
+type Feed struct {
+	Title     string     // Title of the feed, such as "cs.LG updates on arXiv.org"
+	Published time.Time  // Time of the announcement
+	Items     []FeedItem // Announced papers; empty on days without announcements
+}
+
+// FeedItem is a paper in an announcement feed.
+type FeedItem struct {
+	Entry        EntryMetadata // Metadata in the form returned by the search API
+	AnnounceType AnnounceType  // Why the paper was announced
+	Announced    time.Time     // Time the paper was announced
+	License      string        // URL of the license the paper is distributed under
+}
+
+// WithFeedBaseURL sets the site announcement feeds are fetched from, for
+// example a local test server.
+func WithFeedBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.FeedBaseURL = baseURL
+	}
+}
+
+// Feed fetches today's announcement feed for the given categories, such as
+// "cs.LG" or an archive such as "math". Requests are paced by the client's
+// rate limiter and retried according to its retry policy.
+func (c *Client) Feed(ctx context.Context, categories ...string) (Feed, error) {
+	if len(categories) == 0 {
+		return Feed{}, errors.New("arxiv: no feed categories")
+	}
+	escaped := make([]string, len(categories))
+	for i, category := range categories {
+		escaped[i] = url.PathEscape(strings.TrimSpace(category))
+	}
+	feedURL := strings.TrimSuffix(c.FeedBaseURL, "/") + "/rss/" + strings.Join(escaped, "+")
+
+	response, err := c.Get(ctx, feedURL)
+	if err != nil {
+		return Feed{}, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return Feed{}, fmt.Errorf("arxiv: feed request failed: %s", response.Status)
+	}
+	return ParseFeed(response.Body)
+}
+
+// ParseFeed parses an announcement feed in either the RSS 2.0 or the Atom
+// format served by arXiv.
+func ParseFeed(r io.Reader) (Feed, error) {
+	var doc struct {
+		XMLName xml.Name
+		// RSS 2.0
+		Channel struct {
+			Title   string    `xml:"title"`
+			PubDate string    `xml:"pubDate"`
+			Items   []rssItem `xml:"item"`
+		} `xml:"channel"`
+		// Atom
+		Title   string      `xml:"title"`
+		Updated string      `xml:"updated"`
+		Entries []atomEntry `xml:"entry"`
+	}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return Feed{}, fmt.Errorf("%w: %w", ErrMalformedResponse, err)
+	}
+
+	var feed Feed
+	switch doc.XMLName.Local {
+	case "rss":
+		feed.Title = normalizeSpace(doc.Channel.Title)
+		feed.Published = parseFeedTime(doc.Channel.PubDate)
+		for _, item := range doc.Channel.Items {
+			feed.Items = append(feed.Items, item.feedItem())
+		}
+	case "feed":
+		feed.Title = normalizeSpace(doc.Title)
+		feed.Published = parseFeedTime(doc.Updated)
+		for _, entry := range doc.Entries {
+			feed.Items = append(feed.Items, entry.feedItem())
+		}
+	default:
+		return Feed{}, fmt.Errorf("%w: unexpected root element <%s>", ErrMalformedResponse, doc.XMLName.Local)
+	}
+	return feed, nil
+}
+
+type rssItem struct {
+	Title        string   `xml:"title"`
+	Link         string   `xml:"link"`
+	Description  string   `xml:"description"`
+	GUID         string   `xml:"guid"`
+	Categories   []string `xml:"category"`
+	PubDate      string   `xml:"pubDate"`
+	AnnounceType string   `xml:"announce_type"`
+	Rights       string   `xml:"rights"`
+	Creator      string   `xml:"creator"`
+}
+
+func (item rssItem) feedItem() FeedItem {
+	var authors []Author
+	for _, name := range strings.Split(normalizeSpace(item.Creator), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			authors = append(authors, Author{Name: name})
+		}
+	}
+	return newFeedItem(item.GUID, item.Title, item.Link, item.Description, item.AnnounceType,
+		item.Categories, authors, parseFeedTime(item.PubDate), item.Rights)
+}
+
+type atomEntry struct {
+	ID           string     `xml:"id"`
+	Title        string     `xml:"title"`
+	Links        []Link     `xml:"link"`
+	Summary      string     `xml:"summary"`
+	Categories   []Category `xml:"category"`
+	Published    string     `xml:"published"`
+	AnnounceType string     `xml:"announce_type"`
+	Rights       string     `xml:"rights"`
+	Authors      []Author   `xml:"author"`
+}
+
+func (entry atomEntry) feedItem() FeedItem {
+	var link string
+	for _, l := range entry.Links {
+		if l.Rel == "alternate" || l.Rel == "" {
+			link = l.Href
+			break
+		}
+	}
+	categories := make([]string, len(entry.Categories))
+	for i, category := range entry.Categories {
+		categories[i] = category.Term
+	}
+	return newFeedItem(entry.ID, entry.Title, link, entry.Summary, entry.AnnounceType,
+		categories, entry.Authors, parseFeedTime(entry.Published), entry.Rights)
+}
+
+// newFeedItem builds a FeedItem from the fields shared by the RSS and Atom
+// feeds. The guid has the form "oai:arXiv.org:2404.08001v1" and the
+// description the form "arXiv:2404.08001v1 Announce Type: new Abstract: ...".
+func newFeedItem(guid, title, link, description, announceType string, categories []string, authors []Author, announced time.Time, rights string) FeedItem {
+	id := strings.TrimSpace(guid)
+	if i := strings.LastIndexByte(id, ':'); i >= 0 {
+		id = id[i+1:]
+	}
+
+	summary := strings.TrimSpace(description)
+	header, abstract, found := strings.Cut(summary, "Abstract:")
+	if found {
+		summary = strings.TrimSpace(abstract)
+		if announceType == "" {
+			if _, t, ok := strings.Cut(header, "Announce Type:"); ok {
+				announceType = strings.TrimSpace(t)
+			}
+		}
+	}
+
+	entry := EntryMetadata{
+		ID:          "http://arxiv.org/abs/" + id,
+		Title:       normalizeSpace(title),
+		Summary:     summary,
+		Authors:     authors,
+		AbstractUrl: strings.TrimSpace(link),
+		PDFUrl:      "http://arxiv.org/pdf/" + id,
+	}
+	if entry.AbstractUrl == "" {
+		entry.AbstractUrl = entry.ID
+	}
+	for i, term := range categories {
+		term = strings.TrimSpace(term)
+		if i == 0 {
+			entry.PrimaryCategory = Category{Term: term}
+		}
+		entry.Categories = append(entry.Categories, Category{Term: term})
+	}
+
+	return FeedItem{
+		Entry:        entry,
+		AnnounceType: AnnounceType(strings.TrimSpace(announceType)),
+		Announced:    announced,
+		License:      strings.TrimSpace(rights),
+	}
+}
+
+// parseFeedTime parses the RFC 1123 dates of RSS and the RFC 3339 dates of Atom.
+func parseFeedTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{time.RFC1123Z, time.RFC1123, time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,118 @@
+package arxiv
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFeed(t *testing.T) {
+	announced := time.Date(2024, 4, 15, 4, 0, 0, 0, time.UTC)
+	for _, file := range []string{"test_data/feed.rss", "test_data/feed.atom"} {
+		t.Run(file, func(t *testing.T) {
+			f, err := os.Open(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			feed, err := ParseFeed(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if feed.Title != "cs.LG updates on arXiv.org" || !feed.Published.Equal(announced) {
+				t.Errorf("feed = %q, %v", feed.Title, feed.Published)
+			}
+			if len(feed.Items) == 0 {
+				t.Fatal("no items")
+			}
+
+			item := feed.Items[0]
+			e := item.Entry
+			if item.AnnounceType != AnnounceNew || !item.Announced.Equal(announced) || item.License != "http://creativecommons.org/licenses/by/4.0/" {
+				t.Errorf("item = %q %v %q", item.AnnounceType, item.Announced, item.License)
+			}
+			if e.ArxivID() != "2404.08001v1" || e.Title != "Sparse Mixtures of Experts for Tabular Data" {
+				t.Errorf("entry = %q %q", e.ArxivID(), e.Title)
+			}
+			if e.Summary != "We study sparse mixtures of experts\nfor tabular data." {
+				t.Errorf("summary = %q", e.Summary)
+			}
+			if names := e.AuthorNames(); !reflect.DeepEqual(names, []string{"Alice Smith", "Bob Jones"}) {
+				t.Errorf("authors = %q", names)
+			}
+			if e.PrimaryCategory.Term != "cs.LG" || len(e.Categories) != 2 || e.Categories[1].Term != "stat.ML" {
+				t.Errorf("categories = %v, primary %v", e.Categories, e.PrimaryCategory)
+			}
+			if e.AbstractUrl != "https://arxiv.org/abs/2404.08001" || e.PDFUrl != "http://arxiv.org/pdf/2404.08001v1" {
+				t.Errorf("URLs = %q %q", e.AbstractUrl, e.PDFUrl)
+			}
+		})
+	}
+}
+
+func TestParseFeedAnnounceTypes(t *testing.T) {
+	f, err := os.Open("test_data/feed.rss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	feed, err := ParseFeed(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []AnnounceType
+	for _, item := range feed.Items {
+		types = append(types, item.AnnounceType)
+	}
+	// The last item has no announce_type element, so its type comes from the description.
+	want := []AnnounceType{AnnounceNew, AnnounceCrossList, AnnounceReplaceCross}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("announce types = %v, want %v", types, want)
+	}
+
+	if _, err := ParseFeed(strings.NewReader("<html><body>Not found</body></html>")); !errors.Is(err, ErrMalformedResponse) {
+		t.Errorf("ParseFeed of HTML error = %v, want ErrMalformedResponse", err)
+	}
+}
+
+func TestClientFeed(t *testing.T) {
+	data, err := os.ReadFile("test_data/feed.rss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	failures := 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithFeedBaseURL(server.URL),
+		WithRateLimit(0),
+		WithRetry(RetryConfig{MaxAttempts: 2, InitialInterval: time.Millisecond}),
+	)
+	feed, err := client.Feed(context.Background(), "cs.LG", "stat.ML")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feed.Items) != 3 {
+		t.Errorf("got %d items, want 3", len(feed.Items))
+	}
+	if len(paths) != 2 || paths[1] != "/rss/cs.LG+stat.ML" {
+		t.Errorf("requested %v, want a retry of /rss/cs.LG+stat.ML", paths)
+	}
+}
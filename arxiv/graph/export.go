@@ -0,0 +1,235 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateFormat is the form of the first and last dates in exports.
+const dateFormat = "2006-01-02"
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(dateFormat)
+}
+
+// GraphML, as read by Gephi, Cytoscape, yEd and NetworkX.
+
+type graphML struct {
+	XMLName xml.Name     `xml:"http://graphml.graphdrawing.org/xmlns graphml"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// dataOf returns the data of a node or edge, leaving out empty values.
+func dataOf(pairs ...string) []graphMLData {
+	var data []graphMLData
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			data = append(data, graphMLData{Key: pairs[i], Value: pairs[i+1]})
+		}
+	}
+	return data
+}
+
+// WriteGraphML writes the graph as GraphML. Nodes have label, kind,
+// weight, first and last attributes, and edges weight, first and last.
+func (g *Graph) WriteGraphML(w io.Writer) error {
+	doc := graphML{
+		Keys: []graphMLKey{
+			{ID: "label", For: "node", Name: "label", Type: "string"},
+			{ID: "kind", For: "node", Name: "kind", Type: "string"},
+			{ID: "weight", For: "all", Name: "weight", Type: "int"},
+			{ID: "first", For: "all", Name: "first", Type: "string"},
+			{ID: "last", For: "all", Name: "last", Type: "string"},
+		},
+		Graph: graphMLGraph{ID: g.Name, EdgeDefault: "undirected"},
+	}
+	for _, n := range g.Nodes() {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: n.ID,
+			Data: dataOf("label", n.Label, "kind", n.Kind, "weight", strconv.Itoa(n.Weight),
+				"first", formatDate(n.First), "last", formatDate(n.Last)),
+		})
+	}
+	for _, e := range g.Edges() {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: e.Source,
+			Target: e.Target,
+			Data: dataOf("weight", strconv.Itoa(e.Weight),
+				"first", formatDate(e.First), "last", formatDate(e.Last)),
+		})
+	}
+	return writeXML(w, doc)
+}
+
+// GEXF 1.2, as read by Gephi.
+
+type gexf struct {
+	XMLName xml.Name  `xml:"http://gexf.net/1.2 gexf"`
+	Version string    `xml:"version,attr"`
+	Meta    gexfMeta  `xml:"meta"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+type gexfMeta struct {
+	Creator     string `xml:"creator"`
+	Description string `xml:"description,omitempty"`
+}
+
+type gexfGraph struct {
+	DefaultEdgeType string         `xml:"defaultedgetype,attr"`
+	Mode            string         `xml:"mode,attr"`
+	TimeFormat      string         `xml:"timeformat,attr,omitempty"`
+	Attributes      gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode     `xml:"nodes>node"`
+	Edges           []gexfEdge     `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfNode struct {
+	ID        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	Start     string         `xml:"start,attr,omitempty"`
+	End       string         `xml:"end,attr,omitempty"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfEdge struct {
+	ID     string `xml:"id,attr"`
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+	Weight int    `xml:"weight,attr"`
+	Start  string `xml:"start,attr,omitempty"`
+	End    string `xml:"end,attr,omitempty"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// WriteGEXF writes the graph as GEXF. Nodes and edges span the dates of
+// their first and last papers, so that Gephi's timeline can replay the
+// growth of the network; nodes have kind and weight attributes.
+func (g *Graph) WriteGEXF(w io.Writer) error {
+	doc := gexf{
+		Version: "1.2",
+		Meta:    gexfMeta{Creator: "github.com/Epistemic-Technology/arxiv", Description: g.Name},
+		Graph: gexfGraph{
+			DefaultEdgeType: "undirected",
+			Mode:            "static",
+			Attributes: gexfAttributes{Class: "node", Attributes: []gexfAttribute{
+				{ID: "kind", Title: "kind", Type: "string"},
+				{ID: "weight", Title: "weight", Type: "integer"},
+			}},
+		},
+	}
+	for _, n := range g.Nodes() {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, gexfNode{
+			ID:    n.ID,
+			Label: n.Label,
+			Start: formatDate(n.First),
+			End:   formatDate(n.Last),
+			AttValues: []gexfAttValue{
+				{For: "kind", Value: n.Kind},
+				{For: "weight", Value: strconv.Itoa(n.Weight)},
+			},
+		})
+		if !n.First.IsZero() {
+			doc.Graph.Mode, doc.Graph.TimeFormat = "dynamic", "date"
+		}
+	}
+	for i, e := range g.Edges() {
+		doc.Graph.Edges = append(doc.Graph.Edges, gexfEdge{
+			ID:     strconv.Itoa(i),
+			Source: e.Source,
+			Target: e.Target,
+			Weight: e.Weight,
+			Start:  formatDate(e.First),
+			End:    formatDate(e.Last),
+		})
+	}
+	return writeXML(w, doc)
+}
+
+func writeXML(w io.Writer, doc any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// WriteDOT writes the graph in the DOT language of Graphviz. Edges have a
+// weight attribute and a pen width growing with it.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "graph %s {\n", dotQuote(g.Name))
+	for _, n := range g.Nodes() {
+		fmt.Fprintf(bw, "  %s [label=%s, kind=%s, weight=%d];\n", dotQuote(n.ID), dotQuote(n.Label), dotQuote(n.Kind), n.Weight)
+	}
+	for _, e := range g.Edges() {
+		fmt.Fprintf(bw, "  %s -- %s [weight=%d, penwidth=%d];\n", dotQuote(e.Source), dotQuote(e.Target), e.Weight, min(e.Weight, 10))
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ", "\r", " ")
+
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
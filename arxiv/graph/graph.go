@@ -0,0 +1,435 @@
+// Package graph builds networks from arXiv metadata: co-authorship between
+// authors, authorship between authors and categories, and co-occurrence
+// between categories listed on the same paper.
+//
+// Edges are weighted by the number of papers behind them, and nodes and
+// edges record when they first and last appeared. Graphs can be written as
+// GraphML, GEXF or DOT for tools such as Gephi, Cytoscape or Graphviz:
+//
+//	var entries []arxiv.EntryMetadata
+//	for entry := range client.SearchIter(ctx, arxiv.SearchParams{Query: "cat:cs.LG", MaxResults: 100}) {
+//		entries = append(entries, entry)
+//	}
+//	g := graph.CoAuthors(entries, graph.WithMaxAuthors(50))
+//	fmt.Println(len(g.Nodes()), "authors in", len(g.Components()), "components")
+//	err := g.WriteGraphML(os.Stdout)
+//
+// To follow a network over time, split the entries with SliceByPublished
+// and build a graph from each slice, or build one graph with Between.
+package graph
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// Kinds of node.
+const (
+	KindAuthor   = "author"
+	KindCategory = "category"
+)
+
+// Node is an author or a category.
+type Node struct {
+	ID     string    // Key of the author or category term
+	Label  string    // Author's name as first parsed, or the category term
+	Kind   string    // KindAuthor or KindCategory
+	Weight int       // Number of papers
+	First  time.Time // Publication time of the earliest paper
+	Last   time.Time // Publication time of the latest paper
+}
+
+// Edge is an undirected link between two nodes. In graphs of authors and
+// categories, the source is the author.
+type Edge struct {
+	Source string
+	Target string
+	Weight int       // Number of papers
+	First  time.Time // Publication time of the earliest paper
+	Last   time.Time // Publication time of the latest paper
+}
+
+// Graph is an undirected weighted graph.
+type Graph struct {
+	Name string // Name of the graph, used by the exporters
+
+	nodes map[string]*Node
+	edges map[[2]string]*Edge
+	adj   map[string]map[string]*Edge
+}
+
+func newGraph(name string) *Graph {
+	return &Graph{
+		Name:  name,
+		nodes: map[string]*Node{},
+		edges: map[[2]string]*Edge{},
+		adj:   map[string]map[string]*Edge{},
+	}
+}
+
+// config holds the options of the builders.
+type config struct {
+	start, end time.Time
+	maxAuthors int
+	authorKey  func(arxiv.Author) string
+}
+
+// Option configures how a graph is built.
+type Option func(*config)
+
+// Between keeps only the entries published at or after start and before
+// end. A zero time leaves that side open.
+func Between(start, end time.Time) Option {
+	return func(c *config) {
+		c.start, c.end = start, end
+	}
+}
+
+// WithMaxAuthors skips the co-author edges of papers with more than n
+// authors, such as those of large collaborations, which would otherwise
+// link every pair of their authors. The authors are still nodes.
+func WithMaxAuthors(n int) Option {
+	return func(c *config) {
+		c.maxAuthors = n
+	}
+}
+
+// WithAuthorKey sets the function that tells which authors are the same
+// node. The default is the normalized full name, so that "Hinton, Geoffrey"
+// and "Geoffrey Hinton" are one node; arxiv.PersonName.Key, which keeps
+// only the first initial, joins more spellings at the risk of joining
+// different people:
+//
+//	graph.WithAuthorKey(func(a arxiv.Author) string { return a.ParsedName().Key() })
+func WithAuthorKey(key func(arxiv.Author) string) Option {
+	return func(c *config) {
+		c.authorKey = key
+	}
+}
+
+func newConfig(options []Option) config {
+	c := config{authorKey: defaultAuthorKey}
+	for _, option := range options {
+		option(&c)
+	}
+	return c
+}
+
+func defaultAuthorKey(a arxiv.Author) string {
+	return arxiv.NormalizeName(a.ParsedName().String())
+}
+
+// entries returns the entries the options keep, with only the latest
+// version of each.
+func (c config) entries(entries []arxiv.EntryMetadata) []arxiv.EntryMetadata {
+	var kept []arxiv.EntryMetadata
+	for _, e := range entries {
+		if !c.start.IsZero() && e.Published.Before(c.start) || !c.end.IsZero() && !e.Published.Before(c.end) {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return arxiv.Dedup(kept)
+}
+
+// authors returns the distinct authors of an entry as nodes.
+func (c config) authors(e arxiv.EntryMetadata) []Node {
+	var nodes []Node
+	for _, a := range e.Authors {
+		key := c.authorKey(a)
+		if key == "" || slices.ContainsFunc(nodes, func(n Node) bool { return n.ID == key }) {
+			continue
+		}
+		label := a.ParsedName().String()
+		if label == "" {
+			label = a.Name
+		}
+		nodes = append(nodes, Node{ID: key, Label: label, Kind: KindAuthor})
+	}
+	return nodes
+}
+
+// categories returns the distinct categories of an entry as nodes, the
+// primary category first.
+func categories(e arxiv.EntryMetadata) []Node {
+	var nodes []Node
+	for _, c := range append([]arxiv.Category{e.PrimaryCategory}, e.Categories...) {
+		if c.Term == "" || slices.ContainsFunc(nodes, func(n Node) bool { return n.ID == c.Term }) {
+			continue
+		}
+		nodes = append(nodes, Node{ID: c.Term, Label: c.Term, Kind: KindCategory})
+	}
+	return nodes
+}
+
+// CoAuthors returns the co-authorship network of entries: a node for each
+// author, and an edge between two authors for the papers they wrote
+// together.
+func CoAuthors(entries []arxiv.EntryMetadata, options ...Option) *Graph {
+	c := newConfig(options)
+	g := newGraph("co-authors")
+	for _, e := range c.entries(entries) {
+		authors := c.authors(e)
+		for _, a := range authors {
+			g.addNode(a, e.Published)
+		}
+		if c.maxAuthors > 0 && len(authors) > c.maxAuthors {
+			continue
+		}
+		g.addPairs(authors, e.Published)
+	}
+	return g
+}
+
+// AuthorCategories returns the bipartite network of authors and the
+// categories they publish in, with an edge for the papers of an author
+// listed in a category.
+func AuthorCategories(entries []arxiv.EntryMetadata, options ...Option) *Graph {
+	c := newConfig(options)
+	g := newGraph("author-categories")
+	for _, e := range c.entries(entries) {
+		cats := categories(e)
+		for _, cat := range cats {
+			g.addNode(cat, e.Published)
+		}
+		for _, a := range c.authors(e) {
+			g.addNode(a, e.Published)
+			for _, cat := range cats {
+				g.addEdge(a.ID, cat.ID, e.Published)
+			}
+		}
+	}
+	return g
+}
+
+// Categories returns the co-occurrence network of the categories of
+// entries, with an edge between two categories for the papers listed in
+// both.
+func Categories(entries []arxiv.EntryMetadata, options ...Option) *Graph {
+	c := newConfig(options)
+	g := newGraph("categories")
+	for _, e := range c.entries(entries) {
+		cats := categories(e)
+		for _, cat := range cats {
+			g.addNode(cat, e.Published)
+		}
+		g.addPairs(cats, e.Published)
+	}
+	return g
+}
+
+// addNode adds a paper published at t to a node, creating it if needed.
+func (g *Graph) addNode(n Node, t time.Time) {
+	node, ok := g.nodes[n.ID]
+	if !ok {
+		node = &Node{ID: n.ID, Label: n.Label, Kind: n.Kind, First: t, Last: t}
+		g.nodes[n.ID] = node
+	}
+	node.Weight++
+	node.First, node.Last = earliest(node.First, t), latest(node.Last, t)
+}
+
+// addPairs adds a paper published at t to the edge between every pair of
+// nodes, ordering each pair by ID.
+func (g *Graph) addPairs(nodes []Node, t time.Time) {
+	for i, a := range nodes {
+		for _, b := range nodes[i+1:] {
+			source, target := a.ID, b.ID
+			if target < source {
+				source, target = target, source
+			}
+			g.addEdge(source, target, t)
+		}
+	}
+}
+
+// addEdge adds a paper published at t to the edge from source to target,
+// creating it if needed.
+func (g *Graph) addEdge(source, target string, t time.Time) {
+	key := [2]string{source, target}
+	edge, ok := g.edges[key]
+	if !ok {
+		edge = &Edge{Source: source, Target: target, First: t, Last: t}
+		g.edges[key] = edge
+		for _, pair := range [][2]string{{source, target}, {target, source}} {
+			if g.adj[pair[0]] == nil {
+				g.adj[pair[0]] = map[string]*Edge{}
+			}
+			g.adj[pair[0]][pair[1]] = edge
+		}
+	}
+	edge.Weight++
+	edge.First, edge.Last = earliest(edge.First, t), latest(edge.Last, t)
+}
+
+func earliest(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// Nodes returns the nodes of the graph, ordered by ID.
+func (g *Graph) Nodes() []Node {
+	nodes := make([]Node, 0, len(g.nodes))
+	for _, n := range g.nodes {
+		nodes = append(nodes, *n)
+	}
+	slices.SortFunc(nodes, func(a, b Node) int { return cmp.Compare(a.ID, b.ID) })
+	return nodes
+}
+
+// Edges returns the edges of the graph, ordered by source and target.
+func (g *Graph) Edges() []Edge {
+	edges := make([]Edge, 0, len(g.edges))
+	for _, e := range g.edges {
+		edges = append(edges, *e)
+	}
+	slices.SortFunc(edges, func(a, b Edge) int {
+		return cmp.Or(cmp.Compare(a.Source, b.Source), cmp.Compare(a.Target, b.Target))
+	})
+	return edges
+}
+
+// Node returns the node with the given ID.
+func (g *Graph) Node(id string) (Node, bool) {
+	n, ok := g.nodes[id]
+	if !ok {
+		return Node{}, false
+	}
+	return *n, true
+}
+
+// Edge returns the edge between two nodes, in either order.
+func (g *Graph) Edge(a, b string) (Edge, bool) {
+	e, ok := g.adj[a][b]
+	if !ok {
+		return Edge{}, false
+	}
+	return *e, true
+}
+
+// Neighbors returns the IDs of the nodes linked to a node, ordered by ID.
+func (g *Graph) Neighbors(id string) []string {
+	var ids []string
+	for other := range g.adj[id] {
+		ids = append(ids, other)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Degree returns the number of edges of a node.
+func (g *Graph) Degree(id string) int {
+	return len(g.adj[id])
+}
+
+// WeightedDegree returns the sum of the weights of the edges of a node.
+func (g *Graph) WeightedDegree(id string) int {
+	total := 0
+	for _, e := range g.adj[id] {
+		total += e.Weight
+	}
+	return total
+}
+
+// Density returns the number of edges over the number of pairs of nodes.
+func (g *Graph) Density() float64 {
+	n := float64(len(g.nodes))
+	if n < 2 {
+		return 0
+	}
+	return float64(len(g.edges)) / (n * (n - 1) / 2)
+}
+
+// Ranked returns the IDs of the nodes of a kind, or of all nodes if kind
+// is empty, by weighted degree, highest first, then by ID.
+func (g *Graph) Ranked(kind string) []string {
+	var ids []string
+	for id, n := range g.nodes {
+		if kind == "" || n.Kind == kind {
+			ids = append(ids, id)
+		}
+	}
+	slices.SortFunc(ids, func(a, b string) int {
+		return cmp.Or(cmp.Compare(g.WeightedDegree(b), g.WeightedDegree(a)), cmp.Compare(a, b))
+	})
+	return ids
+}
+
+// Components returns the connected components of the graph as lists of
+// node IDs, ordered by ID, the largest component first.
+func (g *Graph) Components() [][]string {
+	seen := map[string]bool{}
+	var components [][]string
+	for _, n := range g.Nodes() {
+		if seen[n.ID] {
+			continue
+		}
+		seen[n.ID] = true
+		component := []string{n.ID}
+		for i := 0; i < len(component); i++ {
+			for other := range g.adj[component[i]] {
+				if !seen[other] {
+					seen[other] = true
+					component = append(component, other)
+				}
+			}
+		}
+		slices.Sort(component)
+		components = append(components, component)
+	}
+	slices.SortStableFunc(components, func(a, b []string) int { return cmp.Compare(len(b), len(a)) })
+	return components
+}
+
+// Period is a length of time that entries are sliced by.
+type Period = arxiv.Period
+
+// Periods for SliceByPublished.
+const (
+	Day     = arxiv.Day
+	Week    = arxiv.Week
+	Month   = arxiv.Month
+	Quarter = arxiv.Quarter
+	Year    = arxiv.Year
+)
+
+// Slice is the entries published in one period.
+type Slice struct {
+	Start   time.Time // Start of the period
+	End     time.Time // Start of the next period
+	Entries []arxiv.EntryMetadata
+}
+
+// SliceByPublished splits entries by the period they were published in,
+// in order of time. Periods with no entries between the first and the last
+// are included, so that slices are evenly spaced.
+func SliceByPublished(entries []arxiv.EntryMetadata, period Period) []Slice {
+	if len(entries) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(entries)
+	slices.SortStableFunc(sorted, func(a, b arxiv.EntryMetadata) int { return a.Published.Compare(b.Published) })
+
+	var out []Slice
+	for start := period.Start(sorted[0].Published); len(sorted) > 0; start = period.Next(start) {
+		s := Slice{Start: start, End: period.Next(start)}
+		for len(sorted) > 0 && sorted[0].Published.Before(s.End) {
+			s.Entries = append(s.Entries, sorted[0])
+			sorted = sorted[1:]
+		}
+		out = append(out, s)
+	}
+	return out
+}
@@ -0,0 +1,230 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/xml"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+func entry(id string, published time.Time, authors []string, categories ...string) arxiv.EntryMetadata {
+	e := arxiv.EntryMetadata{
+		ID:              "http://arxiv.org/abs/" + id,
+		Published:       published,
+		Updated:         published,
+		PrimaryCategory: arxiv.Category{Term: categories[0]},
+	}
+	for _, a := range authors {
+		e.Authors = append(e.Authors, arxiv.Author{Name: a})
+	}
+	for _, c := range categories {
+		e.Categories = append(e.Categories, arxiv.Category{Term: c})
+	}
+	return e
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+}
+
+func corpus() []arxiv.EntryMetadata {
+	entries := []arxiv.EntryMetadata{
+		entry("2401.00001v1", date(2024, 1, 5), []string{"Ada Lovelace", "Alan Turing"}, "cs.LG", "cs.AI"),
+		entry("2401.00002v1", date(2024, 1, 20), []string{"Lovelace, Ada", "Alan Turing", "Grace Hopper"}, "cs.LG"),
+		entry("2402.00003v1", date(2024, 2, 10), []string{"Grace Hopper"}, "cs.PL", "cs.LG"),
+		entry("2403.00004v1", date(2024, 3, 2), []string{`Kurt G\"odel`, "Emmy Noether"}, "math.LO"),
+		// A later version replaces the first.
+		entry("2401.00002v2", date(2024, 1, 20), []string{"Ada Lovelace", "Alan Turing", "Grace Hopper"}, "cs.LG", "stat.ML"),
+	}
+	entries[4].Updated = date(2024, 4, 1)
+	return entries
+}
+
+func TestCoAuthors(t *testing.T) {
+	g := CoAuthors(corpus())
+
+	var ids []string
+	for _, n := range g.Nodes() {
+		ids = append(ids, n.ID)
+	}
+	if want := []string{"ada lovelace", "alan turing", "emmy noether", "grace hopper", "kurt godel"}; !slices.Equal(ids, want) {
+		t.Fatalf("nodes = %q, want %q", ids, want)
+	}
+	ada, _ := g.Node("ada lovelace")
+	if ada.Label != "Ada Lovelace" || ada.Kind != KindAuthor || ada.Weight != 2 ||
+		!ada.First.Equal(date(2024, 1, 5)) || !ada.Last.Equal(date(2024, 1, 20)) {
+		t.Errorf("node = %+v", ada)
+	}
+
+	edge, ok := g.Edge("alan turing", "ada lovelace")
+	if !ok || edge.Source != "ada lovelace" || edge.Target != "alan turing" || edge.Weight != 2 {
+		t.Errorf("edge = %+v, %v", edge, ok)
+	}
+	if len(g.Edges()) != 4 {
+		t.Errorf("got %d edges, want 4: %+v", len(g.Edges()), g.Edges())
+	}
+
+	if got := g.Degree("ada lovelace"); got != 2 {
+		t.Errorf("Degree = %d, want 2", got)
+	}
+	if got := g.WeightedDegree("ada lovelace"); got != 3 {
+		t.Errorf("WeightedDegree = %d, want 3", got)
+	}
+	if got, want := g.Neighbors("grace hopper"), []string{"ada lovelace", "alan turing"}; !slices.Equal(got, want) {
+		t.Errorf("Neighbors = %q, want %q", got, want)
+	}
+	if got, want := g.Ranked(KindAuthor)[:2], []string{"ada lovelace", "alan turing"}; !slices.Equal(got, want) {
+		t.Errorf("Ranked = %q, want %q", got, want)
+	}
+	if got, want := g.Density(), 4.0/10; got != want {
+		t.Errorf("Density = %v, want %v", got, want)
+	}
+
+	components := g.Components()
+	want := [][]string{{"ada lovelace", "alan turing", "grace hopper"}, {"emmy noether", "kurt godel"}}
+	if !slices.EqualFunc(components, want, slices.Equal) {
+		t.Errorf("Components = %q, want %q", components, want)
+	}
+}
+
+func TestCoAuthorsOptions(t *testing.T) {
+	g := CoAuthors(corpus(), WithMaxAuthors(2))
+	if _, ok := g.Edge("grace hopper", "alan turing"); ok {
+		t.Error("got an edge from a paper with too many authors")
+	}
+	if _, ok := g.Node("grace hopper"); !ok {
+		t.Error("authors of a paper with too many authors are missing")
+	}
+
+	g = CoAuthors(corpus(), Between(date(2024, 2, 1), date(2024, 3, 2)))
+	if got := len(g.Nodes()); got != 1 {
+		t.Errorf("got %d nodes between dates, want 1", got)
+	}
+
+	g = CoAuthors(corpus(), WithAuthorKey(func(a arxiv.Author) string { return a.ParsedName().Key() }))
+	if _, ok := g.Node("lovelace_a"); !ok {
+		t.Errorf("nodes = %+v", g.Nodes())
+	}
+}
+
+func TestAuthorCategories(t *testing.T) {
+	g := AuthorCategories(corpus())
+	if n, _ := g.Node("cs.LG"); n.Kind != KindCategory || n.Weight != 3 {
+		t.Errorf("node = %+v", n)
+	}
+	if e, ok := g.Edge("grace hopper", "cs.LG"); !ok || e.Source != "grace hopper" || e.Weight != 2 {
+		t.Errorf("edge = %+v, %v", e, ok)
+	}
+	if got, want := g.Neighbors("grace hopper"), []string{"cs.LG", "cs.PL", "stat.ML"}; !slices.Equal(got, want) {
+		t.Errorf("Neighbors = %q, want %q", got, want)
+	}
+	if got, want := g.Ranked(KindCategory)[0], "cs.LG"; got != want {
+		t.Errorf("Ranked = %q, want %q first", g.Ranked(KindCategory), want)
+	}
+}
+
+func TestCategories(t *testing.T) {
+	g := Categories(corpus())
+	var edges []string
+	for _, e := range g.Edges() {
+		edges = append(edges, e.Source+"-"+e.Target)
+	}
+	if want := []string{"cs.AI-cs.LG", "cs.LG-cs.PL", "cs.LG-stat.ML"}; !slices.Equal(edges, want) {
+		t.Errorf("edges = %q, want %q", edges, want)
+	}
+	if got := len(g.Components()); got != 2 {
+		t.Errorf("got %d components, want 2", got)
+	}
+}
+
+func TestSliceByPublished(t *testing.T) {
+	periods := SliceByPublished(corpus(), Month)
+	var got []string
+	for _, s := range periods {
+		got = append(got, s.Start.Format("2006-01")+":"+strings.Repeat("x", len(s.Entries)))
+	}
+	if want := []string{"2024-01:xxx", "2024-02:x", "2024-03:x"}; !slices.Equal(got, want) {
+		t.Errorf("slices = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		period Period
+		t      time.Time
+		start  time.Time
+	}{
+		{Day, date(2024, 5, 15), time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)},
+		{Week, date(2024, 5, 15), time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)},
+		{Week, date(2024, 5, 19), time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC)},
+		{Quarter, date(2024, 5, 15), time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{Year, date(2024, 5, 15), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := tt.period.Start(tt.t); !got.Equal(tt.start) {
+			t.Errorf("Start(%v, %v) = %v, want %v", tt.period, tt.t, got, tt.start)
+		}
+	}
+
+	if got := SliceByPublished(nil, Year); got != nil {
+		t.Errorf("SliceByPublished(nil) = %v", got)
+	}
+	gap := []arxiv.EntryMetadata{corpus()[0], corpus()[3]}
+	if got := SliceByPublished(gap, Month); len(got) != 3 || len(got[1].Entries) != 0 {
+		t.Errorf("slices = %+v, want an empty one between", got)
+	}
+}
+
+func TestExports(t *testing.T) {
+	g := CoAuthors(corpus())
+	g.Name = `co-authors "test"`
+
+	var buf bytes.Buffer
+	if err := g.WriteGraphML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var graphml graphML
+	if err := xml.Unmarshal(buf.Bytes(), &graphml); err != nil {
+		t.Fatalf("GraphML does not parse: %v\n%s", err, buf.String())
+	}
+	if len(graphml.Graph.Nodes) != 5 || len(graphml.Graph.Edges) != 4 || graphml.Graph.EdgeDefault != "undirected" {
+		t.Errorf("GraphML = %+v", graphml.Graph)
+	}
+	for _, want := range []string{`<data key="label">Kurt Gödel</data>`, `<data key="first">2024-01-05</data>`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("GraphML lacks %s:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := g.WriteGEXF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var doc gexf
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("GEXF does not parse: %v\n%s", err, buf.String())
+	}
+	if len(doc.Graph.Nodes) != 5 || len(doc.Graph.Edges) != 4 || doc.Graph.Mode != "dynamic" {
+		t.Errorf("GEXF = %+v", doc.Graph)
+	}
+	if e := doc.Graph.Edges[0]; e.Source != "ada lovelace" || e.Target != "alan turing" || e.Weight != 2 || e.Start != "2024-01-05" || e.End != "2024-01-20" {
+		t.Errorf("GEXF edge = %+v", e)
+	}
+
+	buf.Reset()
+	if err := g.WriteDOT(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+	for _, want := range []string{
+		`graph "co-authors \"test\"" {`,
+		`"kurt godel" [label="Kurt Gödel", kind="author", weight=1];`,
+		`"ada lovelace" -- "alan turing" [weight=2, penwidth=2];`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT lacks %s:\n%s", want, dot)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package arxiv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+)
+
+// maxStart is the largest Start offset the arXiv API accepts.
+const maxStart = 30000
+
+// defaultPageSize is the page size arXiv uses when MaxResults is not set.
+const defaultPageSize = 10
+
+// ErrBudgetExceeded is matched by the error a Harvest reports when one of its
+// budgets stopped it before the end of the results.
+var ErrBudgetExceeded = errors.New("arxiv: harvest budget exceeded")
+
+// HarvestOptions limits how much work a harvest may do. Zero values mean no limit.
+type HarvestOptions struct {
+	MaxPages    int           // Maximum number of pages to request
+	MaxEntries  int           // Maximum number of entries to yield
+	MaxDuration time.Duration // Maximum wall time, measured from the start of iteration
+}
+
+// BudgetError reports which budget stopped a harvest early and how far it got.
+// It matches ErrBudgetExceeded with errors.Is.
+type BudgetError struct {
+	Budget  string        // "pages", "entries" or "duration"
+	Pages   int           // Pages fetched before stopping
+	Entries int           // Entries yielded before stopping
+	Elapsed time.Duration // Time spent before stopping
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("arxiv: harvest stopped by %s budget after %d pages, %d entries and %v", e.Budget, e.Pages, e.Entries, e.Elapsed.Round(time.Millisecond))
+}
+
+func (e *BudgetError) Is(target error) bool {
+	return target == ErrBudgetExceeded
+}
+
+// HarvestEstimate previews the work a harvest will do.
+type HarvestEstimate struct {
+	TotalResults int           // Total results reported by arXiv for the query
+	PageSize     int           // Entries per page
+	Pages        int           // Pages the harvest is expected to request
+	Entries      int           // Entries the harvest is expected to yield
+	Duration     time.Duration // Minimum time the harvest will take at the client's rate limit
+	Truncated    bool          // Whether budgets or the API's start limit will stop the harvest before the end of the results
+}
+
+// HarvestStats reports the progress of a harvest.
+type HarvestStats struct {
+	Pages        int           // Pages fetched so far
+	Entries      int           // Entries yielded so far
+	TotalResults int           // Total results reported by arXiv, once known
+	Elapsed      time.Duration // Time spent iterating
+	Complete     bool          // Whether the harvest reached the end of the results
+}
+
+// Harvest pages through all results of a search within a set of budgets.
+// Unlike SearchIter, it reports why it stopped: call Err after iterating to
+// tell the end of the results from an error or an exhausted budget.
+type Harvest struct {
+	client  *Client
+	params  SearchParams
+	opts    HarvestOptions
+	first   *SearchResults
+	started time.Time
+	stats   HarvestStats
+	err     error
+}
+
+// Harvest creates a harvest of the results of params limited by opts. No
+// requests are made until Estimate or Entries is called.
+func (c *Client) Harvest(params SearchParams, opts HarvestOptions) *Harvest {
+	return &Harvest{client: c, params: params, opts: opts}
+}
+
+// Estimate fetches the first page of results and predicts how many pages,
+// entries and how much time the harvest will take. The page is kept and
+// reused when iteration starts, so the estimate costs no extra request.
+func (h *Harvest) Estimate(ctx context.Context) (HarvestEstimate, error) {
+	first, err := h.firstPage(ctx)
+	if err != nil {
+		return HarvestEstimate{}, err
+	}
+
+	pageSize := first.ItemsPerPage
+	if pageSize <= 0 {
+		pageSize = h.params.MaxResults
+	}
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
+	estimate := HarvestEstimate{TotalResults: first.TotalResults, PageSize: pageSize}
+	available := max(first.TotalResults-h.params.Start, 0)
+	entries := available
+	if reachable := maxStart + pageSize - h.params.Start; entries > reachable {
+		entries = reachable
+	}
+	if h.opts.MaxEntries > 0 && entries > h.opts.MaxEntries {
+		entries = h.opts.MaxEntries
+	}
+	pages := (entries + pageSize - 1) / pageSize
+	if h.opts.MaxPages > 0 && pages > h.opts.MaxPages {
+		pages = h.opts.MaxPages
+		entries = pages * pageSize
+	}
+	if pages == 0 {
+		pages = 1
+	}
+
+	duration := time.Duration(pages-1) * h.client.requestInterval()
+	if h.opts.MaxDuration > 0 && duration > h.opts.MaxDuration {
+		interval := h.client.requestInterval()
+		pages = int(h.opts.MaxDuration/interval) + 1
+		entries = min(entries, pages*pageSize)
+		duration = time.Duration(pages-1) * interval
+	}
+
+	estimate.Pages = pages
+	estimate.Entries = entries
+	estimate.Duration = duration
+	estimate.Truncated = entries < available
+	return estimate, nil
+}
+
+// Entries returns an iterator over the harvested entries. Iteration stops at
+// the end of the results, on an error, or when a budget is exhausted; Err
+// reports which.
+func (h *Harvest) Entries(ctx context.Context) iter.Seq[EntryMetadata] {
+	return func(yield func(EntryMetadata) bool) {
+		h.started = time.Now()
+		if h.opts.MaxDuration > 0 {
+			var cancel context.CancelFunc
+			parent := ctx
+			ctx, cancel = context.WithTimeout(ctx, h.opts.MaxDuration)
+			defer cancel()
+			defer func() {
+				// A request cut short by the harvest deadline is a budget
+				// stop, not a failure.
+				if h.err != nil && parent.Err() == nil && ctx.Err() != nil {
+					h.err = h.budgetError("duration")
+				}
+			}()
+		}
+		defer func() { h.stats.Elapsed = time.Since(h.started) }()
+
+		params := h.params
+		for {
+			if h.opts.MaxPages > 0 && h.stats.Pages >= h.opts.MaxPages {
+				h.err = h.budgetError("pages")
+				return
+			}
+			if h.opts.MaxEntries > 0 && h.stats.Entries >= h.opts.MaxEntries {
+				h.err = h.budgetError("entries")
+				return
+			}
+			if h.opts.MaxDuration > 0 && time.Since(h.started) >= h.opts.MaxDuration {
+				h.err = h.budgetError("duration")
+				return
+			}
+			if h.opts.MaxEntries > 0 && params.MaxResults > 0 {
+				params.MaxResults = min(params.MaxResults, h.opts.MaxEntries-h.stats.Entries)
+			}
+
+			response, err := h.page(ctx, params)
+			if err != nil {
+				h.err = err
+				return
+			}
+			h.stats.Pages++
+			h.stats.TotalResults = response.TotalResults
+			// Advance by the entries received if the page size is missing, and
+			// stop rather than request the same page again.
+			next := response.StartIndex + max(response.ItemsPerPage, len(response.Entries))
+			more := response.TotalResults > 0 && next < response.TotalResults && next > params.Start
+
+			for _, entry := range response.Entries {
+				if h.opts.MaxEntries > 0 && h.stats.Entries >= h.opts.MaxEntries {
+					h.err = h.budgetError("entries")
+					return
+				}
+				h.stats.Entries++
+				if !yield(entry) {
+					return
+				}
+			}
+			if !more {
+				h.stats.Complete = true
+				return
+			}
+			params.Start = next
+		}
+	}
+}
+
+// Err returns the reason iteration stopped early, or nil if the harvest
+// reached the end of the results or the caller stopped iterating. When a
+// budget stopped the harvest, the error is a *BudgetError.
+func (h *Harvest) Err() error {
+	return h.err
+}
+
+// Stats returns the progress of the harvest.
+func (h *Harvest) Stats() HarvestStats {
+	return h.stats
+}
+
+// firstPage fetches and caches the first page of results.
+func (h *Harvest) firstPage(ctx context.Context) (SearchResults, error) {
+	if h.first != nil {
+		return *h.first, nil
+	}
+	response, err := h.client.Search(ctx, h.params)
+	if err != nil {
+		return SearchResults{}, err
+	}
+	h.first = &response
+	return response, nil
+}
+
+// page fetches a page of results, reusing the page fetched by Estimate.
+func (h *Harvest) page(ctx context.Context, params SearchParams) (SearchResults, error) {
+	if h.first != nil && params.Start == h.params.Start {
+		return *h.first, nil
+	}
+	return h.client.Search(ctx, params)
+}
+
+func (h *Harvest) budgetError(budget string) *BudgetError {
+	return &BudgetError{
+		Budget:  budget,
+		Pages:   h.stats.Pages,
+		Entries: h.stats.Entries,
+		Elapsed: time.Since(h.started),
+	}
+}
+
+// requestInterval returns the minimum time between requests made by the
+// client, as far as it can be determined from its limiter.
+func (c *Client) requestInterval() time.Duration {
+	if limiter, ok := c.limiter.(interface{ Interval() time.Duration }); ok {
+		return limiter.Interval()
+	}
+	if c.limiter == nil {
+		return 0
+	}
+	return c.RateLimit
+}
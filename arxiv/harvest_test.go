@@ -0,0 +1,235 @@
+package arxiv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// pagedServer serves total entries in pages of the requested size and counts
+// the requests it receives.
+func pagedServer(t *testing.T, total int, delay time.Duration) (*httptest.Server, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(delay)
+		start, _ := strconv.Atoi(r.URL.Query().Get("start"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("max_results"))
+		if size == 0 {
+			size = defaultPageSize
+		}
+
+		var b strings.Builder
+		b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:opensearch="http://a9.com/-/spec/opensearch/1.1/">`)
+		fmt.Fprintf(&b, "<opensearch:totalResults>%d</opensearch:totalResults>", total)
+		fmt.Fprintf(&b, "<opensearch:startIndex>%d</opensearch:startIndex>", start)
+		fmt.Fprintf(&b, "<opensearch:itemsPerPage>%d</opensearch:itemsPerPage>", size)
+		for i := start; i < min(start+size, total); i++ {
+			fmt.Fprintf(&b, "<entry><id>http://arxiv.org/abs/2401.%05dv1</id></entry>", i)
+		}
+		b.WriteString("</feed>")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, b.String())
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestHarvestComplete(t *testing.T) {
+	server, requests := pagedServer(t, 25, 0)
+	client := NewClient(WithBaseURL(server.URL), WithRateLimit(0))
+
+	harvest := client.Harvest(SearchParams{Query: "all:test", MaxResults: 10}, HarvestOptions{MaxPages: 5})
+	count := 0
+	for range harvest.Entries(context.Background()) {
+		count++
+	}
+
+	if err := harvest.Err(); err != nil {
+		t.Errorf("Err() = %v; want nil", err)
+	}
+	if count != 25 {
+		t.Errorf("harvested %d entries; want 25", count)
+	}
+	stats := harvest.Stats()
+	if !stats.Complete || stats.Pages != 3 || stats.TotalResults != 25 {
+		t.Errorf("Stats() = %+v; want complete after 3 pages of 25 results", stats)
+	}
+	if got := atomic.LoadInt32(requests); got != 3 {
+		t.Errorf("server saw %d requests; want 3", got)
+	}
+}
+
+func TestHarvestWithoutPageSize(t *testing.T) {
+	// A backend that leaves ItemsPerPage unset, as a WithSearchFunc backend
+	// may, must still advance past each page.
+	var requests int
+	backend := func(ctx context.Context, params SearchParams) (SearchResults, error) {
+		requests++
+		results := SearchResults{TotalResults: 25, StartIndex: params.Start}
+		for i := params.Start; i < min(params.Start+params.MaxResults, 25); i++ {
+			results.Entries = append(results.Entries, EntryMetadata{ID: fmt.Sprintf("http://arxiv.org/abs/2401.%05dv1", i)})
+		}
+		return results, nil
+	}
+	client := NewClient(WithSearchFunc(backend))
+
+	harvest := client.Harvest(SearchParams{Query: "all:test", MaxResults: 10}, HarvestOptions{})
+	seen := map[string]bool{}
+	for entry := range harvest.Entries(context.Background()) {
+		if seen[entry.ID] {
+			t.Fatalf("harvested %s twice", entry.ID)
+		}
+		seen[entry.ID] = true
+	}
+	if err := harvest.Err(); err != nil {
+		t.Errorf("Err() = %v; want nil", err)
+	}
+	if len(seen) != 25 || requests != 3 || !harvest.Stats().Complete {
+		t.Errorf("harvested %d entries in %d requests, stats %+v; want 25 in 3, complete", len(seen), requests, harvest.Stats())
+	}
+
+	// An empty page without a size cannot advance, so the harvest stops.
+	requests = 0
+	client = NewClient(WithSearchFunc(func(ctx context.Context, params SearchParams) (SearchResults, error) {
+		requests++
+		return SearchResults{TotalResults: 25, StartIndex: params.Start}, nil
+	}))
+	harvest = client.Harvest(SearchParams{Query: "all:test", MaxResults: 10}, HarvestOptions{})
+	for range harvest.Entries(context.Background()) {
+	}
+	if requests != 1 {
+		t.Errorf("requested an empty page %d times; want 1", requests)
+	}
+}
+
+func TestHarvestBudgets(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        HarvestOptions
+		delay       time.Duration
+		wantBudget  string
+		wantEntries int
+	}{
+		{
+			name:        "max pages",
+			opts:        HarvestOptions{MaxPages: 2},
+			wantBudget:  "pages",
+			wantEntries: 20,
+		},
+		{
+			name:        "max entries",
+			opts:        HarvestOptions{MaxEntries: 15},
+			wantBudget:  "entries",
+			wantEntries: 15,
+		},
+		{
+			name:        "max duration",
+			opts:        HarvestOptions{MaxDuration: 50 * time.Millisecond},
+			delay:       30 * time.Millisecond,
+			wantBudget:  "duration",
+			wantEntries: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := pagedServer(t, 100, tt.delay)
+			client := NewClient(WithBaseURL(server.URL), WithRateLimit(0))
+
+			harvest := client.Harvest(SearchParams{Query: "all:test", MaxResults: 10}, tt.opts)
+			count := 0
+			for range harvest.Entries(context.Background()) {
+				count++
+			}
+
+			err := harvest.Err()
+			if !errors.Is(err, ErrBudgetExceeded) {
+				t.Fatalf("Err() = %v; want ErrBudgetExceeded", err)
+			}
+			var budgetErr *BudgetError
+			if !errors.As(err, &budgetErr) || budgetErr.Budget != tt.wantBudget {
+				t.Errorf("Err() = %v; want %s budget", err, tt.wantBudget)
+			}
+			if count != tt.wantEntries {
+				t.Errorf("harvested %d entries; want %d", count, tt.wantEntries)
+			}
+			if harvest.Stats().Complete {
+				t.Error("Stats().Complete = true; want false")
+			}
+		})
+	}
+}
+
+func TestHarvestMaxEntriesAtEnd(t *testing.T) {
+	server, _ := pagedServer(t, 20, 0)
+	client := NewClient(WithBaseURL(server.URL), WithRateLimit(0))
+
+	harvest := client.Harvest(SearchParams{Query: "all:test", MaxResults: 10}, HarvestOptions{MaxEntries: 20})
+	for range harvest.Entries(context.Background()) {
+	}
+	if err := harvest.Err(); err != nil {
+		t.Errorf("Err() = %v; want nil when the budget matches the result count", err)
+	}
+	if !harvest.Stats().Complete {
+		t.Error("Stats().Complete = false; want true")
+	}
+}
+
+func TestHarvestEstimate(t *testing.T) {
+	server, requests := pagedServer(t, 1000, 0)
+	client := NewClient(WithBaseURL(server.URL), WithRateLimit(3*time.Second))
+
+	harvest := client.Harvest(SearchParams{Query: "all:test", MaxResults: 100}, HarvestOptions{MaxEntries: 450})
+	estimate, err := harvest.Estimate(context.Background())
+	if err != nil {
+		t.Fatalf("Estimate() = %v; want nil", err)
+	}
+
+	want := HarvestEstimate{
+		TotalResults: 1000,
+		PageSize:     100,
+		Pages:        5,
+		Entries:      450,
+		Duration:     12 * time.Second,
+		Truncated:    true,
+	}
+	if estimate != want {
+		t.Errorf("Estimate() = %+v; want %+v", estimate, want)
+	}
+
+	// The first page fetched by Estimate is reused by the harvest.
+	count := 0
+	for range harvest.Entries(context.Background()) {
+		count++
+		if count == 100 {
+			break
+		}
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("server saw %d requests; want 1", got)
+	}
+}
+
+func TestHarvestEstimateDurationBudget(t *testing.T) {
+	server, _ := pagedServer(t, 1000, 0)
+	client := NewClient(WithBaseURL(server.URL), WithRateLimit(3*time.Second))
+
+	harvest := client.Harvest(SearchParams{Query: "all:test", MaxResults: 100}, HarvestOptions{MaxDuration: 10 * time.Second})
+	estimate, err := harvest.Estimate(context.Background())
+	if err != nil {
+		t.Fatalf("Estimate() = %v; want nil", err)
+	}
+	if estimate.Pages != 4 || estimate.Entries != 400 || !estimate.Truncated {
+		t.Errorf("Estimate() = %+v; want 4 pages, 400 entries, truncated", estimate)
+	}
+}
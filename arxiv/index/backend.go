@@ -0,0 +1,184 @@
+package index
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// defaultMaxResults is the page size of the arXiv API when a search does
+// not set MaxResults.
+const defaultMaxResults = 10
+
+// SearchFunc returns a search function that answers SearchParams from the
+// index the way the arXiv API would, for use with arxiv.WithSearchFunc:
+//
+//	client := arxiv.NewClient(arxiv.WithSearchFunc(ix.SearchFunc()))
+//
+// The query is evaluated as by Search. An IdList alone returns those
+// entries in the order given, and with a query returns those of them that
+// match it. An ID with a version suffix matches only if that version is
+// the one indexed. Results are sorted by relevance unless SortBy says
+// otherwise, and paged with Start and MaxResults, which defaults to 10.
+func (ix *Index) SearchFunc() arxiv.SearchFunc {
+	return func(ctx context.Context, params arxiv.SearchParams) (arxiv.SearchResults, error) {
+		if err := ctx.Err(); err != nil {
+			return arxiv.SearchResults{}, err
+		}
+		if err := params.Validate(); err != nil {
+			return arxiv.SearchResults{}, err
+		}
+		entries, err := ix.results(params)
+		if err != nil {
+			return arxiv.SearchResults{}, err
+		}
+
+		maxResults := params.MaxResults
+		if maxResults <= 0 {
+			maxResults = defaultMaxResults
+		}
+		start := min(max(params.Start, 0), len(entries))
+		end := min(start+maxResults, len(entries))
+		return arxiv.SearchResults{
+			Title:        "arXiv Query: " + describeParams(params),
+			ID:           "index:" + describeParams(params),
+			Updated:      time.Now().UTC().Format(time.RFC3339),
+			TotalResults: len(entries),
+			StartIndex:   params.Start,
+			ItemsPerPage: maxResults,
+			Entries:      slices.Clone(entries[start:end]),
+			Params:       params,
+		}, nil
+	}
+}
+
+// results returns every entry answering params, in order.
+func (ix *Index) results(params arxiv.SearchParams) ([]arxiv.EntryMetadata, error) {
+	if params.Query == "" && len(params.IdList) == 0 {
+		return nil, errors.New("index: search needs a query or an ID list")
+	}
+
+	var entries []arxiv.EntryMetadata
+	if params.Query != "" {
+		hits, err := ix.Search(params.Query)
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range hits {
+			entries = append(entries, hit.Entry)
+		}
+		if params.SortOrder == arxiv.SortOrderAscending && (params.SortBy == "" || params.SortBy == arxiv.SortByRelevance) {
+			slices.Reverse(entries)
+		}
+	}
+
+	if len(params.IdList) > 0 {
+		wanted := ix.byID(params.IdList)
+		if params.Query == "" {
+			entries = wanted
+		} else {
+			entries = slices.DeleteFunc(entries, func(e arxiv.EntryMetadata) bool {
+				return !slices.ContainsFunc(wanted, func(w arxiv.EntryMetadata) bool { return w.ID == e.ID })
+			})
+		}
+	}
+
+	var key func(arxiv.EntryMetadata) time.Time
+	switch params.SortBy {
+	case arxiv.SortBySubmittedDate:
+		key = func(e arxiv.EntryMetadata) time.Time { return e.Published }
+	case arxiv.SortByLastUpdatedDate:
+		key = func(e arxiv.EntryMetadata) time.Time { return e.Updated }
+	}
+	if key != nil {
+		slices.SortStableFunc(entries, func(a, b arxiv.EntryMetadata) int {
+			if params.SortOrder == arxiv.SortOrderAscending {
+				return key(a).Compare(key(b))
+			}
+			return key(b).Compare(key(a))
+		})
+	}
+	return entries, nil
+}
+
+// byID returns the indexed entries with the given IDs, in order, skipping
+// IDs that are not indexed.
+func (ix *Index) byID(ids []string) []arxiv.EntryMetadata {
+	var entries []arxiv.EntryMetadata
+	seen := map[string]bool{}
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		entry, ok := ix.Get(id)
+		if !ok || seen[entry.ID] || id != arxiv.BaseID(id) && entry.ArxivID() != id {
+			continue
+		}
+		seen[entry.ID] = true
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func describeParams(params arxiv.SearchParams) string {
+	return fmt.Sprintf("search_query=%s&id_list=%s&start=%d&max_results=%d",
+		params.Query, strings.Join(params.IdList, ","), params.Start, params.MaxResults)
+}
+
+// ReadJSONL adds the entries in r, one JSON object per line as written by
+// "arxiv search -output jsonl", to the index. It returns the number of
+// entries read.
+func (ix *Index) ReadJSONL(r io.Reader) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 16<<20)
+	n, line := 0, 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		var entry arxiv.EntryMetadata
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return n, fmt.Errorf("index: line %d: %w", line, err)
+		}
+		ix.Add(entry)
+		n++
+	}
+	if err := scanner.Err(); err != nil {
+		return n, fmt.Errorf("index: reading: %w", err)
+	}
+	return n, nil
+}
+
+// ReadFile returns an index of the corpus at path, which is either a JSONL
+// file of entries or an index written by Save.
+func ReadFile(path string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("index: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	first, err := r.Peek(1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("index: reading %s: %w", path, err)
+	}
+	if len(first) == 0 || first[0] == '{' || first[0] == '\n' {
+		ix := New()
+		if _, err := ix.ReadJSONL(r); err != nil {
+			return nil, fmt.Errorf("%w (in %s)", err, path)
+		}
+		return ix, nil
+	}
+	return Load(r)
+}
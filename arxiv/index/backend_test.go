@@ -0,0 +1,135 @@
+package index
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+func TestSearchFunc(t *testing.T) {
+	ix := New()
+	ix.Add(corpus()...)
+	search := ix.SearchFunc()
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		params arxiv.SearchParams
+		want   []string
+		total  int
+	}{
+		{"relevance", arxiv.SearchParams{Query: "all:deep"}, []string{"2401.00002", "2402.00003", "math/0101005"}, 3},
+		{"relevance ascending", arxiv.SearchParams{Query: "all:deep", SortOrder: arxiv.SortOrderAscending}, []string{"math/0101005", "2402.00003", "2401.00002"}, 3},
+		{"submitted date", arxiv.SearchParams{Query: "cat:cs.LG", SortBy: arxiv.SortBySubmittedDate}, []string{"2403.00006", "2401.00002", "2401.00001"}, 3},
+		{"submitted date ascending", arxiv.SearchParams{Query: "cat:cs.LG", SortBy: arxiv.SortBySubmittedDate, SortOrder: arxiv.SortOrderAscending}, []string{"2401.00001", "2401.00002", "2403.00006"}, 3},
+		{"page", arxiv.SearchParams{Query: "cat:cs.LG", SortBy: arxiv.SortBySubmittedDate, Start: 1, MaxResults: 1}, []string{"2401.00002"}, 3},
+		{"past the end", arxiv.SearchParams{Query: "cat:cs.LG", Start: 5}, nil, 3},
+		{"ID list", arxiv.SearchParams{IdList: []string{"math/0101005", "2401.00001", "2499.99999"}}, []string{"math/0101005", "2401.00001"}, 2},
+		{"ID list versions", arxiv.SearchParams{IdList: []string{"2402.00003v2", "2402.00003v1", "2402.00004v1"}}, []string{"2402.00003", "2402.00004"}, 2},
+		{"ID list and query", arxiv.SearchParams{Query: "cat:cs.LG", IdList: []string{"math/0101005", "2401.00001", "2403.00006"}}, []string{"2403.00006", "2401.00001"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := search(ctx, tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range results.Entries {
+				got = append(got, e.BaseID())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("entries = %v, want %v", got, tt.want)
+			}
+			if results.TotalResults != tt.total || results.StartIndex != tt.params.Start {
+				t.Errorf("TotalResults = %d, StartIndex = %d, want %d, %d", results.TotalResults, results.StartIndex, tt.total, tt.params.Start)
+			}
+		})
+	}
+
+	for _, params := range []arxiv.SearchParams{{}, {Query: "xx:deep"}, {Query: "all:deep", MaxResults: 5000}} {
+		if _, err := search(ctx, params); err == nil {
+			t.Errorf("search(%+v) succeeded", params)
+		}
+	}
+}
+
+func TestSearchFuncClient(t *testing.T) {
+	ix := New()
+	ix.Add(corpus()...)
+	client := arxiv.NewClient(arxiv.WithSearchFunc(ix.SearchFunc()))
+	ctx := context.Background()
+
+	params := arxiv.SearchParams{Query: "cat:cs.*", MaxResults: 3, SortBy: arxiv.SortBySubmittedDate}
+	page, err := client.Search(ctx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.TotalResults != 4 || page.ItemsPerPage != 3 || !arxiv.SearchHasMoreResults(page) {
+		t.Fatalf("first page = %d entries of %d, %d per page", len(page.Entries), page.TotalResults, page.ItemsPerPage)
+	}
+	next, err := client.SearchNext(ctx, page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(next.Entries) != 1 || next.Entries[0].BaseID() != "2401.00001" || arxiv.SearchHasMoreResults(next) {
+		t.Errorf("second page = %+v", next)
+	}
+
+	var all []string
+	for e := range client.SearchIter(ctx, arxiv.SearchParams{Query: "cat:cs.*", MaxResults: 1}) {
+		all = append(all, e.BaseID())
+	}
+	if len(all) != 4 {
+		t.Errorf("SearchIter returned %v", all)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	var jsonl bytes.Buffer
+	enc := json.NewEncoder(&jsonl)
+	for _, e := range corpus() {
+		enc.Encode(e)
+	}
+	jsonl.WriteString("\n")
+	jsonlPath := filepath.Join(dir, "corpus.jsonl")
+	if err := os.WriteFile(jsonlPath, jsonl.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := New()
+	saved.Add(corpus()...)
+	var buf bytes.Buffer
+	saved.Save(&buf)
+	indexPath := filepath.Join(dir, "corpus.index")
+	if err := os.WriteFile(indexPath, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{jsonlPath, indexPath} {
+		ix, err := ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, _ := ix.Count(`ti:"deep learning"`); ix.Len() != 6 || n != 1 {
+			t.Errorf("%s: %d entries, %d matching", filepath.Base(path), ix.Len(), n)
+		}
+	}
+
+	bad := filepath.Join(dir, "bad.jsonl")
+	os.WriteFile(bad, []byte("{\"id\": \"x\"}\n{oops\n"), 0o644)
+	if _, err := ReadFile(bad); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("ReadFile of bad JSONL error = %v", err)
+	}
+	if _, err := ReadFile(filepath.Join(dir, "missing")); err == nil {
+		t.Error("ReadFile of missing file succeeded")
+	}
+}
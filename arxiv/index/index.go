@@ -0,0 +1,305 @@
+// Package index searches arXiv metadata offline. An Index is an inverted
+// index over the titles, abstracts, authors, categories, comments and
+// journal references of entries, ranked with BM25, that answers queries in
+// the syntax of the arXiv API, so that the same query runs online or
+// offline:
+//
+//	ix := index.New()
+//	s.ForEach(func(e arxiv.EntryMetadata) error { // s is a *store.Store
+//		ix.Add(e)
+//		return nil
+//	})
+//
+//	hits, err := ix.Search("ti:transformer AND cat:cs.CL ANDNOT au:smith")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	for _, hit := range hits {
+//		fmt.Printf("%.2f %s\n", hit.Score, hit.Entry.Title)
+//	}
+//
+// An index lives in memory; Save and Load write it to and read it from a
+// file, so that it need not be rebuilt from the metadata each time.
+package index
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// field is a searchable part of an entry.
+type field int
+
+const (
+	fieldTitle field = iota
+	fieldAbstract
+	fieldAuthors
+	fieldComment
+	fieldJournal
+	fieldCategories
+	numFields
+)
+
+// textFields are the fields that the all: prefix searches.
+var textFields = []field{fieldTitle, fieldAbstract, fieldAuthors, fieldComment, fieldJournal, fieldCategories}
+
+// authorGap separates the positions of consecutive authors in the authors
+// field, so that a phrase never matches across two names.
+const authorGap = 1 << 10
+
+// posting records the positions at which a term occurs in a document.
+type posting struct {
+	Doc       uint32
+	Positions []uint32
+}
+
+// fieldIndex is the inverted index of one field.
+type fieldIndex struct {
+	Terms   map[string][]posting // Postings of each term, in document order
+	Lengths []uint32             // Number of terms in the field of each document
+	Total   uint64               // Sum of the lengths of the live documents
+}
+
+// Index is an inverted index of entries. It is safe for concurrent use.
+type Index struct {
+	mu      sync.RWMutex
+	entries []arxiv.EntryMetadata
+	deleted []bool
+	ids     map[string]uint32 // Base ID to the document of its latest version
+	fields  [numFields]fieldIndex
+	live    int
+
+	// sorted holds the terms of each field in order, for prefix searches.
+	// It is built on first use and dropped when entries are added.
+	sortedMu sync.Mutex
+	sorted   [numFields][]string
+}
+
+// Hit is an entry matching a query, with its BM25 score. Entries matched
+// only by category or date have a score of zero.
+type Hit struct {
+	Entry arxiv.EntryMetadata
+	Score float64
+}
+
+// New returns an empty index.
+func New() *Index {
+	ix := &Index{ids: map[string]uint32{}}
+	for f := range ix.fields {
+		ix.fields[f].Terms = map[string][]posting{}
+	}
+	return ix
+}
+
+// Add indexes entries. An entry replaces an indexed entry with the same
+// base ID unless the indexed one is a later version, or the same version
+// updated no earlier.
+func (ix *Index) Add(entries ...arxiv.EntryMetadata) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for _, entry := range entries {
+		ix.add(entry)
+	}
+}
+
+func (ix *Index) add(entry arxiv.EntryMetadata) {
+	ix.sorted = [numFields][]string{}
+	id := entry.BaseID()
+	if old, ok := ix.ids[id]; ok {
+		stored := ix.entries[old]
+		if newer(stored, entry) {
+			return
+		}
+		ix.remove(old)
+	}
+
+	doc := uint32(len(ix.entries))
+	ix.entries = append(ix.entries, entry)
+	ix.deleted = append(ix.deleted, false)
+	ix.ids[id] = doc
+	ix.live++
+
+	for f := range ix.fields {
+		fi := &ix.fields[f]
+		positions := map[string][]uint32{}
+		var length uint32
+		for i, text := range fieldValues(entry, field(f)) {
+			offset := uint32(i) * authorGap
+			for j, term := range terms(text, field(f)) {
+				positions[term] = append(positions[term], offset+uint32(j))
+				length++
+			}
+		}
+		for term, pos := range positions {
+			fi.Terms[term] = append(fi.Terms[term], posting{Doc: doc, Positions: pos})
+		}
+		fi.Lengths = append(fi.Lengths, length)
+		fi.Total += uint64(length)
+	}
+}
+
+// newer reports whether the indexed entry a supersedes the entry b.
+func newer(a, b arxiv.EntryMetadata) bool {
+	va, vb := a.Version(), b.Version()
+	if va != vb {
+		return va > vb
+	}
+	return !b.Updated.After(a.Updated)
+}
+
+// remove marks a document deleted. Its postings stay in place and are
+// skipped by searches.
+func (ix *Index) remove(doc uint32) {
+	ix.deleted[doc] = true
+	ix.live--
+	for f := range ix.fields {
+		ix.fields[f].Total -= uint64(ix.fields[f].Lengths[doc])
+	}
+}
+
+// Delete removes the entry with the given base ID from the index, reporting
+// whether it was there.
+func (ix *Index) Delete(id string) bool {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	doc, ok := ix.ids[arxiv.BaseID(id)]
+	if ok {
+		delete(ix.ids, arxiv.BaseID(id))
+		ix.remove(doc)
+	}
+	return ok
+}
+
+// Get returns the indexed entry with the given base ID.
+func (ix *Index) Get(id string) (arxiv.EntryMetadata, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	doc, ok := ix.ids[arxiv.BaseID(id)]
+	if !ok {
+		return arxiv.EntryMetadata{}, false
+	}
+	return ix.entries[doc], true
+}
+
+// Len returns the number of entries in the index.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return ix.live
+}
+
+// snapshotVersion is the format of the postings that Save writes. It
+// changes whenever the terms an entry is indexed under change, such as when
+// author names began to be normalized in version 2; snapshots without a
+// version are version 1.
+const snapshotVersion = 2
+
+// snapshot is the form in which Save writes an index.
+type snapshot struct {
+	Version int
+	Entries []arxiv.EntryMetadata
+	Deleted []bool
+	Fields  [numFields]fieldIndex
+}
+
+// Save writes the index to w, to be read back with Load.
+func (ix *Index) Save(w io.Writer) error {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	err := gob.NewEncoder(w).Encode(snapshot{Version: snapshotVersion, Entries: ix.entries, Deleted: ix.deleted, Fields: ix.fields})
+	if err != nil {
+		return fmt.Errorf("index: saving: %w", err)
+	}
+	return nil
+}
+
+// Load reads an index written by Save. An index saved by an earlier version
+// of this package, whose postings may not match the terms queries now look
+// for, is rebuilt from its entries.
+func Load(r io.Reader) (*Index, error) {
+	var s snapshot
+	if err := gob.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("index: loading: %w", err)
+	}
+	if s.Version > snapshotVersion {
+		return nil, fmt.Errorf("index: loading: unsupported format version %d", s.Version)
+	}
+	if len(s.Deleted) != len(s.Entries) {
+		return nil, fmt.Errorf("index: loading: %d entries but %d deletion marks", len(s.Entries), len(s.Deleted))
+	}
+	if s.Version < snapshotVersion {
+		ix := New()
+		for doc, entry := range s.Entries {
+			if !s.Deleted[doc] {
+				ix.add(entry)
+			}
+		}
+		return ix, nil
+	}
+	ix := &Index{entries: s.Entries, deleted: s.Deleted, fields: s.Fields, ids: map[string]uint32{}}
+	for f := range ix.fields {
+		if len(ix.fields[f].Lengths) != len(ix.entries) {
+			return nil, fmt.Errorf("index: loading: field lengths do not match %d entries", len(ix.entries))
+		}
+		if ix.fields[f].Terms == nil {
+			ix.fields[f].Terms = map[string][]posting{}
+		}
+	}
+	for doc, entry := range ix.entries {
+		if !ix.deleted[doc] {
+			ix.ids[entry.BaseID()] = uint32(doc)
+			ix.live++
+		}
+	}
+	return ix, nil
+}
+
+// fieldValues returns the texts of a field of an entry. The authors field
+// has one text per author.
+func fieldValues(entry arxiv.EntryMetadata, f field) []string {
+	switch f {
+	case fieldTitle:
+		return []string{entry.Title}
+	case fieldAbstract:
+		return []string{entry.Summary}
+	case fieldAuthors:
+		return entry.AuthorNames()
+	case fieldComment:
+		return []string{entry.Comment}
+	case fieldJournal:
+		return []string{entry.JournalReference}
+	case fieldCategories:
+		var values []string
+		seen := map[string]bool{}
+		for _, c := range append([]arxiv.Category{entry.PrimaryCategory}, entry.Categories...) {
+			if c.Term != "" && !seen[c.Term] {
+				seen[c.Term] = true
+				values = append(values, c.Term)
+			}
+		}
+		return []string{strings.Join(values, " ")}
+	}
+	return nil
+}
+
+// terms splits text into the terms indexed for a field. Categories are kept
+// whole, as in "cs.AI"; other text is split into lowercase words. Author
+// names are normalized first, so that `Schr\"odinger` and "Schrodinger"
+// find "Schrödinger".
+func terms(text string, f field) []string {
+	switch f {
+	case fieldCategories:
+		return strings.Fields(strings.ToLower(text))
+	case fieldAuthors:
+		text = arxiv.NormalizeName(text)
+	}
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
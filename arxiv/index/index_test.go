@@ -0,0 +1,326 @@
+package index
+
+import (
+	"bytes"
+	"encoding/gob"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+func entry(id, title, summary string, published time.Time, authors []string, categories ...string) arxiv.EntryMetadata {
+	e := arxiv.EntryMetadata{
+		ID:        "http://arxiv.org/abs/" + id,
+		Title:     title,
+		Summary:   summary,
+		Published: published,
+		Updated:   published,
+	}
+	for _, name := range authors {
+		e.Authors = append(e.Authors, arxiv.Author{Name: name})
+	}
+	for _, c := range categories {
+		e.Categories = append(e.Categories, arxiv.Category{Term: c})
+	}
+	if len(categories) > 0 {
+		e.PrimaryCategory = arxiv.Category{Term: categories[0]}
+	}
+	return e
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+}
+
+func corpus() []arxiv.EntryMetadata {
+	entries := []arxiv.EntryMetadata{
+		entry("2401.00001v1", "Attention Is All You Need for Graphs",
+			"We apply transformer attention to graph neural networks.",
+			date(2024, 1, 5), []string{"Ada Lovelace", "Alan Turing"}, "cs.LG", "cs.AI"),
+		entry("2401.00002v1", "Deep Learning for Protein Folding",
+			"A deep neural network predicts protein structure. Deep models learn fast.",
+			date(2024, 1, 20), []string{"Rosalind Franklin"}, "q-bio.BM", "cs.LG"),
+		entry("2402.00003v2", "Learning Deep Representations of Text",
+			"Transformers learn representations of language.",
+			date(2024, 2, 10), []string{"Noam Chomsky", "Ada Smith"}, "cs.CL"),
+		entry("2402.00004v1", "Quantum Error Correction with Surface Codes",
+			"Surface codes protect qubits from noise.",
+			date(2024, 2, 28), []string{"Richard Feynman", "Alan Smith"}, "quant-ph"),
+		entry("math/0101005v1", "On the Riemann Hypothesis",
+			"A deep result on the zeros of the zeta function.",
+			date(2001, 1, 10), []string{"Bernhard Riemann"}, "math.NT"),
+		entry("2403.00006v1", "Graph Attention in Physics",
+			"Attention mechanisms for particle physics.",
+			date(2024, 3, 1), []string{"Maria del Maestro"}, "hep-ph", "cs.LG"),
+	}
+	entries[1].Comment = "12 pages, accepted at NeurIPS 2023"
+	entries[3].JournalReference = "Phys. Rev. Lett. 120, 100501 (2024)"
+	return entries
+}
+
+func ids(hits []Hit) []string {
+	var out []string
+	for _, hit := range hits {
+		out = append(out, hit.Entry.BaseID())
+	}
+	return out
+}
+
+func TestSearch(t *testing.T) {
+	ix := New()
+	ix.Add(corpus()...)
+
+	tests := []struct {
+		query string
+		want  []string // Sorted
+	}{
+		{"ti:deep", []string{"2401.00002", "2402.00003"}},
+		{"ti:Deep", []string{"2401.00002", "2402.00003"}},
+		{"abs:deep", []string{"2401.00002", "math/0101005"}},
+		{"all:deep", []string{"2401.00002", "2402.00003", "math/0101005"}},
+		{"deep", []string{"2401.00002", "2402.00003", "math/0101005"}},
+		{`ti:"deep learning"`, []string{"2401.00002"}},
+		{"ti:deep learning", []string{"2401.00002", "2402.00003"}},
+		{"ti:deep AND learning", []string{"2401.00002", "2402.00003"}},
+		{"ti:deep AND ti:protein", []string{"2401.00002"}},
+		{"ti:protein OR ti:quantum", []string{"2401.00002", "2402.00004"}},
+		{"all:deep ANDNOT cat:cs.LG", []string{"2402.00003", "math/0101005"}},
+		{"cat:cs.LG", []string{"2401.00001", "2401.00002", "2403.00006"}},
+		{"cat:cs.lg", []string{"2401.00001", "2401.00002", "2403.00006"}},
+		{"cat:cs", []string{"2401.00001", "2401.00002", "2402.00003", "2403.00006"}},
+		{"cat:cs.*", []string{"2401.00001", "2401.00002", "2402.00003", "2403.00006"}},
+		{"cat:math.NT", []string{"math/0101005"}},
+		{"cat:hep-ph", []string{"2403.00006"}},
+		{"cat:hep", nil},
+		{"au:smith", []string{"2402.00003", "2402.00004"}},
+		{`au:"Ada Smith"`, []string{"2402.00003"}},
+		{"au:Smith_Ada", []string{"2402.00003"}},
+		{"au:del_maestro", []string{"2403.00006"}},
+		{"au:ada_turing", nil},
+		{"au:Lovelace_A", []string{"2401.00001"}},
+		{"au:Smith_A", []string{"2402.00003", "2402.00004"}},
+		{"au:Smith_N", nil},
+		{"au:del_Maestro_M", []string{"2403.00006"}},
+		{"au:Maestro_A", nil},
+		{"co:neurips", []string{"2401.00002"}},
+		{"jr:phys", []string{"2402.00004"}},
+		{"ti:attention AND (cat:hep-ph OR au:turing)", []string{"2401.00001", "2403.00006"}},
+		{"ti:attention AND cat:hep-ph OR au:turing", []string{"2401.00001", "2403.00006"}},
+		{"ti:(protein OR quantum)", []string{"2401.00002", "2402.00004"}},
+		{"submittedDate:[202401010000 TO 202401312359]", []string{"2401.00001", "2401.00002"}},
+		{"submittedDate:[20240201 TO 20240228]", []string{"2402.00003", "2402.00004"}},
+		{"cat:cs.LG AND submittedDate:[202401100000 TO 202412312359]", []string{"2401.00002", "2403.00006"}},
+		{"ti:nothing", nil},
+		{"", []string{"2401.00001", "2401.00002", "2402.00003", "2402.00004", "2403.00006", "math/0101005"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			hits, err := ix.Search(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := ids(hits)
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+			if n, _ := ix.Count(tt.query); n != len(tt.want) {
+				t.Errorf("Count(%q) = %d, want %d", tt.query, n, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestSearchQueryBuilder(t *testing.T) {
+	ix := New()
+	ix.Add(corpus()...)
+	query := arxiv.NewSearchQuery().
+		Title("attention").
+		And().
+		Group(func(g *arxiv.SearchQuery) {
+			g.Category("hep-ph").Or().Category("cs.AI")
+		}).
+		SubmittedBetween(date(2024, 2, 1), date(2024, 12, 31))
+	hits, err := ix.Search(query.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(hits); !slices.Equal(got, []string{"2403.00006"}) {
+		t.Errorf("Search(%q) = %v", query, got)
+	}
+}
+
+func TestSearchAuthorName(t *testing.T) {
+	ix := New()
+	ix.Add(corpus()...)
+	ix.Add(entry("2404.00007v1", "Wave Mechanics", "Waves.", date(2024, 4, 1),
+		[]string{`Erwin Schr\"odinger`}, "quant-ph"))
+
+	for _, name := range []string{"Maria del Maestro", "del Maestro, M.", "Erwin Schrödinger", "E. Schrodinger"} {
+		query := arxiv.NewSearchQuery().AuthorName(name)
+		hits, err := ix.Search(query.String())
+		if err != nil {
+			t.Fatalf("Search(%q): %v", query, err)
+		}
+		if len(hits) != 1 || !arxiv.ParseName(name).Matches(hits[0].Entry.Authors[0].ParsedName()) {
+			t.Errorf("Search(%q) = %v", query, ids(hits))
+		}
+	}
+}
+
+func TestSearchRanking(t *testing.T) {
+	ix := New()
+	ix.Add(corpus()...)
+
+	// Two title matches beat an abstract match, and the entry saying "deep"
+	// twice in its abstract beats the one saying it once.
+	hits, err := ix.Search("all:deep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := ids(hits)
+	if got[2] != "math/0101005" || hits[2].Score <= 0 || hits[1].Score <= hits[2].Score {
+		t.Errorf("all:deep ranked %v with scores %v, %v, %v", got, hits[0].Score, hits[1].Score, hits[2].Score)
+	}
+	if got[0] != "2401.00002" {
+		t.Errorf("all:deep ranked %v, want 2401.00002 first", got)
+	}
+
+	// Excluded terms do not count, and category matches tie, newest first.
+	hits, _ = ix.Search("cat:cs.LG ANDNOT ti:protein")
+	if got := ids(hits); !slices.Equal(got, []string{"2403.00006", "2401.00001"}) || hits[0].Score != 0 {
+		t.Errorf("cat:cs.LG ANDNOT ti:protein ranked %v", got)
+	}
+}
+
+func TestAddReplacesVersions(t *testing.T) {
+	ix := New()
+	ix.Add(corpus()...)
+	v1 := entry("2402.00003v1", "An Old Title", "", date(2024, 2, 1), nil, "cs.CL")
+	ix.Add(v1)
+	if e, _ := ix.Get("2402.00003"); e.ArxivID() != "2402.00003v2" {
+		t.Errorf("an older version replaced %s", e.ArxivID())
+	}
+
+	v3 := entry("2402.00003v3", "Learning Shallow Representations", "", date(2024, 2, 10), nil, "cs.CL")
+	ix.Add(v3)
+	if ix.Len() != 6 {
+		t.Errorf("Len = %d, want 6", ix.Len())
+	}
+	if n, _ := ix.Count("ti:deep"); n != 1 {
+		t.Errorf("ti:deep matches %d entries after replacement, want 1", n)
+	}
+	if n, _ := ix.Count("ti:shallow"); n != 1 {
+		t.Errorf("ti:shallow matches %d entries, want 1", n)
+	}
+
+	if !ix.Delete("2402.00003v3") || ix.Delete("2402.00003") {
+		t.Error("Delete did not report the entry's presence")
+	}
+	if n, _ := ix.Count("cat:cs.CL"); n != 0 || ix.Len() != 5 {
+		t.Errorf("after Delete, cat:cs.CL matches %d of %d entries", n, ix.Len())
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	ix := New()
+	ix.Add(corpus()...)
+	ix.Delete("2402.00004")
+	var buf bytes.Buffer
+	if err := ix.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Len() != 5 {
+		t.Errorf("Len = %d, want 5", loaded.Len())
+	}
+	for _, query := range []string{"all:deep", "au:smith", `ti:"graph attention"`, "cat:cs.*"} {
+		want, _ := ix.Search(query)
+		got, _ := loaded.Search(query)
+		if !slices.Equal(ids(got), ids(want)) {
+			t.Errorf("Search(%q) after Load = %v, want %v", query, ids(got), ids(want))
+		}
+	}
+	loaded.Add(entry("2404.00007v1", "Deep Sea Graphs", "", date(2024, 4, 1), nil, "cs.DM"))
+	if n, _ := loaded.Count("ti:deep"); n != 3 {
+		t.Errorf("ti:deep matches %d entries after adding to a loaded index, want 3", n)
+	}
+
+	if _, err := Load(strings.NewReader("not an index")); err == nil {
+		t.Error("Load of garbage succeeded")
+	}
+}
+
+func TestLoadRebuildsOldSnapshots(t *testing.T) {
+	ix := New()
+	ix.Add(corpus()...)
+	ix.Delete("2402.00004")
+
+	// A snapshot from before author names were normalized has no version
+	// and postings under other terms; here, none at all for authors.
+	old := snapshot{Entries: ix.entries, Deleted: ix.deleted, Fields: ix.fields}
+	old.Fields[fieldAuthors].Terms = map[string][]posting{}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(old); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Len() != 5 {
+		t.Errorf("Len = %d, want 5", loaded.Len())
+	}
+	for _, query := range []string{"au:del_Maestro_M", "au:Smith_A", "all:deep"} {
+		want, _ := ix.Search(query)
+		got, _ := loaded.Search(query)
+		if len(want) == 0 || !slices.Equal(ids(got), ids(want)) {
+			t.Errorf("Search(%q) after loading an old snapshot = %v, want %v", query, ids(got), ids(want))
+		}
+	}
+
+	// Adding an entry refreshes the terms that initials are looked up in.
+	loaded.Add(entry("2404.00007v1", "Deep Sea Graphs", "", date(2024, 4, 1), []string{"Zelda Smith"}, "cs.DM"))
+	if n, _ := loaded.Count("au:Smith_Z"); n != 1 {
+		t.Errorf("au:Smith_Z matches %d entries after Add, want 1", n)
+	}
+
+	buf.Reset()
+	if err := gob.NewEncoder(&buf).Encode(snapshot{Version: snapshotVersion + 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(&buf); err == nil {
+		t.Error("Load of a snapshot from a later version succeeded")
+	}
+}
+
+func TestSearchErrors(t *testing.T) {
+	ix := New()
+	tests := []struct {
+		query string
+		err   string
+	}{
+		{"xx:deep", "unknown field: xx"},
+		{`ti:"deep`, "unterminated quote"},
+		{"(ti:deep", "unbalanced parentheses"},
+		{"ti:deep)", "unbalanced parentheses"},
+		{"ti:deep AND", "query ends where a term is expected"},
+		{"AND ti:deep", "unexpected AND"},
+		{"ti: deep", "ti: has no value"},
+		{"submittedDate:2024", "needs a range"},
+		{"submittedDate:[2024 TO 2025]", "invalid date: 2024"},
+		{"submittedDate:[202401010000 202501010000]", "invalid date range"},
+	}
+	for _, tt := range tests {
+		_, err := ix.Search(tt.query)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Search(%q) error = %v, want %q", tt.query, err, tt.err)
+		}
+	}
+}
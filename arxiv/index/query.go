@@ -0,0 +1,316 @@
+package index
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Query syntax
+//
+// Queries are written as for the search_query parameter of the arXiv API,
+// and as built by arxiv.SearchQuery: terms prefixed with a field, such as
+// "ti:transformer" or `au:"John Smith"`, joined by AND, OR and ANDNOT and
+// grouped with parentheses. Operators bind left to right with equal
+// precedence. A term without a prefix searches all fields, except that
+// words following a prefixed term with no operator in between belong to
+// the same field, so "ti:deep learning" needs both words in the title. A
+// prefix may also apply to a group, as in "ti:(deep OR shallow)".
+
+// queryFields maps the field prefixes of the arXiv API to indexed fields.
+var queryFields = map[string][]field{
+	"ti":  {fieldTitle},
+	"abs": {fieldAbstract},
+	"au":  {fieldAuthors},
+	"co":  {fieldComment},
+	"jr":  {fieldJournal},
+	"cat": {fieldCategories},
+	"all": textFields,
+}
+
+const submittedDate = "submittedDate"
+
+// node is a parsed query.
+type node interface{}
+
+// termNode matches entries with a term, or a phrase of several words, in
+// one of the fields of a prefix.
+type termNode struct {
+	prefix string
+	value  string
+}
+
+// rangeNode matches entries submitted between two times. The end is
+// exclusive.
+type rangeNode struct {
+	start, end time.Time
+}
+
+// boolNode combines two queries with AND, OR or ANDNOT.
+type boolNode struct {
+	op          string
+	left, right node
+}
+
+type tokenKind int
+
+const (
+	tokenTerm   tokenKind = iota // A term, possibly with a prefix
+	tokenRange                   // A submittedDate range
+	tokenPrefix                  // A prefix applying to the following group
+	tokenOp                      // AND, OR or ANDNOT
+	tokenOpen                    // (
+	tokenClose                   // )
+)
+
+type token struct {
+	kind   tokenKind
+	prefix string
+	value  string
+}
+
+// lex splits a query into tokens.
+func lex(query string) ([]token, error) {
+	var tokens []token
+	i := 0
+	word := func() string {
+		start := i
+		for i < len(query) && !strings.ContainsRune(` ()":`, rune(query[i])) {
+			i++
+		}
+		return query[start:i]
+	}
+	quoted := func() (string, error) {
+		end := strings.IndexByte(query[i+1:], '"')
+		if end < 0 {
+			return "", fmt.Errorf("index: unterminated quote in query: %s", query)
+		}
+		s := query[i+1 : i+1+end]
+		i += end + 2
+		return s, nil
+	}
+
+	for i < len(query) {
+		switch c := query[i]; c {
+		case ' ', '\t', '\n', '+':
+			i++
+		case '(':
+			tokens = append(tokens, token{kind: tokenOpen})
+			i++
+		case ')':
+			tokens = append(tokens, token{kind: tokenClose})
+			i++
+		case '"':
+			s, err := quoted()
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenTerm, value: s})
+		default:
+			w := word()
+			if i < len(query) && query[i] == ':' {
+				i++
+				t, err := lexPrefixed(query, &i, w, quoted)
+				if err != nil {
+					return nil, err
+				}
+				tokens = append(tokens, t)
+				continue
+			}
+			if w == "" {
+				// A colon without a prefix.
+				return nil, fmt.Errorf("index: unexpected %q in query: %s", query[i], query)
+			}
+			switch op := strings.ToUpper(w); op {
+			case "AND", "OR", "ANDNOT":
+				tokens = append(tokens, token{kind: tokenOp, value: op})
+			default:
+				tokens = append(tokens, token{kind: tokenTerm, value: w})
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// lexPrefixed reads the value following the prefix of a term, at *i.
+func lexPrefixed(query string, i *int, prefix string, quoted func() (string, error)) (token, error) {
+	if prefix == submittedDate {
+		if *i >= len(query) || query[*i] != '[' {
+			return token{}, fmt.Errorf("index: %s needs a range such as [202401010000 TO 202402010000]: %s", submittedDate, query)
+		}
+		end := strings.IndexByte(query[*i:], ']')
+		if end < 0 {
+			return token{}, fmt.Errorf("index: unterminated date range in query: %s", query)
+		}
+		value := query[*i+1 : *i+end]
+		*i += end + 1
+		return token{kind: tokenRange, value: value}, nil
+	}
+	if _, ok := queryFields[prefix]; !ok {
+		return token{}, fmt.Errorf("index: unknown field: %s", prefix)
+	}
+	switch {
+	case *i >= len(query) || query[*i] == ' ' || query[*i] == ')':
+		return token{}, fmt.Errorf("index: %s: has no value in query: %s", prefix, query)
+	case query[*i] == '(':
+		return token{kind: tokenPrefix, prefix: prefix}, nil
+	case query[*i] == '"':
+		s, err := quoted()
+		return token{kind: tokenTerm, prefix: prefix, value: s}, err
+	}
+	start := *i
+	for *i < len(query) && !strings.ContainsRune(` ()"`, rune(query[*i])) {
+		*i++
+	}
+	return token{kind: tokenTerm, prefix: prefix, value: query[start:*i]}, nil
+}
+
+// parser builds a node tree from tokens.
+type parser struct {
+	tokens []token
+	pos    int
+	query  string
+}
+
+// parse parses a query. An empty query parses to nil, which matches every
+// entry.
+func parse(query string) (node, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	p := &parser{tokens: tokens, query: query}
+	n, err := p.expr("all")
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("index: unbalanced parentheses in query: %s", query)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+// expr parses a sequence of terms and groups joined by operators, up to a
+// closing parenthesis or the end of the query. Terms without a prefix
+// search the fields of defaultPrefix.
+func (p *parser) expr(defaultPrefix string) (node, error) {
+	left, last, err := p.unary(defaultPrefix, "")
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind == tokenClose {
+			return left, nil
+		}
+		op, inherit := "AND", last
+		if t.kind == tokenOp {
+			op, inherit = t.value, ""
+			p.pos++
+		}
+		var right node
+		right, last, err = p.unary(defaultPrefix, inherit)
+		if err != nil {
+			return nil, err
+		}
+		left = &boolNode{op: op, left: left, right: right}
+	}
+}
+
+// unary parses a term or a group. A term without a prefix takes inherit,
+// or else defaultPrefix. It also returns the prefix of a term, which the
+// next term inherits if no operator comes between them.
+func (p *parser) unary(defaultPrefix, inherit string) (node, string, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, "", fmt.Errorf("index: query ends where a term is expected: %s", p.query)
+	}
+	p.pos++
+	switch t.kind {
+	case tokenTerm:
+		prefix := t.prefix
+		switch {
+		case prefix != "":
+		case inherit != "":
+			prefix = inherit
+		default:
+			return &termNode{prefix: defaultPrefix, value: t.value}, "", nil
+		}
+		return &termNode{prefix: prefix, value: t.value}, prefix, nil
+	case tokenRange:
+		n, err := parseRange(t.value)
+		return n, "", err
+	case tokenPrefix:
+		defaultPrefix = t.prefix
+		if next, ok := p.peek(); !ok || next.kind != tokenOpen {
+			return nil, "", fmt.Errorf("index: %s: has no value in query: %s", t.prefix, p.query)
+		}
+		p.pos++
+		fallthrough
+	case tokenOpen:
+		n, err := p.expr(defaultPrefix)
+		if err != nil {
+			return nil, "", err
+		}
+		if next, ok := p.peek(); !ok || next.kind != tokenClose {
+			return nil, "", fmt.Errorf("index: unbalanced parentheses in query: %s", p.query)
+		}
+		p.pos++
+		return n, "", nil
+	}
+	return nil, "", fmt.Errorf("index: unexpected %s where a term is expected: %s", describe(t), p.query)
+}
+
+func describe(t token) string {
+	if t.kind == tokenClose {
+		return `")"`
+	}
+	return t.value
+}
+
+// parseRange parses the inside of a date range such as "202401010000 TO
+// 202401312359". Both ends are included, to the minute, or to the day if
+// given as YYYYMMDD.
+func parseRange(value string) (node, error) {
+	start, end, ok := strings.Cut(value, " TO ")
+	if !ok {
+		return nil, fmt.Errorf("index: invalid date range: [%s]", value)
+	}
+	from, _, err := parseDate(strings.TrimSpace(start))
+	if err != nil {
+		return nil, err
+	}
+	to, step, err := parseDate(strings.TrimSpace(end))
+	if err != nil {
+		return nil, err
+	}
+	return &rangeNode{start: from, end: to.Add(step)}, nil
+}
+
+// parseDate parses a date in the YYYYMMDDhhmm or YYYYMMDD form of the arXiv
+// API, returning the time and the precision it was given to.
+func parseDate(s string) (time.Time, time.Duration, error) {
+	switch len(s) {
+	case 12:
+		t, err := time.Parse("200601021504", s)
+		if err == nil {
+			return t, time.Minute, nil
+		}
+	case 8:
+		t, err := time.Parse("20060102", s)
+		if err == nil {
+			return t, 24 * time.Hour, nil
+		}
+	}
+	return time.Time{}, 0, fmt.Errorf("index: invalid date: %s", s)
+}
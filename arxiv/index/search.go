@@ -0,0 +1,361 @@
+package index
+
+import (
+	"math"
+	"path"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// BM25 parameters.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// fieldWeights scales the BM25 score of a term by the field it occurs in.
+// Categories filter but do not rank.
+var fieldWeights = [numFields]float64{
+	fieldTitle:      2,
+	fieldAbstract:   1,
+	fieldAuthors:    1,
+	fieldComment:    0.5,
+	fieldJournal:    0.5,
+	fieldCategories: 0,
+}
+
+// Search returns the entries matching query, best first. Entries with equal
+// scores, such as those matched only by category or date, come most
+// recently submitted first. An empty query matches every entry.
+func (ix *Index) Search(query string) ([]Hit, error) {
+	n, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	docs := ix.eval(n)
+	scores := ix.score(n, docs)
+	hits := make([]Hit, len(docs))
+	for i, doc := range docs {
+		hits[i] = Hit{Entry: ix.entries[doc], Score: scores[i]}
+	}
+	slices.SortStableFunc(hits, func(a, b Hit) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return b.Entry.Published.Compare(a.Entry.Published)
+	})
+	return hits, nil
+}
+
+// Count returns the number of entries matching query.
+func (ix *Index) Count(query string) (int, error) {
+	n, err := parse(query)
+	if err != nil {
+		return 0, err
+	}
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.eval(n)), nil
+}
+
+// eval returns the live documents matching n, in order.
+func (ix *Index) eval(n node) []uint32 {
+	switch n := n.(type) {
+	case nil:
+		return ix.all(func(uint32) bool { return true })
+	case *termNode:
+		var docs []uint32
+		for _, f := range queryFields[n.prefix] {
+			docs = union(docs, ix.match(f, n.value))
+		}
+		return docs
+	case *rangeNode:
+		return ix.all(func(doc uint32) bool {
+			published := ix.entries[doc].Published
+			return !published.Before(n.start) && published.Before(n.end)
+		})
+	case *boolNode:
+		left, right := ix.eval(n.left), ix.eval(n.right)
+		switch n.op {
+		case "AND":
+			return intersect(left, right)
+		case "OR":
+			return union(left, right)
+		case "ANDNOT":
+			return subtract(left, right)
+		}
+	}
+	return nil
+}
+
+// all returns the live documents for which keep returns true.
+func (ix *Index) all(keep func(uint32) bool) []uint32 {
+	var docs []uint32
+	for doc := range ix.entries {
+		if !ix.deleted[doc] && keep(uint32(doc)) {
+			docs = append(docs, uint32(doc))
+		}
+	}
+	return docs
+}
+
+// match returns the live documents with value in field f. A value of
+// several words matches them as a phrase, or, for authors, in any order
+// within one author's name.
+func (ix *Index) match(f field, value string) []uint32 {
+	fi := &ix.fields[f]
+	if f == fieldCategories {
+		return ix.matchCategory(strings.ToLower(strings.TrimSpace(value)))
+	}
+	words := terms(value, f)
+	if len(words) == 0 {
+		return nil
+	}
+
+	postings := make([][]posting, len(words))
+	for i, word := range words {
+		if f == fieldAuthors && utf8.RuneCountInString(word) == 1 {
+			// An initial, as in au:Hinton_G, matches any name it starts.
+			postings[i] = ix.prefixPostings(f, word)
+			continue
+		}
+		postings[i] = fi.Terms[word]
+	}
+	var docs []uint32
+	cursors := make([]int, len(words))
+next:
+	for _, p := range postings[0] {
+		found := []posting{p}
+		for i := 1; i < len(words); i++ {
+			list := postings[i]
+			j, ok := slices.BinarySearchFunc(list[cursors[i]:], p.Doc, func(q posting, doc uint32) int {
+				return int(int64(q.Doc) - int64(doc))
+			})
+			cursors[i] += j
+			if !ok {
+				continue next
+			}
+			found = append(found, list[cursors[i]])
+		}
+		if ix.deleted[p.Doc] || len(found) > 1 && !adjacent(found, f == fieldAuthors) {
+			continue
+		}
+		docs = append(docs, p.Doc)
+	}
+	return docs
+}
+
+// prefixPostings merges the postings of every term of field f starting
+// with prefix, found by binary search in the sorted terms of the field.
+func (ix *Index) prefixPostings(f field, prefix string) []posting {
+	fi := &ix.fields[f]
+	sorted := ix.sortedTerms(f)
+	byDoc := map[uint32][]uint32{}
+	i, _ := slices.BinarySearch(sorted, prefix)
+	for _, term := range sorted[i:] {
+		if !strings.HasPrefix(term, prefix) {
+			break
+		}
+		for _, p := range fi.Terms[term] {
+			byDoc[p.Doc] = append(byDoc[p.Doc], p.Positions...)
+		}
+	}
+	merged := make([]posting, 0, len(byDoc))
+	for doc, positions := range byDoc {
+		slices.Sort(positions)
+		merged = append(merged, posting{Doc: doc, Positions: positions})
+	}
+	slices.SortFunc(merged, func(a, b posting) int { return int(int64(a.Doc) - int64(b.Doc)) })
+	return merged
+}
+
+// sortedTerms returns the terms of field f in order. The caller must hold
+// at least a read lock on ix.
+func (ix *Index) sortedTerms(f field) []string {
+	ix.sortedMu.Lock()
+	defer ix.sortedMu.Unlock()
+	if ix.sorted[f] == nil {
+		terms := make([]string, 0, len(ix.fields[f].Terms))
+		for term := range ix.fields[f].Terms {
+			terms = append(terms, term)
+		}
+		slices.Sort(terms)
+		ix.sorted[f] = terms
+	}
+	return ix.sorted[f]
+}
+
+// adjacent reports whether the positions of consecutive words form a
+// phrase, or, if sameAuthor is set, fall within one author's name.
+func adjacent(words []posting, sameAuthor bool) bool {
+	for _, start := range words[0].Positions {
+		ok := true
+		for i, w := range words[1:] {
+			var found bool
+			if sameAuthor {
+				found = slices.ContainsFunc(w.Positions, func(p uint32) bool { return p/authorGap == start/authorGap })
+			} else {
+				_, found = slices.BinarySearch(w.Positions, start+uint32(i)+1)
+			}
+			if !found {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchCategory returns the live documents in a category, in the categories
+// matching a pattern such as "cs.*", or in the categories of an archive
+// such as "cs" or "math".
+func (ix *Index) matchCategory(value string) []uint32 {
+	var docs []uint32
+	for term, postings := range ix.fields[fieldCategories].Terms {
+		if term != value && !strings.HasPrefix(term, value+".") {
+			if ok, _ := path.Match(value, term); !ok {
+				continue
+			}
+		}
+		var list []uint32
+		for _, p := range postings {
+			if !ix.deleted[p.Doc] {
+				list = append(list, p.Doc)
+			}
+		}
+		docs = union(docs, list)
+	}
+	return docs
+}
+
+// score returns the BM25 scores of docs for the terms of n, except those
+// that entries must not match.
+func (ix *Index) score(n node, docs []uint32) []float64 {
+	scores := make([]float64, len(docs))
+	type key struct {
+		f    field
+		term string
+	}
+	seen := map[key]bool{}
+	var walk func(node)
+	walk = func(n node) {
+		switch n := n.(type) {
+		case *termNode:
+			for _, f := range queryFields[n.prefix] {
+				if fieldWeights[f] == 0 {
+					continue
+				}
+				for _, term := range terms(n.value, f) {
+					if k := (key{f, term}); !seen[k] {
+						seen[k] = true
+						ix.addScores(f, term, docs, scores)
+					}
+				}
+			}
+		case *boolNode:
+			walk(n.left)
+			if n.op != "ANDNOT" {
+				walk(n.right)
+			}
+		}
+	}
+	walk(n)
+	return scores
+}
+
+// addScores adds the BM25 score of term in field f to the scores of docs.
+// Document frequencies count deleted entries until the index is rebuilt.
+func (ix *Index) addScores(f field, term string, docs []uint32, scores []float64) {
+	fi := &ix.fields[f]
+	postings := fi.Terms[term]
+	if len(postings) == 0 || ix.live == 0 {
+		return
+	}
+	n, df := float64(ix.live), float64(len(postings))
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+	avgLength := float64(fi.Total) / n
+	if avgLength == 0 {
+		avgLength = 1
+	}
+
+	j := 0
+	for i, doc := range docs {
+		for j < len(postings) && postings[j].Doc < doc {
+			j++
+		}
+		if j == len(postings) {
+			return
+		}
+		if postings[j].Doc != doc {
+			continue
+		}
+		tf := float64(len(postings[j].Positions))
+		norm := k1 * (1 - b + b*float64(fi.Lengths[doc])/avgLength)
+		scores[i] += fieldWeights[f] * idf * tf * (k1 + 1) / (tf + norm)
+	}
+}
+
+// intersect returns the documents in both sorted lists.
+func intersect(a, b []uint32) []uint32 {
+	var out []uint32
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+// union returns the documents in either sorted list.
+func union(a, b []uint32) []uint32 {
+	out := make([]uint32, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] < b[j]:
+			out = append(out, a[i])
+			i++
+		case a[i] > b[j]:
+			out = append(out, b[j])
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	out = append(out, a[i:]...)
+	return append(out, b[j:]...)
+}
+
+// subtract returns the documents in sorted list a but not in b.
+func subtract(a, b []uint32) []uint32 {
+	var out []uint32
+	j := 0
+	for _, doc := range a {
+		for j < len(b) && b[j] < doc {
+			j++
+		}
+		if j == len(b) || b[j] != doc {
+			out = append(out, doc)
+		}
+	}
+	return out
+}
@@ -313,3 +313,40 @@ func TestInterceptorWithRetry(t *testing.T) {
 		}
 	})
 }
+
+func TestWithSearchFunc(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	backend := func(ctx context.Context, params SearchParams) (SearchResults, error) {
+		entries := []EntryMetadata{{ID: fmt.Sprintf("http://arxiv.org/abs/2401.%05dv1", params.Start)}}
+		return SearchResults{TotalResults: 3, StartIndex: params.Start, ItemsPerPage: 1, Entries: entries, Params: params}, nil
+	}
+	var intercepted int
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithRateLimit(0),
+		WithSearchFunc(backend),
+		WithInterceptor(func(ctx context.Context, params SearchParams, next SearchFunc) (SearchResults, error) {
+			intercepted++
+			return next(ctx, params)
+		}),
+	)
+
+	var ids []string
+	for entry := range client.SearchIter(context.Background(), SearchParams{Query: "all:test", MaxResults: 1}) {
+		ids = append(ids, entry.ArxivID())
+	}
+	if fmt.Sprint(ids) != "[2401.00000v1 2401.00001v1 2401.00002v1]" {
+		t.Errorf("SearchIter returned %v", ids)
+	}
+	if intercepted != 3 {
+		t.Errorf("interceptor called %d times, want 3", intercepted)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("server received %d requests, want none", n)
+	}
+}
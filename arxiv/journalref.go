@@ -0,0 +1,170 @@
+package arxiv
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// JournalInfo holds the parts of a journal reference such as
+// "Phys. Rev. D 98, 030001 (2018)".
+type JournalInfo struct {
+	Journal    string            // Journal or proceedings name, such as "Phys. Rev. D"
+	Volume     string            // Volume, such as "98"
+	Issue      string            // Issue or number within the volume
+	Pages      string            // Page, page range or article number, such as "436-444" or "030001"
+	Year       int               // Year of publication
+	Confidence JournalConfidence // How reliably each field was extracted
+}
+
+// JournalConfidence tells how reliably each field of a JournalInfo was
+// extracted. A field with ConfidenceNone was not found.
+type JournalConfidence struct {
+	Journal, Volume, Issue, Pages, Year Confidence
+}
+
+// JournalInfo extracts the journal, volume, issue, pages and year from the
+// entry's journal reference.
+func (e EntryMetadata) JournalInfo() JournalInfo {
+	return ParseJournalReference(e.JournalReference)
+}
+
+var (
+	journalPrefix   = regexp.MustCompile(`(?i)^(?:published\s+in|appeared\s+in|in)\s*:?\s+`)
+	parenYear       = regexp.MustCompile(`\(\s*(?:[A-Za-z]+\.?\s+)?(?:\d{1,2}\s+)?(19\d{2}|20\d{2})(?:\s+[A-Za-z]+\.?)?(?:\s+\d{1,2})?\s*\)`)
+	trailingYear    = regexp.MustCompile(`(?:^|[\s,;:])((?:19|20)\d{2})\s*\.?$`)
+	anyYear         = regexp.MustCompile(`\b((?:19|20)\d{2})\b`)
+	volumeLabel     = regexp.MustCompile(`(?i)\b(?:vol(?:ume)?\.?|v\.)\s*(\d+[A-Za-z]?)`)
+	issueLabel      = regexp.MustCompile(`(?i)\b(?:no\.|nr\.|num\.|number|issue|iss\.)\s*(\d+(?:\s*[-/]\s*\d+)?)`)
+	pagesLabel      = regexp.MustCompile(`(?i)(?:\bpp?\.|\bpages?\b|\bpgs?\.)\s*([A-Za-z]?\d+[A-Za-z]?(?:\s*[-–—]+\s*[A-Za-z]?\d+[A-Za-z]?)?)`)
+	articleLabel    = regexp.MustCompile(`(?i)\b(?:article(?:\s+(?:no\.|number))?|art\.(?:\s+no\.)?|paper)\s*(?:no\.\s*)?([A-Za-z]?\d+)`)
+	labelStart      = regexp.MustCompile(`(?i)(?:^|[\s,;:])(?:vol(?:ume)?\.?|v\.|no\.|nr\.|number|issue|pp?\.|pages?|article|art\.)\s*\d`)
+	numberStart     = regexp.MustCompile(`(?:^|[^A-Za-z0-9])(\d+)(st|nd|rd|th)?`)
+	positionalParts = regexp.MustCompile(`([A-Za-z]?\d+[A-Za-z]?(?:\s*[-–—]+\s*[A-Za-z]?\d+[A-Za-z]?)?)(?:\s*\(([^)]*)\))?`)
+)
+
+// ParseJournalReference splits a free-text journal reference, such as "Phys.
+// Rev. D 98, 030001 (2018)", "JHEP 0805:061,2008" or "IEEE Trans. Inf.
+// Theory, vol. 52, no. 4, pp. 1289-1306, 2006", into its parts. Extraction
+// is best effort: Confidence tells which fields were found and how reliably.
+// Only the first of several references separated by semicolons is parsed.
+func ParseJournalReference(ref string) JournalInfo {
+	var info JournalInfo
+	ref = normalizeSpace(ref)
+	if i := strings.IndexByte(ref, ';'); i >= 0 {
+		ref = strings.TrimSpace(ref[:i])
+	}
+	ref = journalPrefix.ReplaceAllString(ref, "")
+	if ref == "" {
+		return info
+	}
+
+	// The year, preferring one in parentheses or at the end, which is where
+	// the year of publication goes in every common style.
+	yearFromPosition := false
+	if m := parenYear.FindStringSubmatchIndex(ref); m != nil {
+		info.Year, _ = strconv.Atoi(ref[m[2]:m[3]])
+		info.Confidence.Year = ConfidenceHigh
+		ref = ref[:m[0]] + " " + ref[m[1]:]
+	} else if m := trailingYear.FindStringSubmatchIndex(ref); m != nil && m[2] > 0 {
+		info.Year, _ = strconv.Atoi(ref[m[2]:m[3]])
+		info.Confidence.Year = ConfidenceHigh
+		ref = ref[:m[2]]
+	} else if m := anyYear.FindStringSubmatch(ref); m != nil {
+		info.Year, _ = strconv.Atoi(m[1])
+		info.Confidence.Year = ConfidenceLow
+		yearFromPosition = true
+	}
+
+	// The journal is everything before the first number or label, skipping
+	// ordinals such as the "40th" of "Proceedings of the 40th ICML".
+	end := len(ref)
+	for _, m := range numberStart.FindAllStringSubmatchIndex(ref, -1) {
+		if m[4] < 0 {
+			end = m[2]
+			break
+		}
+	}
+	if m := labelStart.FindStringIndex(ref); m != nil && m[0] < end {
+		end = m[0]
+	}
+	info.Journal = strings.TrimRight(strings.TrimSpace(ref[:end]), ",:;(")
+	info.Journal = strings.TrimSpace(info.Journal)
+	if strings.IndexFunc(info.Journal, isLetter) < 0 {
+		info.Journal = ""
+	}
+	rest := ref[end:]
+
+	// Labelled parts, as in "vol. 52, no. 4, pp. 1289-1306".
+	takeLabel := func(re *regexp.Regexp) string {
+		m := re.FindStringSubmatchIndex(rest)
+		if m == nil {
+			return ""
+		}
+		value := rest[m[2]:m[3]]
+		rest = rest[:m[0]] + " " + rest[m[1]:]
+		return value
+	}
+	if v := takeLabel(volumeLabel); v != "" {
+		info.Volume, info.Confidence.Volume = v, ConfidenceHigh
+	}
+	if v := takeLabel(issueLabel); v != "" {
+		info.Issue, info.Confidence.Issue = normalizeRange(v), ConfidenceHigh
+	}
+	if v := takeLabel(pagesLabel); v != "" {
+		info.Pages, info.Confidence.Pages = normalizeRange(v), ConfidenceHigh
+	} else if v := takeLabel(articleLabel); v != "" {
+		info.Pages, info.Confidence.Pages = v, ConfidenceHigh
+	}
+
+	// Unlabelled parts, in the order volume(issue), pages, as in
+	// "98, 030001", "15(1):1929-1958" or "0805:061".
+	for _, m := range positionalParts.FindAllStringSubmatch(rest, -1) {
+		switch {
+		case info.Volume == "":
+			info.Volume = m[1]
+			if m[2] != "" && info.Issue == "" && !anyYear.MatchString(m[2]) {
+				info.Issue = normalizeSpace(m[2])
+			}
+		case info.Pages == "":
+			info.Pages = normalizeRange(m[1])
+		}
+	}
+	if info.Confidence.Volume == ConfidenceNone && info.Volume != "" {
+		switch {
+		case yearFromPosition && info.Volume == strconv.Itoa(info.Year):
+			// A year in the middle of the reference, as in "Proceedings of
+			// ICML 2019, pp. 1-10", is not a volume.
+			info.Volume, info.Issue = "", ""
+		case info.Pages != "" && info.Journal != "":
+			info.Confidence.Volume = ConfidenceHigh
+		default:
+			info.Confidence.Volume = ConfidenceLow
+		}
+	}
+	if info.Issue != "" && info.Confidence.Issue == ConfidenceNone {
+		info.Confidence.Issue = info.Confidence.Volume
+	}
+	if info.Pages != "" && info.Confidence.Pages == ConfidenceNone {
+		info.Confidence.Pages = ConfidenceLow
+		if info.Volume != "" {
+			info.Confidence.Pages = ConfidenceHigh
+		}
+	}
+	if info.Journal != "" {
+		info.Confidence.Journal = ConfidenceLow
+		if info.Volume != "" {
+			info.Confidence.Journal = ConfidenceHigh
+		}
+	}
+	return info
+}
+
+// normalizeRange turns a range such as "436 – 444" into "436-444".
+func normalizeRange(s string) string {
+	return strings.NewReplacer(" ", "", "—", "-", "–", "-", "--", "-").Replace(s)
+}
+
+func isLetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 0x7f
+}
@@ -0,0 +1,71 @@
+package arxiv
+
+import "testing"
+
+func TestParseJournalReference(t *testing.T) {
+	const (
+		low  = ConfidenceLow
+		high = ConfidenceHigh
+	)
+	all := JournalConfidence{Journal: high, Volume: high, Issue: high, Pages: high, Year: high}
+	noIssue := JournalConfidence{Journal: high, Volume: high, Pages: high, Year: high}
+	tests := []struct {
+		ref  string
+		want JournalInfo
+	}{
+		{"", JournalInfo{}},
+		{"Phys. Rev. D 98, 030001 (2018)", JournalInfo{"Phys. Rev. D", "98", "", "030001", 2018, noIssue}},
+		{"Graphs Combin. 25 (2009) 219", JournalInfo{"Graphs Combin.", "25", "", "219", 2009, noIssue}},
+		{"Nature 521, 436-444 (2015)", JournalInfo{"Nature", "521", "", "436-444", 2015, noIssue}},
+		{"JHEP 0805:061,2008", JournalInfo{"JHEP", "0805", "", "061", 2008, noIssue}},
+		{"Phys.Rev.Lett.99:141302,2007", JournalInfo{"Phys.Rev.Lett.", "99", "", "141302", 2007, noIssue}},
+		{"Nucl.Phys.Proc.Suppl.164:1-8,2007", JournalInfo{"Nucl.Phys.Proc.Suppl.", "164", "", "1-8", 2007, noIssue}},
+		{"Astrophys.J.667:L1-L4,2007", JournalInfo{"Astrophys.J.", "667", "", "L1-L4", 2007, noIssue}},
+		{"J. Mach. Learn. Res. 15(1):1929-1958, 2014", JournalInfo{"J. Mach. Learn. Res.", "15", "1", "1929-1958", 2014, all}},
+		{"IEEE Trans. Inf. Theory, vol. 52, no. 4, pp. 1289-1306, 2006", JournalInfo{"IEEE Trans. Inf. Theory", "52", "4", "1289-1306", 2006, all}},
+		{"Ann. Statist. Volume 36, Number 1 (2008), 199-227", JournalInfo{"Ann. Statist.", "36", "1", "199-227", 2008, all}},
+		{"Math. Ann. 340 (2008), no. 2, 373-394", JournalInfo{"Math. Ann.", "340", "2", "373-394", 2008, all}},
+		{"A&A 500, 1-10 (2009)", JournalInfo{"A&A", "500", "", "1-10", 2009, noIssue}},
+		{"Phys. Lett. B 659:1-5 (2008)", JournalInfo{"Phys. Lett. B", "659", "", "1-5", 2008, noIssue}},
+		{"Eur. Phys. J. C 72 (2012) 2173", JournalInfo{"Eur. Phys. J. C", "72", "", "2173", 2012, noIssue}},
+		{"J. Phys. A: Math. Theor. 41 (2008) 295207", JournalInfo{"J. Phys. A: Math. Theor.", "41", "", "295207", 2008, noIssue}},
+		{"Proc. Natl. Acad. Sci. USA 104, 123 (2007)", JournalInfo{"Proc. Natl. Acad. Sci. USA", "104", "", "123", 2007, noIssue}},
+		{"Nature (London) 407, 651 (2000)", JournalInfo{"Nature (London)", "407", "", "651", 2000, noIssue}},
+		{"Class. Quant. Grav. 24 (2007) S1 - S10", JournalInfo{"Class. Quant. Grav.", "24", "", "S1-S10", 2007, noIssue}},
+		{"Int. J. Mod. Phys. A 22 (2007) 1–20", JournalInfo{"Int. J. Mod. Phys. A", "22", "", "1-20", 2007, noIssue}},
+		{"JHEP 05 (2019) 123", JournalInfo{"JHEP", "05", "", "123", 2019, noIssue}},
+		{"Published in: Journal of Physics: Conference Series 1234 (2019) 012001", JournalInfo{"Journal of Physics: Conference Series", "1234", "", "012001", 2019, noIssue}},
+		{"Phys. Rev. B 75, 104503 (2007); Erratum: Phys. Rev. B 76, 019901 (2007)", JournalInfo{"Phys. Rev. B", "75", "", "104503", 2007, noIssue}},
+		{"Proceedings of ICML 2019, pp. 1-10", JournalInfo{"Proceedings of ICML", "", "", "1-10", 2019,
+			JournalConfidence{Journal: low, Pages: high, Year: low}}},
+		{"In Proceedings of the 40th International Conference on Machine Learning, PMLR 202:1234-1250, 2023", JournalInfo{
+			"Proceedings of the 40th International Conference on Machine Learning, PMLR", "202", "", "1234-1250", 2023, noIssue}},
+		{"Nature Communications 9, Article number: 4509 (2018)", JournalInfo{"Nature Communications", "9", "", "4509", 2018, noIssue}},
+		{"Journal of Statistical Software 50(3), 2012", JournalInfo{"Journal of Statistical Software", "50", "3", "", 2012,
+			JournalConfidence{Journal: high, Volume: low, Issue: low, Year: high}}},
+		{"Monthly Notices of the Royal Astronomical Society", JournalInfo{"Monthly Notices of the Royal Astronomical Society", "", "", "", 0,
+			JournalConfidence{Journal: low}}},
+		{"Journal of Functional Analysis (2010)", JournalInfo{"Journal of Functional Analysis", "", "", "", 2010,
+			JournalConfidence{Journal: low, Year: high}}},
+		{"Phys. Rev. E 77 (2008), no. 3, 036111, 10 pp.", JournalInfo{"Phys. Rev. E", "77", "3", "036111", 2008, all}},
+		{"Adv. Math. 226 (2011), no. 4, pp. 3355--3395", JournalInfo{"Adv. Math.", "226", "4", "3355-3395", 2011, all}},
+		{"Comm. Math. Phys. 300 (2010) 1-53", JournalInfo{"Comm. Math. Phys.", "300", "", "1-53", 2010, noIssue}},
+		{"ApJ, 700, 1 (2009 July 20)", JournalInfo{"ApJ", "700", "", "1", 2009, noIssue}},
+		{"Physica D 238 (2009) 1 - 12.", JournalInfo{"Physica D", "238", "", "1-12", 2009, noIssue}},
+		{"Science, 2014", JournalInfo{"Science", "", "", "", 2014, JournalConfidence{Journal: low, Year: high}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			if got := ParseJournalReference(tt.ref); got != tt.want {
+				t.Errorf("ParseJournalReference(%q)\n got %+v\nwant %+v", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntryJournalInfo(t *testing.T) {
+	e := EntryMetadata{JournalReference: "Graphs Combin. 25 (2009) 219"}
+	if info := e.JournalInfo(); info.Journal != "Graphs Combin." || info.Volume != "25" || info.Year != 2009 {
+		t.Errorf("JournalInfo() = %+v", info)
+	}
+}
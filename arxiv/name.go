@@ -0,0 +1,411 @@
+package arxiv
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PersonName is an author's name split into its parts, so that "Geoffrey
+// E. Hinton", "G. Hinton" and "Hinton, Geoffrey" can be recognized as the
+// same person.
+type PersonName struct {
+	Given    string // Given names and initials, such as "Geoffrey E."
+	Particle string // Lowercase particle of the family name, such as "van der" or "de"
+	Family   string // Family name, such as "Hinton"
+	Suffix   string // Generational suffix, such as "Jr." or "III"
+}
+
+// ParsedName parses the author's name.
+func (a Author) ParsedName() PersonName {
+	return ParseName(a.Name)
+}
+
+var (
+	latexAccent   = regexp.MustCompile("\\\\([`'^\"~=.])\\s*(?:\\{\\s*(\\\\i|[A-Za-z])\\s*\\}|(\\\\i|[A-Za-z]))")
+	latexLetterAc = regexp.MustCompile(`\\([uvHcdbtrk])(?:\s*\{\s*(\\i|[A-Za-z])\s*\}|\s+(\\i|[A-Za-z]))`)
+	latexSymbol   = regexp.MustCompile(`\\(ss|aa|AA|ae|AE|oe|OE|o|O|l|L|i)(?:\{\}|\s+|\b)`)
+	parenthetical = regexp.MustCompile(`\s*\([^)]*\)`)
+	nameInitials  = regexp.MustCompile(`^(\p{Lu}\.)+$`)
+)
+
+// latexSymbols are the letters LaTeX writes as commands.
+var latexSymbols = map[string]string{
+	"ss": "ß", "aa": "å", "AA": "Å", "ae": "æ", "AE": "Æ", "oe": "œ", "OE": "Œ",
+	"o": "ø", "O": "Ø", "l": "ł", "L": "Ł", "i": "ı",
+}
+
+// latexAccents lists, for each LaTeX accent command, base letters followed
+// by the letter with the accent.
+var latexAccents = map[string]string{
+	`"`: "AÄEËIÏOÖUÜYŸaäeëiïoöuüyÿ",
+	`'`: "AÁEÉIÍOÓUÚYÝCĆNŃSŚZŹaáeéiíoóuúyýcćnńsśzź",
+	"`": "AÀEÈIÌOÒUÙaàeèiìoòuù",
+	`^`: "AÂEÊIÎOÔUÛaâeêiîoôuû",
+	`~`: "AÃNÑOÕaãnñoõ",
+	`=`: "AĀEĒIĪOŌUŪaāeēiīoōuū",
+	`.`: "ZŻzżIİ",
+	`u`: "AĂGĞaăgğ",
+	`v`: "CČDĎEĚNŇRŘSŠTŤZŽcčdďeěnňrřsštťzž",
+	`H`: "OŐUŰoőuű",
+	`c`: "CÇSŞTŢcçsştţ",
+	`r`: "AÅUŮaåuů",
+	`k`: "AĄEĘaąeę",
+}
+
+// latexMarks are the combining marks for accents without a precomposed
+// letter in latexAccents.
+var latexMarks = map[string]rune{
+	"`": '\u0300', "'": '\u0301', "^": '\u0302', "~": '\u0303', "=": '\u0304',
+	"u": '\u0306', ".": '\u0307', `"`: '\u0308', "r": '\u030a', "H": '\u030b',
+	"v": '\u030c', "d": '\u0323', "c": '\u0327', "k": '\u0328', "b": '\u0331', "t": '\u0361',
+}
+
+// foldTable maps letters with diacritics to their plain forms.
+var foldTable = func() map[rune]string {
+	table := map[rune]string{
+		'ß': "ss", 'æ': "ae", 'Æ': "AE", 'œ': "oe", 'Œ': "OE", 'ø': "o", 'Ø': "O",
+		'ł': "l", 'Ł': "L", 'ı': "i", 'đ': "d", 'Đ': "D", 'ð': "d", 'Ð': "D",
+		'þ': "th", 'Þ': "Th", 'ș': "s", 'Ș': "S", 'ț': "t", 'Ț': "T",
+		'ĉ': "c", 'Ĉ': "C", 'ċ': "c", 'Ċ': "C", 'ĝ': "g", 'Ĝ': "G", 'ġ': "g", 'Ġ': "G",
+		'ģ': "g", 'Ģ': "G", 'ĥ': "h", 'Ĥ': "H", 'ħ': "h", 'Ħ': "H", 'ĩ': "i", 'Ĩ': "I",
+		'ĭ': "i", 'Ĭ': "I", 'į': "i", 'Į': "I", 'ĵ': "j", 'Ĵ': "J", 'ķ': "k", 'Ķ': "K",
+		'ĺ': "l", 'Ĺ': "L", 'ļ': "l", 'Ļ': "L", 'ľ': "l", 'Ľ': "L", 'ņ': "n", 'Ņ': "N",
+		'ŏ': "o", 'Ŏ': "O", 'ŕ': "r", 'Ŕ': "R", 'ŗ': "r", 'Ŗ': "R", 'ŝ': "s", 'Ŝ': "S",
+		'ŧ': "t", 'Ŧ': "T", 'ũ': "u", 'Ũ': "U", 'ŭ': "u", 'Ŭ': "U", 'ų': "u", 'Ų': "U",
+		'ŵ': "w", 'Ŵ': "W", 'ŷ': "y", 'Ŷ': "Y", 'ź': "z", 'ç': "c",
+	}
+	for _, pairs := range latexAccents {
+		runes := []rune(pairs)
+		for i := 0; i+1 < len(runes); i += 2 {
+			table[runes[i+1]] = string(runes[i])
+		}
+	}
+	return table
+}()
+
+// decodeLaTeX replaces LaTeX accents and letters, as in `Schr\"odinger` or
+// `Erd{\H o}s`, with Unicode, and drops the braces around them.
+func decodeLaTeX(s string) string {
+	if !strings.ContainsRune(s, '\\') {
+		return s
+	}
+	accent := func(cmd, letter string) string {
+		if letter == `\i` {
+			letter = "i"
+		}
+		runes := []rune(latexAccents[cmd])
+		for i := 0; i+1 < len(runes); i += 2 {
+			if string(runes[i]) == letter {
+				return string(runes[i+1])
+			}
+		}
+		return letter + string(latexMarks[cmd])
+	}
+	replace := func(re *regexp.Regexp) {
+		s = re.ReplaceAllStringFunc(s, func(m string) string {
+			sub := re.FindStringSubmatch(m)
+			return accent(sub[1], sub[2]+sub[3])
+		})
+	}
+	replace(latexAccent)
+	replace(latexLetterAc)
+	s = latexSymbol.ReplaceAllStringFunc(s, func(m string) string {
+		return latexSymbols[latexSymbol.FindStringSubmatch(m)[1]]
+	})
+	return strings.NewReplacer("{", "", "}", "").Replace(s)
+}
+
+// NormalizeName returns the form of a name, or part of one, used to compare
+// names: LaTeX accents are decoded, diacritics removed, letters lowercased
+// and runs of space collapsed, so that `Schr\"odinger`, "Schrödinger" and
+// "SCHRODINGER" all become "schrodinger".
+func NormalizeName(s string) string {
+	s = decodeLaTeX(s)
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Combining marks, as left by decodeLaTeX.
+		case foldTable[r] != "":
+			b.WriteString(strings.ToLower(foldTable[r]))
+		default:
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return normalizeSpace(b.String())
+}
+
+// nameParticles are the words that start a family name when written in
+// lowercase, as in "Ludwig van Beethoven".
+var nameParticles = map[string]bool{
+	"van": true, "von": true, "der": true, "den": true, "de": true, "del": true,
+	"della": true, "di": true, "da": true, "do": true, "dos": true, "das": true,
+	"du": true, "des": true, "la": true, "le": true, "ten": true, "ter": true,
+	"zu": true, "af": true, "al": true, "el": true, "bin": true, "ibn": true,
+}
+
+// nameSuffixes are generational suffixes, keyed by their normalized form.
+var nameSuffixes = map[string]bool{"jr": true, "sr": true, "ii": true, "iii": true, "iv": true}
+
+// groupWords end the names of collaborations, which are kept whole as a
+// family name.
+var groupWords = map[string]bool{"collaboration": true, "consortium": true, "team": true, "group": true, "collaborations": true}
+
+func isSuffix(s string) bool {
+	return nameSuffixes[strings.ToLower(strings.Trim(s, ".,"))]
+}
+
+// ParseName splits a name such as "Geoffrey E. Hinton", "Hinton, Geoffrey
+// E.", "Ludwig van Beethoven" or "Martin Luther King, Jr." into its parts.
+// LaTeX accents are decoded, and notes in parentheses, such as affiliation
+// markers, are dropped. Names of collaborations, such as "ATLAS
+// Collaboration", are kept whole as the family name.
+func ParseName(name string) PersonName {
+	s := decodeLaTeX(name)
+	s = parenthetical.ReplaceAllString(s, "")
+	s = normalizeSpace(strings.Trim(normalizeSpace(s), " ,;"))
+	if s == "" {
+		return PersonName{}
+	}
+	words := strings.Fields(s)
+	if groupWords[strings.ToLower(words[len(words)-1])] {
+		return PersonName{Family: s}
+	}
+
+	var parts []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	var n PersonName
+	if len(parts) > 1 && isSuffix(parts[len(parts)-1]) {
+		n.Suffix = normalizeSuffix(parts[len(parts)-1])
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) > 1 {
+		// "Family, Given" or "Family, Suffix, Given".
+		if len(parts) > 2 && isSuffix(parts[1]) {
+			n.Suffix = normalizeSuffix(parts[1])
+			parts = append(parts[:1], parts[2:]...)
+		}
+		n.Given = normalizeGiven(strings.Join(parts[1:], " "))
+		n.Particle, n.Family = splitParticle(strings.Fields(parts[0]))
+		return n
+	}
+
+	words = strings.Fields(parts[0])
+	if len(words) > 1 && isSuffix(words[len(words)-1]) {
+		n.Suffix = normalizeSuffix(words[len(words)-1])
+		words = words[:len(words)-1]
+	}
+	if len(words) == 1 {
+		n.Family = words[0]
+		return n
+	}
+	// The family name starts at the last word, or at the lowercase particles
+	// before it.
+	start := len(words) - 1
+	for start > 1 && nameParticles[words[start-1]] {
+		start--
+	}
+	n.Given = normalizeGiven(strings.Join(words[:start], " "))
+	n.Particle, n.Family = splitParticle(words[start:])
+	return n
+}
+
+// splitParticle splits the words of a family name into its leading
+// lowercase particles and the rest.
+func splitParticle(words []string) (particle, family string) {
+	i := 0
+	for i < len(words)-1 && nameParticles[words[i]] {
+		i++
+	}
+	return strings.Join(words[:i], " "), strings.Join(words[i:], " ")
+}
+
+// normalizeGiven spaces out run-together initials, turning "G.E." into
+// "G. E.".
+func normalizeGiven(given string) string {
+	var words []string
+	for _, w := range strings.Fields(given) {
+		if nameInitials.MatchString(w) && len(w) > 2 {
+			for _, initial := range strings.SplitAfter(w, ".") {
+				if initial != "" {
+					words = append(words, initial)
+				}
+			}
+			continue
+		}
+		words = append(words, w)
+	}
+	return strings.Join(words, " ")
+}
+
+func normalizeSuffix(s string) string {
+	s = strings.Trim(s, ".,")
+	switch lower := strings.ToLower(s); lower {
+	case "jr", "sr":
+		return strings.ToUpper(lower[:1]) + lower[1:] + "."
+	}
+	return strings.ToUpper(s)
+}
+
+// String returns the name in the order given names, family name, suffix,
+// as in "Martin Luther King Jr.".
+func (n PersonName) String() string {
+	return joinNonEmpty(n.Given, n.Particle, n.Family, n.Suffix)
+}
+
+// Initials returns the initials of the given names, as in "G. E." for
+// "Geoffrey E." or "J.-P." for "Jean-Pierre".
+func (n PersonName) Initials() string {
+	var initials []string
+	for _, w := range strings.Fields(n.Given) {
+		var parts []string
+		for _, part := range strings.Split(w, "-") {
+			if r, _ := utf8.DecodeRuneInString(part); unicode.IsLetter(r) {
+				parts = append(parts, string(unicode.ToUpper(r))+".")
+			}
+		}
+		if len(parts) > 0 {
+			initials = append(initials, strings.Join(parts, "-"))
+		}
+	}
+	return strings.Join(initials, " ")
+}
+
+// Abbreviated returns the name with the given names shortened to initials,
+// as in "G. E. Hinton".
+func (n PersonName) Abbreviated() string {
+	return joinNonEmpty(n.Initials(), n.Particle, n.Family, n.Suffix)
+}
+
+// Key returns a normalized key for the name: the family name with its
+// particle and the first initial, lowercase, without diacritics and joined
+// by underscores, as in "hinton_g" or "van_der_berg_j". Names of the same
+// person written differently usually share a key, and names sharing a key
+// may be the same person; Matches tells more reliably.
+func (n PersonName) Key() string {
+	key := strings.ReplaceAll(NormalizeName(joinNonEmpty(n.Particle, n.Family)), " ", "_")
+	if initial := n.firstInitial(); initial != "" {
+		key += "_" + initial
+	}
+	return key
+}
+
+func (n PersonName) firstInitial() string {
+	given := NormalizeName(n.Given)
+	for _, r := range given {
+		if unicode.IsLetter(r) {
+			return string(r)
+		}
+	}
+	return ""
+}
+
+// Matches reports whether two names may belong to the same person: their
+// family names are equal apart from case, diacritics and particles, and
+// their given names agree as far as both go, an initial agreeing with any
+// name it starts. "G. Hinton" matches "Geoffrey E. Hinton", but "G. J.
+// Hinton" does not match "G. E. Hinton".
+func (n PersonName) Matches(other PersonName) bool {
+	if n.Family == "" || other.Family == "" {
+		return false
+	}
+	family := NormalizeName(joinNonEmpty(n.Particle, n.Family))
+	otherFamily := NormalizeName(joinNonEmpty(other.Particle, other.Family))
+	if family != otherFamily && NormalizeName(n.Family) != NormalizeName(other.Family) {
+		return false
+	}
+	a, b := givenNames(n.Given), givenNames(other.Given)
+	for i := 0; i < len(a) && i < len(b); i++ {
+		x, y := a[i], b[i]
+		if utf8.RuneCountInString(x) > 1 && utf8.RuneCountInString(y) > 1 {
+			if x != y {
+				return false
+			}
+		} else {
+			rx, _ := utf8.DecodeRuneInString(x)
+			ry, _ := utf8.DecodeRuneInString(y)
+			if rx != ry {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// givenNames splits given names into normalized names and initials,
+// without periods; an initial is a single letter.
+func givenNames(given string) []string {
+	var names []string
+	for _, w := range strings.FieldsFunc(NormalizeName(given), func(r rune) bool {
+		return r == ' ' || r == '.' || r == '-'
+	}) {
+		if w != "" {
+			names = append(names, w)
+		}
+	}
+	return names
+}
+
+// QueryVariants returns the values of au: terms that find the name in the
+// arXiv API, which expects the family name and the first initial joined by
+// underscores, as in "Hinton_G" or "del_Maestro_A". Names with diacritics
+// also get a variant without them, and names with a particle one without
+// the particle.
+func (n PersonName) QueryVariants() []string {
+	if n.Family == "" {
+		return nil
+	}
+	initial := strings.ToUpper(n.firstInitial())
+	variant := func(family string) string {
+		family = strings.Join(strings.Fields(family), "_")
+		if initial == "" {
+			return family
+		}
+		return family + "_" + initial
+	}
+	families := []string{joinNonEmpty(n.Particle, n.Family)}
+	if n.Particle != "" {
+		families = append(families, n.Family)
+	}
+	var variants []string
+	for _, family := range families {
+		for _, v := range []string{variant(family), variant(foldKeepCase(family))} {
+			if !slices.Contains(variants, v) {
+				variants = append(variants, v)
+			}
+		}
+	}
+	return variants
+}
+
+// foldKeepCase removes diacritics without lowercasing.
+func foldKeepCase(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case foldTable[r] != "":
+			b.WriteString(foldTable[r])
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func joinNonEmpty(parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, " ")
+}
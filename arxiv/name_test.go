@@ -0,0 +1,166 @@
+package arxiv
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseName(t *testing.T) {
+	tests := []struct {
+		name string
+		want PersonName
+	}{
+		{"Geoffrey E. Hinton", PersonName{Given: "Geoffrey E.", Family: "Hinton"}},
+		{"G.E. Hinton", PersonName{Given: "G. E.", Family: "Hinton"}},
+		{"Hinton, Geoffrey", PersonName{Given: "Geoffrey", Family: "Hinton"}},
+		{"Hinton", PersonName{Family: "Hinton"}},
+		{"Ludwig van Beethoven", PersonName{Given: "Ludwig", Particle: "van", Family: "Beethoven"}},
+		{"Jan van der Berg", PersonName{Given: "Jan", Particle: "van der", Family: "Berg"}},
+		{"van der Berg, Jan", PersonName{Given: "Jan", Particle: "van der", Family: "Berg"}},
+		{"Adolfo del Campo", PersonName{Given: "Adolfo", Particle: "del", Family: "Campo"}},
+		{"Martin Luther King, Jr.", PersonName{Given: "Martin Luther", Family: "King", Suffix: "Jr."}},
+		{"Martin Luther King Jr", PersonName{Given: "Martin Luther", Family: "King", Suffix: "Jr."}},
+		{"King, Jr., Martin Luther", PersonName{Given: "Martin Luther", Family: "King", Suffix: "Jr."}},
+		{"Smith, John, III", PersonName{Given: "John", Family: "Smith", Suffix: "III"}},
+		{`Erwin Schr\"odinger`, PersonName{Given: "Erwin", Family: "Schrödinger"}},
+		{`Paul Erd{\H o}s`, PersonName{Given: "Paul", Family: "Erdős"}},
+		{`Ant{\'o}nio Ma{\~n}ez`, PersonName{Given: "António", Family: "Mañez"}},
+		{`Kurt G\"{o}del`, PersonName{Given: "Kurt", Family: "Gödel"}},
+		{`Pawe{\l} Nurowski`, PersonName{Given: "Paweł", Family: "Nurowski"}},
+		{"Jean-Pierre Serre", PersonName{Given: "Jean-Pierre", Family: "Serre"}},
+		{"Jane Doe (MIT)", PersonName{Given: "Jane", Family: "Doe"}},
+		{"ATLAS Collaboration", PersonName{Family: "ATLAS Collaboration"}},
+		{"  ", PersonName{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseName(tt.name); got != tt.want {
+				t.Errorf("ParseName(%q) = %#v, want %#v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPersonNameForms(t *testing.T) {
+	tests := []struct {
+		name        string
+		str         string
+		initials    string
+		abbreviated string
+		key         string
+		variants    []string
+	}{
+		{"Geoffrey E. Hinton", "Geoffrey E. Hinton", "G. E.", "G. E. Hinton", "hinton_g", []string{"Hinton_G"}},
+		{"Hinton, G.E.", "G. E. Hinton", "G. E.", "G. E. Hinton", "hinton_g", []string{"Hinton_G"}},
+		{"Jean-Pierre Serre", "Jean-Pierre Serre", "J.-P.", "J.-P. Serre", "serre_j", []string{"Serre_J"}},
+		{"Jan van der Berg", "Jan van der Berg", "J.", "J. van der Berg", "van_der_berg_j", []string{"van_der_Berg_J", "Berg_J"}},
+		{"Martin Luther King, Jr.", "Martin Luther King Jr.", "M. L.", "M. L. King Jr.", "king_m", []string{"King_M"}},
+		{`Erwin Schr\"odinger`, "Erwin Schrödinger", "E.", "E. Schrödinger", "schrodinger_e", []string{"Schrödinger_E", "Schrodinger_E"}},
+		{"Élie Cartan", "Élie Cartan", "É.", "É. Cartan", "cartan_e", []string{"Cartan_E"}},
+		{"Maria del Maestro", "Maria del Maestro", "M.", "M. del Maestro", "del_maestro_m", []string{"del_Maestro_M", "Maestro_M"}},
+		{"Euclid", "Euclid", "", "Euclid", "euclid", []string{"Euclid"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := ParseName(tt.name)
+			if got := n.String(); got != tt.str {
+				t.Errorf("String() = %q, want %q", got, tt.str)
+			}
+			if got := n.Initials(); got != tt.initials {
+				t.Errorf("Initials() = %q, want %q", got, tt.initials)
+			}
+			if got := n.Abbreviated(); got != tt.abbreviated {
+				t.Errorf("Abbreviated() = %q, want %q", got, tt.abbreviated)
+			}
+			if got := n.Key(); got != tt.key {
+				t.Errorf("Key() = %q, want %q", got, tt.key)
+			}
+			if got := n.QueryVariants(); !slices.Equal(got, tt.variants) {
+				t.Errorf("QueryVariants() = %q, want %q", got, tt.variants)
+			}
+		})
+	}
+}
+
+func TestPersonNameMatches(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Geoffrey E. Hinton", "G. Hinton", true},
+		{"Geoffrey E. Hinton", "Hinton, G. E.", true},
+		{"Geoffrey E. Hinton", "Geoffrey Hinton", true},
+		{"Geoffrey E. Hinton", "HINTON", true},
+		{"G. E. Hinton", "G. J. Hinton", false},
+		{"Geoffrey Hinton", "Gregory Hinton", false},
+		{"Geoffrey Hinton", "G. Hinton", true},
+		{"Geoffrey Hinton", "J. Hinton", false},
+		{`Erwin Schr\"odinger`, "E. Schrodinger", true},
+		{"Jan van der Berg", "J. Berg", true},
+		{"Jan van der Berg", "J. van den Berg", true},
+		{"Jean-Pierre Serre", "J.-P. Serre", true},
+		{"Jean-Pierre Serre", "J. Serre", true},
+		{"Jean-Pierre Serre", "Jean-Paul Serre", false},
+		{"Иван Иванов", "И. Иванов", true},
+		{"Иван Иванов", "Игорь Иванов", false},
+		{"Иван Иванов", "П. Иванов", false},
+		{"Ли Вэй", "Лю Вэй", false},
+		{"Ada Smith", "Alan Smith", false},
+		{"Ada Smith", "Ada Smyth", false},
+		{"ATLAS Collaboration", "Ada Smith", false},
+		{"", "Ada Smith", false},
+	}
+	for _, tt := range tests {
+		a, b := ParseName(tt.a), ParseName(tt.b)
+		if got := a.Matches(b); got != tt.want {
+			t.Errorf("%q.Matches(%q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+		if got := b.Matches(a); got != tt.want {
+			t.Errorf("%q.Matches(%q) = %v, want %v", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := map[string]string{
+		`Schr\"odinger`:   "schrodinger",
+		"Schrödinger":     "schrodinger",
+		"SCHRODINGER":     "schrodinger",
+		`Erd\H{o}s`:       "erdos",
+		`Gau\ss`:          "gauss",
+		"Łukasz  Nowak":   "lukasz nowak",
+		`\v{S}ilhav\'y`:   "silhavy",
+		"Ørsted":          "orsted",
+		`\c{C}etin \"Uz`:  "cetin uz",
+		"Jean-Pierre":     "jean-pierre",
+		`No\"{\i}l Smith`: "noil smith",
+	}
+	for in, want := range tests {
+		if got := NormalizeName(in); got != want {
+			t.Errorf("NormalizeName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSearchQuery_AuthorName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Geoffrey E. Hinton", "au:Hinton_G"},
+		{"Hinton, G.", "au:Hinton_G"},
+		{"Maria del Maestro", "(au:del_Maestro_M OR au:Maestro_M)"},
+		{`Erwin Schr\"odinger`, "(au:Schrödinger_E OR au:Schrodinger_E)"},
+		{"", "au:"},
+	}
+	for _, tt := range tests {
+		if got := NewSearchQuery().AuthorName(tt.name).String(); got != tt.want {
+			t.Errorf("AuthorName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	got := NewSearchQuery().AuthorName("Maria del Maestro").And().Category("cs.LG").String()
+	if want := "(au:del_Maestro_M OR au:Maestro_M) AND cat:cs.LG"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,357 @@
+// Package oaipmh harvests arXiv metadata in bulk over the Open Archives
+// Initiative Protocol for Metadata Harvesting (OAI-PMH).
+//
+// The search API is meant for queries, not for copying large parts of the
+// archive. OAI-PMH serves every record, can be filtered by set and by the
+// date records last changed, and pages through results with resumption
+// tokens:
+//
+//	client := oaipmh.NewClient()
+//	records := client.ListRecords(oaipmh.ListOptions{
+//		MetadataPrefix: oaipmh.FormatArXiv,
+//		Set:            "cs",
+//		From:           time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+//	})
+//	for record := range records.All(ctx) {
+//		fmt.Println(record.Entry.Title, record.License)
+//	}
+//	if err := records.Err(); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// Records are mapped to arxiv.EntryMetadata, with the fields the Atom API
+// does not provide, such as the license and submitter, alongside.
+package oaipmh
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// DefaultBaseURL is arXiv's OAI-PMH endpoint.
+const DefaultBaseURL = "https://oaipmh.arxiv.org/oai"
+
+// DefaultMaxRetryAfter is the default number of times a request answered with
+// 503 Service Unavailable and a Retry-After header is repeated.
+const DefaultMaxRetryAfter = 5
+
+// Metadata formats served by arXiv.
+const (
+	FormatArXiv    = "arXiv"    // arXiv's own format, with structured author names and the license
+	FormatArXivRaw = "arXivRaw" // The metadata as submitted, with the submitter and version history
+	FormatDC       = "oai_dc"   // Simple Dublin Core
+)
+
+// dateFormat is the granularity of datestamps in arXiv's repository.
+const dateFormat = "2006-01-02"
+
+// Error is an error reported by the repository in an OAI-PMH response, such
+// as "badArgument" or "idDoesNotExist".
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return "oaipmh: " + e.Code
+	}
+	return fmt.Sprintf("oaipmh: %s: %s", e.Code, e.Message)
+}
+
+// Client makes OAI-PMH requests.
+type Client struct {
+	BaseURL       string        // OAI-PMH endpoint
+	MaxRetryAfter int           // Times a 503 response with Retry-After is retried (0 = DefaultMaxRetryAfter, negative = never), unless the arxiv.Client has its own retry policy
+	client        *arxiv.Client // Makes the HTTP requests
+}
+
+// ClientOption configures a Client.
+type ClientOption func(*Client)
+
+// NewClient creates an OAI-PMH client with the given options.
+func NewClient(options ...ClientOption) *Client {
+	c := &Client{
+		BaseURL:       DefaultBaseURL,
+		MaxRetryAfter: DefaultMaxRetryAfter,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	if c.client == nil {
+		// The base URL gives the requests their own shared per-host limiter.
+		c.client = arxiv.NewClient(arxiv.WithBaseURL(c.BaseURL))
+	}
+	return c
+}
+
+// WithBaseURL sets the OAI-PMH endpoint.
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.BaseURL = baseURL
+	}
+}
+
+// WithMaxRetryAfter sets how many times a request answered with 503 Service
+// Unavailable and a Retry-After header is repeated. A negative value disables
+// these retries. It has no effect when the arxiv.Client passed to WithClient
+// has a RetryConfig or RetryPolicy, which then decides alone.
+func WithMaxRetryAfter(n int) ClientOption {
+	return func(c *Client) {
+		c.MaxRetryAfter = n
+	}
+}
+
+// WithClient makes requests through an arxiv.Client, sharing its HTTP
+// client, rate limiter and retry policy. Its BaseURL is not used. If it has
+// a retry policy, that policy alone retries failed requests, including 503
+// responses with Retry-After, in place of MaxRetryAfter.
+func WithClient(client *arxiv.Client) ClientOption {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// ListOptions selects the records returned by ListRecords.
+type ListOptions struct {
+	MetadataPrefix string    // Metadata format (default FormatArXiv)
+	From           time.Time // Only records changed on or after this day (zero = no lower bound)
+	Until          time.Time // Only records changed on or before this day (zero = no upper bound)
+	Set            string    // Only records in this set, such as "cs" or "physics:hep-th"
+}
+
+func (o ListOptions) values() url.Values {
+	v := url.Values{}
+	prefix := o.MetadataPrefix
+	if prefix == "" {
+		prefix = FormatArXiv
+	}
+	v.Set("metadataPrefix", prefix)
+	if !o.From.IsZero() {
+		v.Set("from", o.From.UTC().Format(dateFormat))
+	}
+	if !o.Until.IsZero() {
+		v.Set("until", o.Until.UTC().Format(dateFormat))
+	}
+	if o.Set != "" {
+		v.Set("set", o.Set)
+	}
+	return v
+}
+
+// Page is one response to a ListRecords request.
+type Page struct {
+	Records          []Record
+	ResumptionToken  string // Token requesting the next page; empty on the last page
+	CompleteListSize int    // Number of records in the whole list, if reported
+	Cursor           int    // Position of the first record of the page in the list, if reported
+}
+
+// ListRecordsPage requests one page of records. An empty token requests the
+// first page; otherwise the token from the previous page is sent and opts are
+// ignored, as the protocol requires. A list without matching records is an
+// empty page, not an error.
+func (c *Client) ListRecordsPage(ctx context.Context, opts ListOptions, token string) (Page, error) {
+	v := url.Values{"verb": {"ListRecords"}}
+	if token != "" {
+		v.Set("resumptionToken", token)
+	} else {
+		for key, values := range opts.values() {
+			v[key] = values
+		}
+	}
+
+	var response oaiResponse
+	err := c.do(ctx, v, &response)
+	if e, ok := err.(*Error); ok && e.Code == "noRecordsMatch" {
+		return Page{}, nil
+	}
+	if err != nil {
+		return Page{}, err
+	}
+
+	list := response.ListRecords
+	page := Page{
+		ResumptionToken:  strings.TrimSpace(list.ResumptionToken.Token),
+		CompleteListSize: list.ResumptionToken.CompleteListSize,
+		Cursor:           list.ResumptionToken.Cursor,
+	}
+	for _, r := range list.Records {
+		page.Records = append(page.Records, r.record())
+	}
+	return page, nil
+}
+
+// Records iterates over the records of a ListRecords request, following
+// resumption tokens.
+type Records struct {
+	client *Client
+	opts   ListOptions
+	token  string
+	err    error
+}
+
+// ListRecords returns the records selected by opts. No requests are made
+// until the records are iterated.
+func (c *Client) ListRecords(opts ListOptions) *Records {
+	return &Records{client: c, opts: opts}
+}
+
+// Resume continues a list from a resumption token saved from an earlier
+// Records, for example after the program was restarted.
+func (c *Client) Resume(opts ListOptions, token string) *Records {
+	return &Records{client: c, opts: opts, token: token}
+}
+
+// All returns an iterator over the records. Iteration stops at the end of
+// the list or on an error; Err reports which.
+func (r *Records) All(ctx context.Context) iter.Seq[Record] {
+	return func(yield func(Record) bool) {
+		for {
+			page, err := r.client.ListRecordsPage(ctx, r.opts, r.token)
+			if err != nil {
+				r.err = err
+				return
+			}
+			for _, record := range page.Records {
+				if !yield(record) {
+					return
+				}
+			}
+			if page.ResumptionToken == "" {
+				r.token = ""
+				return
+			}
+			r.token = page.ResumptionToken
+		}
+	}
+}
+
+// Err returns the error that stopped iteration, if any.
+func (r *Records) Err() error {
+	return r.err
+}
+
+// Token returns the resumption token of the next page to fetch, or "" once
+// the list is complete. Saving it lets an interrupted harvest continue with
+// Client.Resume.
+func (r *Records) Token() string {
+	return r.token
+}
+
+// GetRecord returns a single record in the given metadata format (default
+// FormatArXiv). The identifier may be a full OAI identifier such as
+// "oai:arXiv.org:2408.03982" or just the arXiv ID.
+func (c *Client) GetRecord(ctx context.Context, identifier, metadataPrefix string) (Record, error) {
+	if !strings.HasPrefix(identifier, "oai:") {
+		identifier = "oai:arXiv.org:" + arxiv.BaseID(identifier)
+	}
+	if metadataPrefix == "" {
+		metadataPrefix = FormatArXiv
+	}
+	v := url.Values{
+		"verb":           {"GetRecord"},
+		"identifier":     {identifier},
+		"metadataPrefix": {metadataPrefix},
+	}
+	var response oaiResponse
+	if err := c.do(ctx, v, &response); err != nil {
+		return Record{}, err
+	}
+	if len(response.GetRecord.Records) == 0 {
+		return Record{}, fmt.Errorf("oaipmh: no record in GetRecord response for %s", identifier)
+	}
+	return response.GetRecord.Records[0].record(), nil
+}
+
+// Set is a set records can be selected by, such as an archive or a
+// subject class.
+type Set struct {
+	Spec string // Value for ListOptions.Set, such as "physics:hep-th"
+	Name string // Human-readable name
+}
+
+// ListSets returns all sets of the repository.
+func (c *Client) ListSets(ctx context.Context) ([]Set, error) {
+	var sets []Set
+	v := url.Values{"verb": {"ListSets"}}
+	for {
+		var response oaiResponse
+		if err := c.do(ctx, v, &response); err != nil {
+			return nil, err
+		}
+		for _, s := range response.ListSets.Sets {
+			sets = append(sets, Set{Spec: strings.TrimSpace(s.Spec), Name: strings.TrimSpace(s.Name)})
+		}
+		token := strings.TrimSpace(response.ListSets.ResumptionToken.Token)
+		if token == "" {
+			return sets, nil
+		}
+		v = url.Values{"verb": {"ListSets"}, "resumptionToken": {token}}
+	}
+}
+
+// do makes a request with the given query parameters and decodes the
+// response into dst, returning the OAI-PMH error it reports, if any.
+func (c *Client) do(ctx context.Context, v url.Values, dst *oaiResponse) error {
+	requestURL := c.BaseURL + "?" + v.Encode()
+	maxRetries := c.MaxRetryAfter
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetryAfter
+	}
+	if c.client.RetryPolicy != nil || c.client.RetryConfig != nil {
+		// The client's retry policy already decides, and waits out
+		// Retry-After itself; retrying here too would multiply attempts.
+		maxRetries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		response, err := c.client.Get(ctx, requestURL)
+		if err != nil {
+			return err
+		}
+		if attempt < maxRetries {
+			if wait, ok := arxiv.RetryAfter(response, time.Now()); ok && response.StatusCode == http.StatusServiceUnavailable {
+				response.Body.Close()
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+					continue
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				}
+			}
+		}
+
+		err = decodeResponse(response, dst)
+		response.Body.Close()
+		return err
+	}
+}
+
+func decodeResponse(response *http.Response, dst *oaiResponse) error {
+	if response.StatusCode != http.StatusOK {
+		// Repositories report protocol errors with 200 OK, so any other
+		// status is a transport failure.
+		io.Copy(io.Discard, io.LimitReader(response.Body, 1<<16))
+		return fmt.Errorf("oaipmh: %s", response.Status)
+	}
+	if err := xml.NewDecoder(response.Body).Decode(dst); err != nil {
+		return fmt.Errorf("oaipmh: parsing response: %w", err)
+	}
+	if len(dst.Errors) > 0 {
+		e := dst.Errors[0]
+		return &Error{Code: e.Code, Message: strings.TrimSpace(e.Message)}
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package oaipmh
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// fixtureServer answers OAI-PMH requests with the files in test_data, chosen
+// by the verb and arguments of each request. The first unavailable requests
+// are answered with 503 and Retry-After: 0. It records the query of every
+// request.
+func fixtureServer(t *testing.T, unavailable int) (*Client, *[]url.Values) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		mu.Lock()
+		requests = append(requests, q)
+		n := len(requests)
+		mu.Unlock()
+		if n <= unavailable {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "Retry after 0 seconds", http.StatusServiceUnavailable)
+			return
+		}
+
+		var file string
+		switch q.Get("verb") {
+		case "ListRecords":
+			switch {
+			case q.Get("resumptionToken") == "6977293|1001":
+				file = "list-records-2.xml"
+			case q.Get("set") == "cs":
+				file = "list-records-1.xml"
+			default:
+				file = "error-no-records.xml"
+			}
+		case "GetRecord":
+			switch {
+			case q.Get("identifier") != "oai:arXiv.org:0704.0001":
+				file = "error-bad-id.xml"
+			case q.Get("metadataPrefix") == FormatDC:
+				file = "get-record-dc.xml"
+			default:
+				file = "get-record-raw.xml"
+			}
+		case "ListSets":
+			file = "list-sets.xml"
+		}
+		data, err := os.ReadFile(filepath.Join("test_data", file))
+		if err != nil {
+			t.Errorf("no fixture for %s", r.URL.RawQuery)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithClient(arxiv.NewClient(arxiv.WithRateLimit(0))),
+	)
+	return client, &requests
+}
+
+func TestListRecords(t *testing.T) {
+	client, requests := fixtureServer(t, 0)
+	records := client.ListRecords(ListOptions{
+		Set:   "cs",
+		From:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Until: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+	})
+
+	var got []Record
+	for record := range records.All(context.Background()) {
+		got = append(got, record)
+	}
+	if err := records.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if records.Token() != "" {
+		t.Errorf("Token = %q after the last page", records.Token())
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d records, want 3", len(got))
+	}
+
+	first := (*requests)[0]
+	for key, want := range map[string]string{"verb": "ListRecords", "metadataPrefix": "arXiv", "set": "cs", "from": "2024-01-01", "until": "2024-01-31"} {
+		if first.Get(key) != want {
+			t.Errorf("first request %s = %q, want %q", key, first.Get(key), want)
+		}
+	}
+	second := (*requests)[1]
+	if second.Get("resumptionToken") != "6977293|1001" || second.Has("set") || second.Has("metadataPrefix") {
+		t.Errorf("second request = %v, want only the verb and resumption token", second)
+	}
+
+	r := got[0]
+	if r.Identifier != "oai:arXiv.org:0704.0002" || !r.Datestamp.Equal(time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)) ||
+		!reflect.DeepEqual(r.Sets, []string{"cs", "math"}) {
+		t.Errorf("header = %q %v %v", r.Identifier, r.Datestamp, r.Sets)
+	}
+	e := r.Entry
+	if e.ArxivID() != "0704.0002" || e.Title != "Sparsity-certifying Graph Decompositions" {
+		t.Errorf("entry = %q %q", e.ArxivID(), e.Title)
+	}
+	if names := e.AuthorNames(); !reflect.DeepEqual(names, []string{"Ileana Streinu", "Louis Theran Jr"}) {
+		t.Errorf("authors = %q", names)
+	}
+	if e.Authors[1].Affiliation != "University of Massachusetts" {
+		t.Errorf("affiliation = %q", e.Authors[1].Affiliation)
+	}
+	if e.PrimaryCategory.Term != "math.CO" || len(e.Categories) != 2 {
+		t.Errorf("categories = %v, primary %v", e.Categories, e.PrimaryCategory)
+	}
+	if !e.Published.Equal(time.Date(2007, 3, 30, 0, 0, 0, 0, time.UTC)) || !e.Updated.Equal(time.Date(2008, 12, 13, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("dates = %v, %v", e.Published, e.Updated)
+	}
+	if e.DOI != "10.1007/s00373-008-0834-4" || e.JournalReference != "Graphs Combin. 25 (2009) 219" || e.Comment != "To appear in Graphs and Combinatorics" {
+		t.Errorf("DOI, journal or comment = %q %q %q", e.DOI, e.JournalReference, e.Comment)
+	}
+	if !strings.HasPrefix(e.Summary, "We describe a new algorithm") || e.PDFUrl != "http://arxiv.org/pdf/0704.0002" {
+		t.Errorf("summary or PDF URL = %q %q", e.Summary, e.PDFUrl)
+	}
+	if r.License != "http://arxiv.org/licenses/nonexclusive-distrib/1.0/" || r.ReportNo != "UMass-CS-2007-01" {
+		t.Errorf("license or report number = %q %q", r.License, r.ReportNo)
+	}
+
+	if !got[1].Deleted || got[1].Entry.ID != "" {
+		t.Errorf("second record = %+v, want a deleted record without metadata", got[1])
+	}
+	if got[2].Entry.ArxivID() != "cs/0112017" || got[2].Entry.Title != "Logic Programming with Integrity Constraints" ||
+		!got[2].Entry.Updated.Equal(got[2].Entry.Published) {
+		t.Errorf("third record = %+v", got[2].Entry)
+	}
+}
+
+func TestListRecordsPage(t *testing.T) {
+	client, _ := fixtureServer(t, 0)
+	page, err := client.ListRecordsPage(context.Background(), ListOptions{Set: "cs"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Records) != 2 || page.ResumptionToken != "6977293|1001" || page.CompleteListSize != 3 {
+		t.Errorf("page = %d records, token %q, size %d", len(page.Records), page.ResumptionToken, page.CompleteListSize)
+	}
+
+	records := client.Resume(ListOptions{}, page.ResumptionToken)
+	var ids []string
+	for record := range records.All(context.Background()) {
+		ids = append(ids, record.Entry.ArxivID())
+	}
+	if records.Err() != nil || !reflect.DeepEqual(ids, []string{"cs/0112017"}) {
+		t.Errorf("resumed list = %v, %v", ids, records.Err())
+	}
+
+	page, err = client.ListRecordsPage(context.Background(), ListOptions{Set: "q-bio"}, "")
+	if err != nil || len(page.Records) != 0 {
+		t.Errorf("noRecordsMatch gave %d records and error %v, want an empty page", len(page.Records), err)
+	}
+}
+
+func TestGetRecord(t *testing.T) {
+	client, requests := fixtureServer(t, 0)
+	ctx := context.Background()
+
+	raw, err := client.GetRecord(ctx, "0704.0001v2", FormatArXivRaw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := (*requests)[0].Get("identifier"); got != "oai:arXiv.org:0704.0001" {
+		t.Errorf("identifier = %q", got)
+	}
+	if raw.Submitter != "Pavel Nadolsky" || len(raw.Versions) != 2 || raw.Versions[1].Version != "v2" || raw.Versions[0].Size != "424kb" {
+		t.Errorf("submitter and versions = %q %+v", raw.Submitter, raw.Versions)
+	}
+	e := raw.Entry
+	if e.ArxivID() != "0704.0001v2" {
+		t.Errorf("ID = %q, want the latest version", e.ArxivID())
+	}
+	if !e.Published.Equal(time.Date(2007, 4, 2, 19, 18, 42, 0, time.UTC)) || !e.Updated.Equal(time.Date(2007, 7, 24, 20, 10, 27, 0, time.UTC)) {
+		t.Errorf("dates = %v, %v", e.Published, e.Updated)
+	}
+	if names := e.AuthorNames(); !reflect.DeepEqual(names, []string{`C. Bal\'azs`, "E. L. Berger", "P. M. Nadolsky", "C.-P. Yuan"}) {
+		t.Errorf("authors = %q", names)
+	}
+
+	dc, err := client.GetRecord(ctx, "oai:arXiv.org:0704.0001", FormatDC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e = dc.Entry
+	if e.ArxivID() != "0704.0001" || e.Title != "Calculation of prompt diphoton production cross sections at Tevatron and LHC energies" {
+		t.Errorf("entry = %q %q", e.ArxivID(), e.Title)
+	}
+	if names := e.AuthorNames(); !reflect.DeepEqual(names, []string{"C. Balázs", "E. L. Berger"}) {
+		t.Errorf("authors = %q", names)
+	}
+	if e.Comment != "37 pages, 15 figures" || !strings.HasPrefix(e.Summary, "A fully differential") || e.DOI != "10.1103/PhysRevD.76.013009" {
+		t.Errorf("comment, summary or DOI = %q %q %q", e.Comment, e.Summary, e.DOI)
+	}
+	if dc.License != "http://arxiv.org/licenses/nonexclusive-distrib/1.0/" {
+		t.Errorf("license = %q", dc.License)
+	}
+
+	_, err = client.GetRecord(ctx, "9999.99999", "")
+	var oaiErr *Error
+	if !errors.As(err, &oaiErr) || oaiErr.Code != "idDoesNotExist" {
+		t.Errorf("GetRecord of unknown ID error = %v, want idDoesNotExist", err)
+	}
+}
+
+func TestListSets(t *testing.T) {
+	client, _ := fixtureServer(t, 0)
+	sets, err := client.ListSets(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Set{{"cs", "Computer Science"}, {"math", "Mathematics"}, {"physics:hep-th", "High Energy Physics - Theory"}}
+	if !reflect.DeepEqual(sets, want) {
+		t.Errorf("ListSets = %v, want %v", sets, want)
+	}
+}
+
+func TestRetryAfter503(t *testing.T) {
+	client, requests := fixtureServer(t, 2)
+	if _, err := client.ListSets(context.Background()); err != nil {
+		t.Fatalf("ListSets after two 503 responses: %v", err)
+	}
+	if len(*requests) != 3 {
+		t.Errorf("made %d requests, want 3", len(*requests))
+	}
+
+	client, _ = fixtureServer(t, 2)
+	client.MaxRetryAfter = 1
+	if _, err := client.ListSets(context.Background()); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("ListSets with one retry error = %v, want 503", err)
+	}
+
+	// A client with its own retry policy is the only layer retrying.
+	client, requests = fixtureServer(t, 5)
+	client.client = arxiv.NewClient(arxiv.WithRateLimit(0), arxiv.WithRetry(arxiv.RetryConfig{MaxAttempts: 2}))
+	if _, err := client.ListSets(context.Background()); err == nil {
+		t.Error("ListSets succeeded despite five 503 responses")
+	}
+	if len(*requests) != 2 {
+		t.Errorf("made %d requests, want the client's 2 attempts", len(*requests))
+	}
+}
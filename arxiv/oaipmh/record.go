@@ -0,0 +1,323 @@
+package oaipmh
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// Record is a harvested metadata record.
+type Record struct {
+	Identifier string              // OAI identifier, such as "oai:arXiv.org:2408.03982"
+	Datestamp  time.Time           // Day the record last changed
+	Sets       []string            // Sets the record belongs to
+	Deleted    bool                // Whether the record was withdrawn; deleted records have no metadata
+	Entry      arxiv.EntryMetadata // Metadata in the form returned by the search API
+	License    string              // URL of the license the paper is distributed under
+	Submitter  string              // Name of the submitter (arXivRaw only)
+	Versions   []Version           // Version history, oldest first (arXivRaw only)
+	ReportNo   string              // Report number assigned by the author's institution
+}
+
+// Version is one version of a paper in the arXivRaw format.
+type Version struct {
+	Version    string    // Version label, such as "v2"
+	Date       time.Time // Time the version was submitted
+	Size       string    // Size of the submission as reported by arXiv, such as "123kb"
+	SourceType string    // Type of the source files
+}
+
+// oaiResponse is the envelope of every OAI-PMH response.
+type oaiResponse struct {
+	Errors []struct {
+		Code    string `xml:"code,attr"`
+		Message string `xml:",chardata"`
+	} `xml:"error"`
+	GetRecord struct {
+		Records []xmlRecord `xml:"record"`
+	} `xml:"GetRecord"`
+	ListRecords struct {
+		Records         []xmlRecord        `xml:"record"`
+		ResumptionToken xmlResumptionToken `xml:"resumptionToken"`
+	} `xml:"ListRecords"`
+	ListSets struct {
+		Sets []struct {
+			Spec string `xml:"setSpec"`
+			Name string `xml:"setName"`
+		} `xml:"set"`
+		ResumptionToken xmlResumptionToken `xml:"resumptionToken"`
+	} `xml:"ListSets"`
+}
+
+type xmlResumptionToken struct {
+	Token            string `xml:",chardata"`
+	CompleteListSize int    `xml:"completeListSize,attr"`
+	Cursor           int    `xml:"cursor,attr"`
+}
+
+type xmlRecord struct {
+	Header struct {
+		Status     string   `xml:"status,attr"`
+		Identifier string   `xml:"identifier"`
+		Datestamp  string   `xml:"datestamp"`
+		SetSpecs   []string `xml:"setSpec"`
+	} `xml:"header"`
+	Metadata struct {
+		ArXiv    *arXivMetadata    `xml:"http://arxiv.org/OAI/arXiv/ arXiv"`
+		ArXivRaw *arXivRawMetadata `xml:"http://arxiv.org/OAI/arXivRaw/ arXivRaw"`
+		DC       *dcMetadata       `xml:"http://www.openarchives.org/OAI/2.0/oai_dc/ dc"`
+	} `xml:"metadata"`
+}
+
+// arXivMetadata is a record in the arXiv format.
+type arXivMetadata struct {
+	ID      string `xml:"id"`
+	Created string `xml:"created"`
+	Updated string `xml:"updated"`
+	Authors []struct {
+		KeyName      string   `xml:"keyname"`
+		ForeNames    string   `xml:"forenames"`
+		Suffix       string   `xml:"suffix"`
+		Affiliations []string `xml:"affiliation"`
+	} `xml:"authors>author"`
+	Title      string `xml:"title"`
+	Categories string `xml:"categories"`
+	Comments   string `xml:"comments"`
+	ReportNo   string `xml:"report-no"`
+	JournalRef string `xml:"journal-ref"`
+	DOI        string `xml:"doi"`
+	License    string `xml:"license"`
+	Abstract   string `xml:"abstract"`
+}
+
+// arXivRawMetadata is a record in the arXivRaw format.
+type arXivRawMetadata struct {
+	ID        string `xml:"id"`
+	Submitter string `xml:"submitter"`
+	Versions  []struct {
+		Version    string `xml:"version,attr"`
+		Date       string `xml:"date"`
+		Size       string `xml:"size"`
+		SourceType string `xml:"source_type"`
+	} `xml:"version"`
+	Title      string `xml:"title"`
+	Authors    string `xml:"authors"`
+	Categories string `xml:"categories"`
+	Comments   string `xml:"comments"`
+	ReportNo   string `xml:"report-no"`
+	JournalRef string `xml:"journal-ref"`
+	DOI        string `xml:"doi"`
+	License    string `xml:"license"`
+	Abstract   string `xml:"abstract"`
+}
+
+// dcMetadata is a record in the oai_dc format.
+type dcMetadata struct {
+	Titles       []string `xml:"title"`
+	Creators     []string `xml:"creator"`
+	Subjects     []string `xml:"subject"`
+	Descriptions []string `xml:"description"`
+	Dates        []string `xml:"date"`
+	Identifiers  []string `xml:"identifier"`
+	Rights       []string `xml:"rights"`
+}
+
+// record maps a parsed record to a Record.
+func (r xmlRecord) record() Record {
+	record := Record{
+		Identifier: strings.TrimSpace(r.Header.Identifier),
+		Datestamp:  parseDate(r.Header.Datestamp),
+		Deleted:    r.Header.Status == "deleted",
+	}
+	for _, set := range r.Header.SetSpecs {
+		record.Sets = append(record.Sets, strings.TrimSpace(set))
+	}
+
+	switch m := r.Metadata; {
+	case m.ArXiv != nil:
+		m.ArXiv.fill(&record)
+	case m.ArXivRaw != nil:
+		m.ArXivRaw.fill(&record)
+	case m.DC != nil:
+		m.DC.fill(&record)
+	}
+	return record
+}
+
+func (m *arXivMetadata) fill(record *Record) {
+	id := strings.TrimSpace(m.ID)
+	entry := baseEntry(id)
+	entry.Title = clean(m.Title)
+	entry.Summary = strings.TrimSpace(m.Abstract)
+	entry.Published = parseDate(m.Created)
+	entry.Updated = parseDate(m.Updated)
+	if entry.Updated.IsZero() {
+		entry.Updated = entry.Published
+	}
+	for _, a := range m.Authors {
+		name := strings.Join(strings.Fields(clean(a.ForeNames)+" "+clean(a.KeyName)+" "+clean(a.Suffix)), " ")
+		author := arxiv.Author{Name: name}
+		if len(a.Affiliations) > 0 {
+			author.Affiliation = clean(a.Affiliations[0])
+		}
+		entry.Authors = append(entry.Authors, author)
+	}
+	setCategories(&entry, m.Categories)
+	entry.Comment = clean(m.Comments)
+	entry.JournalReference = clean(m.JournalRef)
+	entry.DOI = strings.TrimSpace(m.DOI)
+
+	record.Entry = entry
+	record.License = strings.TrimSpace(m.License)
+	record.ReportNo = clean(m.ReportNo)
+}
+
+func (m *arXivRawMetadata) fill(record *Record) {
+	id := strings.TrimSpace(m.ID)
+	for _, v := range m.Versions {
+		record.Versions = append(record.Versions, Version{
+			Version:    strings.TrimSpace(v.Version),
+			Date:       parseVersionDate(v.Date),
+			Size:       strings.TrimSpace(v.Size),
+			SourceType: strings.TrimSpace(v.SourceType),
+		})
+	}
+	if n := len(record.Versions); n > 0 {
+		// The ID of the latest version, as the search API reports it
+		id += record.Versions[n-1].Version
+	}
+
+	entry := baseEntry(id)
+	entry.Title = clean(m.Title)
+	entry.Summary = strings.TrimSpace(m.Abstract)
+	if n := len(record.Versions); n > 0 {
+		entry.Published = record.Versions[0].Date
+		entry.Updated = record.Versions[n-1].Date
+	}
+	for _, name := range splitAuthors(m.Authors) {
+		entry.Authors = append(entry.Authors, arxiv.Author{Name: name})
+	}
+	setCategories(&entry, m.Categories)
+	entry.Comment = clean(m.Comments)
+	entry.JournalReference = clean(m.JournalRef)
+	entry.DOI = strings.TrimSpace(m.DOI)
+
+	record.Entry = entry
+	record.License = strings.TrimSpace(m.License)
+	record.Submitter = clean(m.Submitter)
+	record.ReportNo = clean(m.ReportNo)
+}
+
+func (m *dcMetadata) fill(record *Record) {
+	var entry arxiv.EntryMetadata
+	for _, identifier := range m.Identifiers {
+		identifier = strings.TrimSpace(identifier)
+		switch {
+		case strings.Contains(identifier, "arxiv.org/abs/") && entry.ID == "":
+			id := identifier[strings.Index(identifier, "/abs/")+len("/abs/"):]
+			entry = baseEntry(id)
+		case strings.HasPrefix(identifier, "doi:"):
+			entry.DOI = strings.TrimPrefix(identifier, "doi:")
+		}
+	}
+	if len(m.Titles) > 0 {
+		entry.Title = clean(m.Titles[0])
+	}
+	for _, creator := range m.Creators {
+		entry.Authors = append(entry.Authors, arxiv.Author{Name: invertName(clean(creator))})
+	}
+	for _, description := range m.Descriptions {
+		description = strings.TrimSpace(description)
+		if comment, ok := strings.CutPrefix(description, "Comment:"); ok {
+			entry.Comment = clean(comment)
+		} else if entry.Summary == "" {
+			entry.Summary = description
+		}
+	}
+	if len(m.Dates) > 0 {
+		entry.Published = parseDate(m.Dates[0])
+		entry.Updated = parseDate(m.Dates[len(m.Dates)-1])
+	}
+	record.Entry = entry
+	if len(m.Rights) > 0 {
+		record.License = strings.TrimSpace(m.Rights[0])
+	}
+}
+
+// baseEntry returns an entry with the ID and URLs the search API would give
+// the paper with the given arXiv ID.
+func baseEntry(id string) arxiv.EntryMetadata {
+	if id == "" {
+		return arxiv.EntryMetadata{}
+	}
+	return arxiv.EntryMetadata{
+		ID:          "http://arxiv.org/abs/" + id,
+		AbstractUrl: "http://arxiv.org/abs/" + id,
+		PDFUrl:      "http://arxiv.org/pdf/" + id,
+	}
+}
+
+// setCategories fills the categories of entry from a space-separated list
+// whose first category is the primary one.
+func setCategories(entry *arxiv.EntryMetadata, categories string) {
+	for i, term := range strings.Fields(categories) {
+		if i == 0 {
+			entry.PrimaryCategory = arxiv.Category{Term: term}
+		}
+		entry.Categories = append(entry.Categories, arxiv.Category{Term: term})
+	}
+}
+
+// splitAuthors splits an arXivRaw author list such as "A. Smith, B. Jones and
+// C. Lee" into names.
+func splitAuthors(authors string) []string {
+	authors = clean(authors)
+	var names []string
+	for _, part := range strings.Split(authors, ",") {
+		for _, name := range strings.Split(part, " and ") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// invertName turns a Dublin Core creator such as "Smith, John" into "John Smith".
+func invertName(name string) string {
+	last, first, ok := strings.Cut(name, ",")
+	if !ok {
+		return name
+	}
+	return strings.TrimSpace(strings.TrimSpace(first) + " " + strings.TrimSpace(last))
+}
+
+// clean collapses the runs of whitespace, including line breaks, that
+// metadata fields are wrapped with.
+func clean(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func parseDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(dateFormat, s); err == nil {
+		return t
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+// parseVersionDate parses the RFC 1123 dates of arXivRaw versions, such as
+// "Mon, 2 Apr 2007 19:18:42 GMT".
+func parseVersionDate(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{time.RFC1123, "Mon, 2 Jan 2006 15:04:05 MST"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
@@ -5,6 +5,8 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv/taxonomy"
 )
 
 type queryOperator string
@@ -99,13 +101,39 @@ func (q *SearchQuery) Abstract(value string) *SearchQuery {
 	return q
 }
 
-// Author adds an author search term.
+// Author adds an author search term. arXiv matches authors best in the
+// form Surname_Initial, as in "Hinton_G"; AuthorName builds it from a full
+// name.
 func (q *SearchQuery) Author(value string) *SearchQuery {
 	q.nodes = append(q.nodes, &fieldQuery{field: fieldAuthor, value: value})
 	return q
 }
 
-// Category adds a category search term.
+// AuthorName adds a search for an author by name, written in any form
+// ParseName understands, such as "Geoffrey E. Hinton" or "Hinton, G.". It
+// searches for every variant from PersonName.QueryVariants, as in
+// "au:Hinton_G" or "(au:del_Maestro_A OR au:Maestro_A)".
+func (q *SearchQuery) AuthorName(name string) *SearchQuery {
+	variants := ParseName(name).QueryVariants()
+	switch len(variants) {
+	case 0:
+		return q.Author(name)
+	case 1:
+		return q.Author(variants[0])
+	}
+	group := &groupQuery{}
+	for i, v := range variants {
+		if i > 0 {
+			group.nodes = append(group.nodes, &operatorNode{op: opOr})
+		}
+		group.nodes = append(group.nodes, &fieldQuery{field: fieldAuthor, value: v})
+	}
+	q.nodes = append(q.nodes, group)
+	return q
+}
+
+// Category adds a category search term, such as "cs.LG". Validate reports
+// categories missing from the taxonomy package.
 func (q *SearchQuery) Category(value string) *SearchQuery {
 	q.nodes = append(q.nodes, &fieldQuery{field: fieldCategory, value: value})
 	return q
@@ -176,6 +204,59 @@ func (q *SearchQuery) SubmittedBetween(start, end time.Time) *SearchQuery {
 	return q
 }
 
+// Validate returns an error if a category term of the query names no
+// category, alias or archive of arXiv's taxonomy, or is a pattern such as
+// "cs.*" that matches no category. The taxonomy is a fixed table, so a
+// category arXiv added since may fail validation while the API accepts it;
+// callers may prefer to warn rather than refuse.
+func (q *SearchQuery) Validate() error {
+	return validateNodes(q.nodes)
+}
+
+func validateNodes(nodes []queryNode) error {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *fieldQuery:
+			if n.field == fieldCategory {
+				if err := validateCategory(n.value); err != nil {
+					return err
+				}
+			}
+		case *groupQuery:
+			if err := validateNodes(n.nodes); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateCategory checks the value of a cat: term. Values holding several
+// terms, which the parser keeps together inside parentheses, are not checked.
+func validateCategory(value string) error {
+	term := strings.Trim(value, `"`)
+	if term == "" || strings.ContainsAny(term, " ()") {
+		return nil
+	}
+	if taxonomy.IsPattern(term) {
+		if len(taxonomy.Expand(term)) == 0 {
+			return fmt.Errorf("category pattern matches no category: %s", term)
+		}
+		return nil
+	}
+	if _, ok := taxonomy.LookupArchive(term); ok && term == strings.ToLower(term) {
+		return nil
+	}
+	c, ok := taxonomy.Lookup(term)
+	switch {
+	case !ok:
+		return fmt.Errorf("unknown category: %s", term)
+	case c.ID != term:
+		return fmt.Errorf("unknown category: %s (did you mean %s?)", term, c.ID)
+	}
+	return nil
+}
+
 // String encodes the SearchQuery to a string suitable for the arXiv API.
 func (q *SearchQuery) String() string {
 	var parts []string
@@ -187,7 +268,8 @@ func (q *SearchQuery) String() string {
 
 // ParseSearchQuery parses a search query string into a SearchQuery.
 // The query string should not use special characters such as + for
-// spaces or % encoded characters.
+// spaces or % encoded characters. Only the syntax is checked; call
+// Validate on the result to check categories against arXiv's taxonomy.
 func ParseSearchQuery(query string) (*SearchQuery, error) {
 	q := NewSearchQuery()
 	if query == "" {
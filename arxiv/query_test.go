@@ -552,6 +552,79 @@ func TestParseSearchQuery_Errors(t *testing.T) {
 	}
 }
 
+func TestParseSearchQuery_Categories(t *testing.T) {
+	valid := []string{
+		"cat:cs.LG",
+		`cat:"stat.ML"`,
+		"cat:hep-th",
+		"cat:cs",
+		"cat:astro-ph",
+		"cat:cs.*",
+		"cat:math.IT",
+		"cat:solv-int",
+		"ti:graph AND (cat:cs.LG OR cat:stat.ML)",
+	}
+	for _, input := range valid {
+		q, err := ParseSearchQuery(input)
+		if err != nil {
+			t.Fatalf("ParseSearchQuery(%q) error = %v", input, err)
+		}
+		if err := q.Validate(); err != nil {
+			t.Errorf("ParseSearchQuery(%q).Validate() = %v", input, err)
+		}
+	}
+
+	// Parsing only checks syntax, so categories missing from the taxonomy,
+	// such as ones arXiv added since, still parse; Validate reports them.
+	invalid := []struct {
+		input   string
+		wantErr string
+	}{
+		{"ti:neural AND cat:cs.XX", "unknown category: cs.XX"},
+		{"cat:cs.lg", "did you mean cs.LG?"},
+		{"cat:bogus.*", "category pattern matches no category: bogus.*"},
+	}
+	for _, tt := range invalid {
+		q, err := ParseSearchQuery(tt.input)
+		if err != nil {
+			t.Fatalf("ParseSearchQuery(%q) error = %v", tt.input, err)
+		}
+		if err := q.Validate(); err == nil || !contains(err.Error(), tt.wantErr) {
+			t.Errorf("ParseSearchQuery(%q).Validate() = %v, want error containing %q", tt.input, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSearchQuery_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   *SearchQuery
+		wantErr string
+	}{
+		{
+			name:  "known categories",
+			query: NewSearchQuery().Title("graph").And().Group(func(g *SearchQuery) { g.Category("cs.LG").Or().Category("math.*") }),
+		},
+		{
+			name:    "unknown category in group",
+			query:   NewSearchQuery().Title("quantum").And().Group(func(g *SearchQuery) { g.Category("cs.AI").Or().Category("physics.quant-ph") }),
+			wantErr: "unknown category: physics.quant-ph",
+		},
+		{
+			name:  "no categories",
+			query: NewSearchQuery().Author("Hinton"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.query.Validate()
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Validate() = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func contains(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
@@ -0,0 +1,229 @@
+package arxiv
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/internal/lockfile"
+	"golang.org/x/time/rate"
+)
+
+// DefaultRateLimit is the minimum interval between requests required by the
+// arXiv API terms of use: no more than one request every three seconds.
+const DefaultRateLimit = 3 * time.Second
+
+// Limiter paces requests to the arXiv API. A single Limiter may be shared by
+// any number of clients and must be safe for concurrent use.
+type Limiter interface {
+	// Wait blocks until a request may be made or ctx is done. It returns how
+	// long the caller was made to wait.
+	Wait(ctx context.Context) (time.Duration, error)
+}
+
+// LimiterStats reports how much waiting a limiter has imposed.
+type LimiterStats struct {
+	Requests  int           // Number of requests admitted
+	TotalWait time.Duration // Total time callers spent waiting
+	MaxWait   time.Duration // Longest single wait
+	LastWait  time.Duration // Wait imposed on the most recent request
+}
+
+// IntervalLimiter is a Limiter that spaces requests at least Interval apart.
+// If a lock file is set, the schedule is also shared with other processes
+// using the same file, so that separate programs on one machine together
+// stay within arXiv's rate limit.
+type IntervalLimiter struct {
+	interval time.Duration
+	lockPath string
+
+	mu    sync.Mutex
+	next  time.Time
+	stats LimiterStats
+}
+
+// NewIntervalLimiter creates a limiter that admits one request per interval.
+func NewIntervalLimiter(interval time.Duration) *IntervalLimiter {
+	return &IntervalLimiter{interval: interval}
+}
+
+// NewFileLimiter creates a limiter that admits one request per interval across
+// every process using the lock file at path. The file is created if needed and
+// holds the time of the next free slot. Cross-process locking is only
+// available on Unix systems; elsewhere the limiter behaves like an
+// IntervalLimiter.
+func NewFileLimiter(path string, interval time.Duration) *IntervalLimiter {
+	return &IntervalLimiter{interval: interval, lockPath: path}
+}
+
+// Interval returns the minimum spacing between requests.
+func (l *IntervalLimiter) Interval() time.Duration {
+	return l.interval
+}
+
+// Stats returns a snapshot of the waits imposed so far.
+func (l *IntervalLimiter) Stats() LimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
+
+// Wait implements Limiter. The next slot is reserved before sleeping, so
+// concurrent callers are queued in arrival order. A caller whose context
+// ends while waiting gives up its slot without returning it.
+func (l *IntervalLimiter) Wait(ctx context.Context) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	slot, err := l.reserve(now)
+	if err != nil {
+		l.mu.Unlock()
+		return 0, err
+	}
+	wait := max(slot.Sub(now), 0)
+	l.stats.Requests++
+	l.stats.TotalWait += wait
+	l.stats.MaxWait = max(l.stats.MaxWait, wait)
+	l.stats.LastWait = wait
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return 0, nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return wait, nil
+	case <-ctx.Done():
+		return time.Since(now), ctx.Err()
+	}
+}
+
+// reserve claims the earliest free slot at or after now and returns it.
+// The caller must hold l.mu.
+func (l *IntervalLimiter) reserve(now time.Time) (time.Time, error) {
+	if l.lockPath == "" {
+		slot := now
+		if l.next.After(slot) {
+			slot = l.next
+		}
+		l.next = slot.Add(l.interval)
+		return slot, nil
+	}
+
+	slot, err := reserveInFile(l.lockPath, now, l.interval)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("arxiv: rate limit lock file: %w", err)
+	}
+	return slot, nil
+}
+
+// reserveInFile claims the earliest free slot at or after now recorded in
+// the lock file at path, holding a lock on it so that processes sharing it
+// take turns.
+func reserveInFile(path string, now time.Time, interval time.Duration) (time.Time, error) {
+	f, err := lockfile.Lock(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer f.Close()
+
+	next, err := readNextSlot(f)
+	if err != nil {
+		return time.Time{}, err
+	}
+	slot := now
+	if next.After(slot) {
+		slot = next
+	}
+	return slot, writeNextSlot(f, slot.Add(interval))
+}
+
+// readNextSlot reads the next free slot, stored as Unix nanoseconds.
+// An empty file means no slot has been reserved yet.
+func readNextSlot(f *os.File) (time.Time, error) {
+	if _, err := f.Seek(0, 0); err != nil {
+		return time.Time{}, err
+	}
+	buf := make([]byte, 32)
+	n, err := f.Read(buf)
+	if n == 0 {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	nanos, err := strconv.ParseInt(strings.TrimSpace(string(buf[:n])), 10, 64)
+	if err != nil {
+		// A corrupt file should not wedge every process; start over.
+		return time.Time{}, nil
+	}
+	return time.Unix(0, nanos), nil
+}
+
+// writeNextSlot replaces the contents of f with the given slot.
+func writeNextSlot(f *os.File, next time.Time) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	_, err := f.WriteAt([]byte(strconv.FormatInt(next.UnixNano(), 10)), 0)
+	return err
+}
+
+// rateLimiter adapts a *rate.Limiter to the Limiter interface.
+type rateLimiter struct {
+	limiter *rate.Limiter
+}
+
+func (r rateLimiter) Wait(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	err := r.limiter.Wait(ctx)
+	return time.Since(start), err
+}
+
+var (
+	sharedLimitersMu sync.Mutex
+	sharedLimiters   = map[string]*IntervalLimiter{}
+)
+
+// SharedLimiter returns the process-wide limiter used by every client that
+// talks to the host of baseURL at DefaultRateLimit. Clients created with
+// NewClient and no rate limit options use it automatically. Limiters are
+// kept for the life of the process, one for each host, so URLs differing
+// only in path, scheme, letter case or a default port share one.
+func SharedLimiter(baseURL string) *IntervalLimiter {
+	host := limiterHost(baseURL)
+
+	sharedLimitersMu.Lock()
+	defer sharedLimitersMu.Unlock()
+	limiter, ok := sharedLimiters[host]
+	if !ok {
+		limiter = NewIntervalLimiter(DefaultRateLimit)
+		sharedLimiters[host] = limiter
+	}
+	return limiter
+}
+
+// limiterHost returns the lower-cased host of baseURL, with its port unless
+// it is the default for HTTP or HTTPS.
+func limiterHost(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return strings.ToLower(baseURL)
+	}
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host = net.JoinHostPort(host, port)
+	}
+	return host
+}
@@ -0,0 +1,146 @@
+package arxiv
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIntervalLimiter(t *testing.T) {
+	interval := 50 * time.Millisecond
+	limiter := NewIntervalLimiter(interval)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if _, err := limiter.Wait(ctx); err != nil {
+			t.Fatalf("Wait() = %v; want nil", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 3*interval {
+		t.Errorf("elapsed = %v; want at least %v", elapsed, 3*interval)
+	}
+
+	stats := limiter.Stats()
+	if stats.Requests != 4 {
+		t.Errorf("Stats().Requests = %d; want 4", stats.Requests)
+	}
+	if stats.TotalWait < 2*interval {
+		t.Errorf("Stats().TotalWait = %v; want at least %v", stats.TotalWait, 2*interval)
+	}
+	if stats.MaxWait <= 0 || stats.LastWait <= 0 {
+		t.Errorf("Stats() = %+v; want positive MaxWait and LastWait", stats)
+	}
+}
+
+func TestIntervalLimiterConcurrent(t *testing.T) {
+	interval := 20 * time.Millisecond
+	limiter := NewIntervalLimiter(interval)
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var admitted []time.Time
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := limiter.Wait(ctx); err != nil {
+				t.Errorf("Wait() = %v; want nil", err)
+			}
+			mu.Lock()
+			admitted = append(admitted, time.Now())
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	first, last := admitted[0], admitted[0]
+	for _, at := range admitted {
+		if at.Before(first) {
+			first = at
+		}
+		if at.After(last) {
+			last = at
+		}
+	}
+	if spread := last.Sub(first); spread < 4*interval {
+		t.Errorf("requests spread over %v; want at least %v", spread, 4*interval)
+	}
+}
+
+func TestIntervalLimiterContextCancel(t *testing.T) {
+	limiter := NewIntervalLimiter(1 * time.Second)
+	if _, err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() = %v; want nil", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() = %v; want context.DeadlineExceeded", err)
+	}
+}
+
+func TestFileLimiterSharesSchedule(t *testing.T) {
+	interval := 50 * time.Millisecond
+	path := filepath.Join(t.TempDir(), "arxiv.lock")
+
+	// Two limiters on the same file stand in for two processes.
+	first := NewFileLimiter(path, interval)
+	second := NewFileLimiter(path, interval)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if _, err := first.Wait(ctx); err != nil {
+			t.Fatalf("first.Wait() = %v; want nil", err)
+		}
+		if _, err := second.Wait(ctx); err != nil {
+			t.Fatalf("second.Wait() = %v; want nil", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 3*interval {
+		t.Errorf("elapsed = %v; want at least %v", elapsed, 3*interval)
+	}
+}
+
+func TestNewClientDefaultRateLimit(t *testing.T) {
+	client := NewClient()
+	if client.RateLimit != DefaultRateLimit {
+		t.Errorf("Client.RateLimit = %v; want %v", client.RateLimit, DefaultRateLimit)
+	}
+
+	other := NewClient()
+	if client.Limiter() != other.Limiter() {
+		t.Error("clients with default settings should share a limiter")
+	}
+	if client.Limiter() != Limiter(SharedLimiter(client.BaseURL)) {
+		t.Error("default limiter should be the shared limiter for the base URL")
+	}
+
+	mirror := NewClient(WithBaseURL("http://localhost:8080/api/query"))
+	if mirror.Limiter() == client.Limiter() {
+		t.Error("clients for different hosts should not share a limiter")
+	}
+	for _, url := range []string{"https://EXPORT.arxiv.org/api/query", "http://export.arxiv.org:80/oai2", "export.arxiv.org"} {
+		if SharedLimiter(url) != SharedLimiter(client.BaseURL) {
+			t.Errorf("SharedLimiter(%q) differs from the limiter for %q", url, client.BaseURL)
+		}
+	}
+
+	unlimited := NewClient(WithRateLimit(0))
+	if unlimited.Limiter() != nil {
+		t.Error("WithRateLimit(0) should disable rate limiting")
+	}
+
+	shared := NewIntervalLimiter(time.Second)
+	a := NewClient(WithLimiter(shared), WithRateLimit(5*time.Second))
+	b := NewClient(WithLimiter(shared))
+	if a.Limiter() != Limiter(shared) || b.Limiter() != Limiter(shared) {
+		t.Error("WithLimiter should set the limiter used by the client")
+	}
+}
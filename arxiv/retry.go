@@ -0,0 +1,232 @@
+package arxiv
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrEmptyPage is passed to the RetryPolicy when arXiv responds with a page
+// that contains no entries even though the reported total says there should
+// be some. The API does this intermittently, and retrying the same request
+// usually succeeds. If the page is not retried, Search returns it without an
+// error, as it would any other page.
+var ErrEmptyPage = errors.New("arxiv: unexpected empty page")
+
+// ErrMalformedResponse is returned when a response body cannot be parsed as an
+// arXiv Atom feed. The underlying parse error is wrapped alongside it.
+var ErrMalformedResponse = errors.New("arxiv: malformed response")
+
+// RetryPolicy decides whether a failed request is retried and how long to wait
+// before trying again. Either err or response may be nil. When the response
+// was received, its body may already have been consumed.
+type RetryPolicy interface {
+	// ShouldRetry reports whether another attempt should be made after the
+	// given attempt (starting at 1) failed.
+	ShouldRetry(attempt int, err error, response *http.Response) bool
+	// Delay returns how long to wait before the attempt following the given one.
+	Delay(attempt int, err error, response *http.Response) time.Duration
+}
+
+// DefaultRetryPolicy is the RetryPolicy used when a client is configured with
+// a RetryConfig but no explicit policy. It retries transient network errors,
+// the HTTP status codes 408, 429, 500, 502, 503 and 504, malformed responses,
+// empty pages and incomplete downloads, backing off exponentially with
+// jitter. A Retry-After header on a 429 or 503 response takes precedence over
+// the computed backoff.
+type DefaultRetryPolicy struct {
+	Config RetryConfig
+}
+
+// ShouldRetry implements RetryPolicy.
+func (p DefaultRetryPolicy) ShouldRetry(attempt int, err error, response *http.Response) bool {
+	return attempt < p.Config.MaxAttempts && isRetryableError(err, response)
+}
+
+// Delay implements RetryPolicy.
+func (p DefaultRetryPolicy) Delay(attempt int, err error, response *http.Response) time.Duration {
+	if wait, ok := RetryAfter(response, time.Now()); ok {
+		return wait
+	}
+	return calculateBackoff(attempt, &p.Config)
+}
+
+// WithRetryPolicy sets the policy used to decide whether and when failed
+// requests are retried. It takes precedence over the policy derived from
+// WithRetry, although MaxElapsedTime from the RetryConfig is still honored.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.RetryPolicy = policy
+	}
+}
+
+// retryPolicy returns the policy in effect for the client, or nil if failed
+// requests should not be retried.
+func (c *Client) retryPolicy() RetryPolicy {
+	if c.RetryPolicy != nil {
+		return c.RetryPolicy
+	}
+	if c.RetryConfig != nil {
+		return DefaultRetryPolicy{Config: *c.RetryConfig}
+	}
+	return nil
+}
+
+// retryBudget returns the total time a single call may spend retrying.
+func (c *Client) retryBudget() time.Duration {
+	if c.RetryConfig == nil {
+		return 0
+	}
+	return c.RetryConfig.MaxElapsedTime
+}
+
+// withRetry runs op until it succeeds, the retry policy gives up, the retry
+// budget would be exceeded or ctx is done. An attempt has failed if it returns
+// an error or a response with a status other than 200 OK. The result of an
+// attempt that is retried is passed to discard, if set, before waiting.
+func withRetry[T any](ctx context.Context, c *Client, op func(ctx context.Context) (T, *http.Response, error), discard func(T)) (T, error) {
+	policy := c.retryPolicy()
+	budget := c.retryBudget()
+	started := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		result, response, err := op(ctx)
+		if err == nil && (response == nil || response.StatusCode == http.StatusOK) {
+			return result, nil
+		}
+		if policy == nil || !policy.ShouldRetry(attempt, err, response) {
+			return result, err
+		}
+
+		delay := policy.Delay(attempt, err, response)
+		if budget > 0 && time.Since(started)+delay > budget {
+			return result, err
+		}
+		if discard != nil {
+			discard(result)
+		}
+
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+				// Continue to next attempt
+			case <-ctx.Done():
+				timer.Stop()
+				var zero T
+				return zero, ctx.Err()
+			}
+		}
+	}
+}
+
+// isRetryableError determines if an error is retryable.
+// Retryable errors include temporary network failures, specific HTTP status
+// codes, and malformed or empty pages returned with a 200 OK status.
+func isRetryableError(err error, response *http.Response) bool {
+	if response != nil && response.StatusCode != http.StatusOK {
+		// Retry on specific HTTP status codes
+		switch response.StatusCode {
+		case http.StatusTooManyRequests, // 429
+			http.StatusRequestTimeout,      // 408
+			http.StatusInternalServerError, // 500
+			http.StatusBadGateway,          // 502
+			http.StatusServiceUnavailable,  // 503
+			http.StatusGatewayTimeout:      // 504
+			return true
+		}
+		return false
+	}
+
+	if err != nil {
+		// Retry on timeout or temporary network errors
+		if errors.Is(err, context.DeadlineExceeded) {
+			return true
+		}
+		// Check for temporary network errors
+		if urlErr, ok := err.(*url.Error); ok && urlErr.Temporary() {
+			return true
+		}
+		// Retry when arXiv returns a truncated or empty page
+		if errors.Is(err, ErrMalformedResponse) || errors.Is(err, ErrEmptyPage) {
+			return true
+		}
+		// Retry downloads cut short, resuming where they stopped
+		if errors.Is(err, ErrIncompleteDownload) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RetryAfter returns the wait requested by the Retry-After header of a 429 or
+// 503 response, relative to now. The header may hold either a number of
+// seconds or an HTTP date. It reports false if response is nil, has another
+// status, or has no valid header.
+func RetryAfter(response *http.Response, now time.Time) (time.Duration, bool) {
+	if response == nil {
+		return 0, false
+	}
+	if response.StatusCode != http.StatusTooManyRequests && response.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+	value := response.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// jitterMu guards RetryConfig.Rand, since a *rand.Rand is not safe for
+// concurrent use and a client may be shared between goroutines.
+var jitterMu sync.Mutex
+
+// calculateBackoff calculates the next backoff interval with jitter.
+func calculateBackoff(attempt int, config *RetryConfig) time.Duration {
+	if config == nil || attempt <= 0 {
+		return 0
+	}
+
+	// Calculate exponential backoff
+	backoff := float64(config.InitialInterval) * math.Pow(config.Multiplier, float64(attempt-1))
+
+	// Cap at max interval
+	if backoff > float64(config.MaxInterval) {
+		backoff = float64(config.MaxInterval)
+	}
+
+	// Add jitter (±10% randomization unless configured otherwise)
+	fraction := config.Jitter
+	if fraction == 0 {
+		fraction = 0.1
+	}
+	if fraction > 0 {
+		var r float64
+		if config.Rand != nil {
+			jitterMu.Lock()
+			r = config.Rand.Float64()
+			jitterMu.Unlock()
+		} else {
+			r = rand.Float64()
+		}
+		backoff += fraction * backoff * (2*r - 1)
+	}
+
+	return time.Duration(backoff)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
 	"net/http"
 	"net/http/httptest"
 	"sync/atomic"
@@ -399,3 +400,320 @@ const testXMLResponse = `<?xml version="1.0" encoding="UTF-8"?>
     <category term="cs.AI" scheme="http://arxiv.org/schemas/atom"/>
   </entry>
 </feed>`
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		response *http.Response
+		want     time.Duration
+		wantOK   bool
+	}{
+		{
+			name:     "nil response",
+			response: nil,
+		},
+		{
+			name:     "seconds on 503",
+			response: responseWithRetryAfter(http.StatusServiceUnavailable, "5"),
+			want:     5 * time.Second,
+			wantOK:   true,
+		},
+		{
+			name:     "seconds on 429",
+			response: responseWithRetryAfter(http.StatusTooManyRequests, "2"),
+			want:     2 * time.Second,
+			wantOK:   true,
+		},
+		{
+			name:     "HTTP date on 503",
+			response: responseWithRetryAfter(http.StatusServiceUnavailable, now.Add(10*time.Second).Format(http.TimeFormat)),
+			want:     10 * time.Second,
+			wantOK:   true,
+		},
+		{
+			name:     "HTTP date in the past",
+			response: responseWithRetryAfter(http.StatusServiceUnavailable, now.Add(-10*time.Second).Format(http.TimeFormat)),
+			want:     0,
+			wantOK:   true,
+		},
+		{
+			name:     "ignored on 500",
+			response: responseWithRetryAfter(http.StatusInternalServerError, "5"),
+		},
+		{
+			name:     "invalid value",
+			response: responseWithRetryAfter(http.StatusServiceUnavailable, "soon"),
+		},
+		{
+			name:     "missing header",
+			response: &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := RetryAfter(tt.response, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("RetryAfter() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestIsRetryableErrorParseFailures(t *testing.T) {
+	ok := &http.Response{StatusCode: http.StatusOK}
+	badRequest := &http.Response{StatusCode: http.StatusBadRequest}
+
+	if !isRetryableError(ErrEmptyPage, ok) {
+		t.Error("isRetryableError(ErrEmptyPage, 200) = false, want true")
+	}
+	if !isRetryableError(fmt.Errorf("%w: EOF", ErrMalformedResponse), ok) {
+		t.Error("isRetryableError(ErrMalformedResponse, 200) = false, want true")
+	}
+	if isRetryableError(fmt.Errorf("%w: EOF", ErrMalformedResponse), badRequest) {
+		t.Error("isRetryableError(ErrMalformedResponse, 400) = true, want false")
+	}
+}
+
+func TestCalculateBackoffSeededJitter(t *testing.T) {
+	newConfig := func() *RetryConfig {
+		return &RetryConfig{
+			InitialInterval: 1 * time.Second,
+			MaxInterval:     30 * time.Second,
+			Multiplier:      2.0,
+			Jitter:          0.5,
+			Rand:            rand.New(rand.NewPCG(1, 2)),
+		}
+	}
+
+	first, second := newConfig(), newConfig()
+	for attempt := 1; attempt <= 5; attempt++ {
+		a := calculateBackoff(attempt, first)
+		b := calculateBackoff(attempt, second)
+		if a != b {
+			t.Errorf("attempt %d: seeded backoffs differ: %v != %v", attempt, a, b)
+		}
+	}
+
+	noJitter := &RetryConfig{
+		InitialInterval: 1 * time.Second,
+		MaxInterval:     30 * time.Second,
+		Multiplier:      2.0,
+		Jitter:          -1,
+	}
+	if got := calculateBackoff(3, noJitter); got != 4*time.Second {
+		t.Errorf("calculateBackoff() without jitter = %v, want 4s", got)
+	}
+}
+
+func TestClientRetryPolicy(t *testing.T) {
+	t.Run("honors Retry-After", func(t *testing.T) {
+		var attemptCount int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attemptCount, 1) == 1 {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, testXMLResponse)
+		}))
+		defer server.Close()
+
+		client := NewClient(
+			WithBaseURL(server.URL),
+			WithRateLimit(0),
+			WithRetry(RetryConfig{
+				MaxAttempts:     2,
+				InitialInterval: 1 * time.Millisecond,
+				MaxInterval:     1 * time.Millisecond,
+			}),
+		)
+
+		start := time.Now()
+		_, err := client.Search(context.Background(), SearchParams{Query: "test"})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if elapsed := time.Since(start); elapsed < 1*time.Second {
+			t.Errorf("Search() returned after %v, want at least the 1s Retry-After", elapsed)
+		}
+	})
+
+	t.Run("retries empty pages", func(t *testing.T) {
+		var attemptCount int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			if atomic.AddInt32(&attemptCount, 1) == 1 {
+				fmt.Fprint(w, testEmptyPageResponse)
+				return
+			}
+			fmt.Fprint(w, testXMLResponse)
+		}))
+		defer server.Close()
+
+		client := NewClient(
+			WithBaseURL(server.URL),
+			WithRateLimit(0),
+			WithRetry(RetryConfig{MaxAttempts: 3, InitialInterval: 1 * time.Millisecond}),
+		)
+
+		response, err := client.Search(context.Background(), SearchParams{Query: "test"})
+		if err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if len(response.Entries) != 1 {
+			t.Errorf("Expected 1 entry, got %d", len(response.Entries))
+		}
+		if atomic.LoadInt32(&attemptCount) != 2 {
+			t.Errorf("Expected 2 attempts, got %d", attemptCount)
+		}
+	})
+
+	t.Run("returns empty page without retry", func(t *testing.T) {
+		var attemptCount int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attemptCount, 1)
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, testEmptyPageResponse)
+		}))
+		defer server.Close()
+
+		client := NewClient(WithBaseURL(server.URL), WithRateLimit(0))
+		response, err := client.Search(context.Background(), SearchParams{Query: "test"})
+		if err != nil {
+			t.Fatalf("Search() error = %v, want the empty page", err)
+		}
+		if len(response.Entries) != 0 || response.TotalResults == 0 {
+			t.Errorf("Search() = %+v, want the empty page", response)
+		}
+
+		// Once retries run out, the last empty page is returned.
+		client = NewClient(
+			WithBaseURL(server.URL),
+			WithRateLimit(0),
+			WithRetry(RetryConfig{MaxAttempts: 2, InitialInterval: time.Millisecond}),
+		)
+		if _, err := client.Search(context.Background(), SearchParams{Query: "test"}); err != nil {
+			t.Errorf("Search() error = %v after retries ran out, want the empty page", err)
+		}
+		if got := atomic.LoadInt32(&attemptCount); got != 3 {
+			t.Errorf("got %d requests, want 3", got)
+		}
+	})
+
+	t.Run("retries malformed responses", func(t *testing.T) {
+		var attemptCount int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			if atomic.AddInt32(&attemptCount, 1) == 1 {
+				fmt.Fprint(w, `<feed><entry>`)
+				return
+			}
+			fmt.Fprint(w, testXMLResponse)
+		}))
+		defer server.Close()
+
+		client := NewClient(
+			WithBaseURL(server.URL),
+			WithRateLimit(0),
+			WithRetry(RetryConfig{MaxAttempts: 3, InitialInterval: 1 * time.Millisecond}),
+		)
+
+		if _, err := client.Search(context.Background(), SearchParams{Query: "test"}); err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if atomic.LoadInt32(&attemptCount) != 2 {
+			t.Errorf("Expected 2 attempts, got %d", attemptCount)
+		}
+	})
+
+	t.Run("stops at retry budget", func(t *testing.T) {
+		var attemptCount int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attemptCount, 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		client := NewClient(
+			WithBaseURL(server.URL),
+			WithRateLimit(0),
+			WithRetry(RetryConfig{
+				MaxAttempts:     10,
+				InitialInterval: 40 * time.Millisecond,
+				MaxInterval:     40 * time.Millisecond,
+				Jitter:          -1,
+				MaxElapsedTime:  100 * time.Millisecond,
+			}),
+		)
+
+		_, err := client.Search(context.Background(), SearchParams{Query: "test"})
+		if err == nil {
+			t.Error("Expected error when retry budget is exhausted")
+		}
+		if attempts := atomic.LoadInt32(&attemptCount); attempts != 3 {
+			t.Errorf("Expected 3 attempts within budget, got %d", attempts)
+		}
+	})
+
+	t.Run("custom policy", func(t *testing.T) {
+		var attemptCount int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attemptCount, 1) < 3 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprint(w, testXMLResponse)
+		}))
+		defer server.Close()
+
+		policy := &recordingPolicy{maxAttempts: 3}
+		client := NewClient(
+			WithBaseURL(server.URL),
+			WithRateLimit(0),
+			WithRetryPolicy(policy),
+		)
+
+		if _, err := client.Search(context.Background(), SearchParams{Query: "test"}); err != nil {
+			t.Fatalf("Search() error = %v", err)
+		}
+		if len(policy.statuses) != 2 || policy.statuses[0] != http.StatusNotFound {
+			t.Errorf("policy saw statuses %v, want two 404s", policy.statuses)
+		}
+	})
+}
+
+// recordingPolicy retries every failure up to maxAttempts and records the
+// status codes it was asked about.
+type recordingPolicy struct {
+	maxAttempts int
+	statuses    []int
+}
+
+func (p *recordingPolicy) ShouldRetry(attempt int, err error, response *http.Response) bool {
+	if response != nil {
+		p.statuses = append(p.statuses, response.StatusCode)
+	}
+	return attempt < p.maxAttempts
+}
+
+func (p *recordingPolicy) Delay(attempt int, err error, response *http.Response) time.Duration {
+	return 0
+}
+
+func responseWithRetryAfter(status int, value string) *http.Response {
+	header := http.Header{}
+	header.Set("Retry-After", value)
+	return &http.Response{StatusCode: status, Header: header}
+}
+
+const testEmptyPageResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <opensearch:totalResults xmlns:opensearch="http://a9.com/-/spec/opensearch/1.1/">1</opensearch:totalResults>
+  <opensearch:startIndex xmlns:opensearch="http://a9.com/-/spec/opensearch/1.1/">0</opensearch:startIndex>
+  <opensearch:itemsPerPage xmlns:opensearch="http://a9.com/-/spec/opensearch/1.1/">1</opensearch:itemsPerPage>
+</feed>`
@@ -0,0 +1,129 @@
+package arxiv_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+	"github.com/Epistemic-Technology/arxiv/arxiv/arxivtest"
+)
+
+// Client tests against the fake server, which stands in for
+// export.arxiv.org so that they run offline. They live outside package
+// arxiv because arxivtest imports it.
+
+func TestGetRequestGetsOKResponseWithDefaultConfig(t *testing.T) {
+	server := arxivtest.NewServer(arxivtest.Generate(3)...)
+	defer server.Close()
+	params := arxiv.SearchParams{Query: "all:paper"}
+
+	resp, err := arxiv.DoGetRequest(context.Background(), server.Client(), params)
+	if err != nil {
+		t.Fatalf("DoGetRequest(%v) = %v; want nil", params, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("DoGetRequest(%v) = %v; want 200", params, resp.StatusCode)
+	}
+}
+
+func TestPostRequestGetsOKResponseWithDefaultConfig(t *testing.T) {
+	server := arxivtest.NewServer(arxivtest.Generate(3)...)
+	defer server.Close()
+	client := server.Client(arxiv.WithRequestMethod(arxiv.RequestMethodPost))
+	params := arxiv.SearchParams{Query: "all:paper"}
+
+	resp, err := arxiv.DoPostRequest(context.Background(), client, params)
+	if err != nil {
+		t.Fatalf("DoPostRequest(%v) = %v; want nil", params, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("DoPostRequest(%v) = %v; want 200", params, resp.StatusCode)
+	}
+}
+
+func TestSearchWorksWithDefaultConfig(t *testing.T) {
+	server := arxivtest.NewServer(arxivtest.Generate(3)...)
+	defer server.Close()
+	params := arxiv.SearchParams{Query: "all:paper"}
+
+	response, err := server.Client().Search(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Search(%v) = %v; want nil", params, err)
+	}
+	if len(response.Entries) != 3 {
+		t.Errorf("Search(%v) returned %d entries; want 3", params, len(response.Entries))
+	}
+}
+
+func TestSearchIteratesOverMultiplePages(t *testing.T) {
+	server := arxivtest.NewServer(arxivtest.Generate(35)...)
+	defer server.Close()
+	params := arxiv.SearchParams{Query: "all:paper", MaxResults: 10}
+
+	count := 0
+	for result := range server.Client().SearchIter(context.Background(), params) {
+		if result.ID == "" {
+			t.Errorf("SearchIter() = %v; want non-empty string", result.ID)
+		}
+		count++
+	}
+	if count != 35 {
+		t.Errorf("SearchIter() returned %d results; want 35", count)
+	}
+	if got := len(server.Requests()); got != 4 {
+		t.Errorf("SearchIter() made %d requests; want 4", got)
+	}
+}
+
+func TestSearchNext(t *testing.T) {
+	server := arxivtest.NewServer(arxivtest.Generate(35)...)
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	response, err := client.Search(ctx, arxiv.SearchParams{Query: "all:paper", Start: 20, MaxResults: 10})
+	if err != nil {
+		t.Fatalf("Search() = %v; want nil", err)
+	}
+	if !arxiv.SearchHasMoreResults(response) {
+		t.Fatal("SearchHasMoreResults() = false; want true")
+	}
+	next, err := client.SearchNext(ctx, response)
+	if err != nil {
+		t.Fatalf("SearchNext() = %v; want nil", err)
+	}
+	if next.StartIndex != response.StartIndex+response.ItemsPerPage || len(next.Entries) != 5 {
+		t.Errorf("SearchNext() = start %d with %d entries; want start 30 with 5", next.StartIndex, len(next.Entries))
+	}
+	if arxiv.SearchHasMoreResults(next) {
+		t.Error("SearchHasMoreResults() after the last page = true; want false")
+	}
+	if _, err := client.SearchNext(ctx, next); err == nil {
+		t.Error("SearchNext() after the last page = nil; want error")
+	}
+}
+
+func TestSearchPrevious(t *testing.T) {
+	server := arxivtest.NewServer(arxivtest.Generate(35)...)
+	defer server.Close()
+	client := server.Client()
+	ctx := context.Background()
+
+	response, err := client.Search(ctx, arxiv.SearchParams{Query: "all:paper", Start: 20, MaxResults: 10})
+	if err != nil {
+		t.Fatalf("Search() = %v; want nil", err)
+	}
+	if !arxiv.SearchHasPreviousResults(response) {
+		t.Fatal("SearchHasPreviousResults() = false; want true")
+	}
+	previous, err := client.SearchPrevious(ctx, response)
+	if err != nil {
+		t.Fatalf("SearchPrevious() = %v; want nil", err)
+	}
+	if previous.StartIndex != 10 || len(previous.Entries) != 10 {
+		t.Errorf("SearchPrevious() = start %d with %d entries; want start 10 with 10", previous.StartIndex, len(previous.Entries))
+	}
+}
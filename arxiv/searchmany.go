@@ -0,0 +1,103 @@
+package arxiv
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultConcurrency is the default number of searches SearchMany runs at once.
+// Requests are still paced by the client's limiter; concurrency only lets one
+// search wait on the network while the next is admitted.
+const DefaultConcurrency = 2
+
+// WithConcurrency sets how many searches SearchMany may run at once.
+func WithConcurrency(concurrency int) ClientOption {
+	return func(c *Client) {
+		c.Concurrency = concurrency
+	}
+}
+
+// QueryResult holds the outcome of one search made by SearchMany.
+type QueryResult struct {
+	Index   int           // Position of the search in the slice passed to SearchMany.
+	Params  SearchParams  // Parameters of the search.
+	Results SearchResults // Results of the search, if it succeeded.
+	Err     error         // Error returned by the search, if any.
+}
+
+// SearchMany runs a search for each of params and returns their results in
+// the same order. Searches run concurrently, up to the client's Concurrency,
+// and share the client's rate limiter and retry policy. A failed search does
+// not stop the others; its error is recorded in the corresponding QueryResult.
+func (c *Client) SearchMany(ctx context.Context, params []SearchParams) []QueryResult {
+	results := make([]QueryResult, len(params))
+	concurrency := c.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i, p := range params {
+		results[i] = QueryResult{Params: p, Index: i}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, p SearchParams) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			results[i].Results, results[i].Err = c.Search(ctx, p)
+		}(i, p)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// MergedEntry is an entry found by one or more of the searches passed to
+// MergeQueryResults.
+type MergedEntry struct {
+	Entry   EntryMetadata `json:"entry"`   // Newest version of the entry found by any search.
+	Queries []int         `json:"queries"` // Indexes of the searches that returned the entry, in order.
+}
+
+// MergeQueryResults combines the entries of several searches, dropping
+// duplicates by arXiv ID, whatever their version, and recording which
+// searches matched each entry. Where searches returned different versions
+// of a paper, the newest is kept, as by KeepNewest. Entries are ordered by
+// the first search that returned them and then by their position in that
+// search's results. Failed searches are skipped.
+func MergeQueryResults(results []QueryResult) []MergedEntry {
+	var merged []MergedEntry
+	seen := make(map[string]int)
+
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		for _, entry := range result.Results.Entries {
+			id := entry.BaseID()
+			if i, ok := seen[id]; ok {
+				if KeepNewest(merged[i].Entry, entry) {
+					merged[i].Entry = entry
+				}
+				queries := merged[i].Queries
+				if queries[len(queries)-1] != result.Index {
+					merged[i].Queries = append(queries, result.Index)
+				}
+				continue
+			}
+			seen[id] = len(merged)
+			merged = append(merged, MergedEntry{Entry: entry, Queries: []int{result.Index}})
+		}
+	}
+
+	return merged
+}
@@ -0,0 +1,143 @@
+package arxiv
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// feedWithIDs returns an Atom feed containing one entry per ID.
+func feedWithIDs(ids ...string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:opensearch="http://a9.com/-/spec/opensearch/1.1/">`)
+	fmt.Fprintf(&b, "<opensearch:totalResults>%d</opensearch:totalResults>", len(ids))
+	b.WriteString("<opensearch:startIndex>0</opensearch:startIndex>")
+	fmt.Fprintf(&b, "<opensearch:itemsPerPage>%d</opensearch:itemsPerPage>", len(ids))
+	for _, id := range ids {
+		fmt.Fprintf(&b, "<entry><id>http://arxiv.org/abs/%s</id><title>Paper %s</title></entry>", id, id)
+	}
+	b.WriteString("</feed>")
+	return b.String()
+}
+
+func TestSearchMany(t *testing.T) {
+	feeds := map[string]string{
+		"cat:cs.LG": feedWithIDs("2401.00001v1", "2401.00002v1"),
+		"cat:cs.AI": feedWithIDs("2401.00002v1", "2401.00003v2"),
+		"cat:cs.CL": feedWithIDs("2401.00004v1"),
+	}
+
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		feed, ok := feeds[r.URL.Query().Get("search_query")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, feed)
+	}))
+	defer server.Close()
+
+	client := NewClient(
+		WithBaseURL(server.URL),
+		WithHTTPClient(server.Client()),
+		WithRateLimit(0),
+		WithConcurrency(2),
+	)
+
+	params := []SearchParams{
+		{Query: "cat:cs.LG"},
+		{Query: "cat:cs.AI"},
+		{Query: "cat:bogus"},
+		{Query: "cat:cs.CL"},
+	}
+	results := client.SearchMany(context.Background(), params)
+
+	if len(results) != len(params) {
+		t.Fatalf("SearchMany() returned %d results; want %d", len(results), len(params))
+	}
+	for i, result := range results {
+		if result.Index != i || result.Params.Query != params[i].Query {
+			t.Errorf("results[%d] = %d %q; want %d %q", i, result.Index, result.Params.Query, i, params[i].Query)
+		}
+	}
+	if results[2].Err == nil {
+		t.Error("results[2].Err = nil; want error for failed query")
+	}
+	if results[0].Err != nil || len(results[0].Results.Entries) != 2 {
+		t.Errorf("results[0] = %d entries, err %v; want 2 entries", len(results[0].Results.Entries), results[0].Err)
+	}
+	if got := atomic.LoadInt32(&maxInFlight); got > 2 {
+		t.Errorf("max concurrent requests = %d; want at most 2", got)
+	}
+
+	merged := MergeQueryResults(results)
+	wantIDs := []string{"2401.00001v1", "2401.00002v1", "2401.00003v2", "2401.00004v1"}
+	if len(merged) != len(wantIDs) {
+		t.Fatalf("MergeQueryResults() returned %d entries; want %d", len(merged), len(wantIDs))
+	}
+	for i, id := range wantIDs {
+		if merged[i].Entry.ArxivID() != id {
+			t.Errorf("merged[%d] = %s; want %s", i, merged[i].Entry.ArxivID(), id)
+		}
+	}
+	if q := merged[1].Queries; len(q) != 2 || q[0] != 0 || q[1] != 1 {
+		t.Errorf("merged[1].Queries = %v; want [0 1]", q)
+	}
+	if q := merged[3].Queries; len(q) != 1 || q[0] != 3 {
+		t.Errorf("merged[3].Queries = %v; want [3]", q)
+	}
+}
+
+func TestMergeQueryResultsVersions(t *testing.T) {
+	entry := func(id string, day int) EntryMetadata {
+		return EntryMetadata{ID: "http://arxiv.org/abs/" + id, Updated: time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC)}
+	}
+	results := []QueryResult{
+		{Index: 0, Results: SearchResults{Entries: []EntryMetadata{entry("2401.00001v1", 1)}}},
+		{Index: 1, Results: SearchResults{Entries: []EntryMetadata{entry("2401.00001v2", 2), entry("2401.00002v2", 2)}}},
+		{Index: 2, Results: SearchResults{Entries: []EntryMetadata{entry("2401.00002v1", 1)}}},
+	}
+	merged := MergeQueryResults(results)
+	if len(merged) != 2 {
+		t.Fatalf("MergeQueryResults() returned %d entries; want 2: %+v", len(merged), merged)
+	}
+	for i, want := range []string{"2401.00001v2", "2401.00002v2"} {
+		if got := merged[i].Entry.ArxivID(); got != want {
+			t.Errorf("merged[%d] = %s; want %s", i, got, want)
+		}
+	}
+	if q := merged[1].Queries; len(q) != 2 || q[0] != 1 || q[1] != 2 {
+		t.Errorf("merged[1].Queries = %v; want [1 2]", q)
+	}
+}
+
+func TestSearchManyCanceled(t *testing.T) {
+	client := NewClient(WithBaseURL("http://127.0.0.1:0"), WithRateLimit(0))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := client.SearchMany(ctx, []SearchParams{{Query: "a"}, {Query: "b"}, {Query: "c"}})
+	for i, result := range results {
+		if result.Err == nil {
+			t.Errorf("results[%d].Err = nil; want error after cancellation", i)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+// Package store keeps arXiv metadata in a local database, so that entries
+// fetched once need not be downloaded again.
+//
+// Entries are keyed by base arXiv ID, with every version seen kept as
+// history. Sync brings the entries matching a query up to date, fetching
+// only those updated since the previous sync of the same query:
+//
+//	s, err := store.Open("arxiv.db")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer s.Close()
+//
+//	result, err := s.Sync(ctx, arxiv.NewClient(), "cat:cs.LG")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Printf("%d new, %d updated\n", result.Added, result.Updated)
+//
+// The database is a single file written with bbolt, and may be opened by
+// one process at a time.
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// DefaultPageSize is the number of entries Sync requests per page.
+const DefaultPageSize = 100
+
+// ErrNotFound is returned for entries that are not in the store.
+var ErrNotFound = errors.New("store: entry not found")
+
+var (
+	entriesBucket  = []byte("entries")  // Base ID to the latest version of the entry
+	versionsBucket = []byte("versions") // Base ID to a bucket of versions, by version number
+	syncBucket     = []byte("sync")     // Query to its syncState
+)
+
+// Store is a database of entries. It is safe for concurrent use.
+type Store struct {
+	db               *bolt.DB
+	pageSize         int
+	initialSyncLimit int
+}
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithPageSize sets the number of entries Sync requests per page (default
+// DefaultPageSize).
+func WithPageSize(n int) Option {
+	return func(s *Store) {
+		s.pageSize = n
+	}
+}
+
+// WithInitialSyncLimit limits the first sync of a query to the n most
+// recently updated entries, instead of every entry the API returns. Later
+// syncs continue from the newest of them. Zero means no limit.
+func WithInitialSyncLimit(n int) Option {
+	return func(s *Store) {
+		s.initialSyncLimit = n
+	}
+}
+
+// Open opens the database at path, creating it if it does not exist. It
+// fails after a second if another process has the database open.
+func Open(path string, options ...Option) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("store: opening %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{entriesBucket, versionsBucket, syncBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("store: initializing %s: %w", path, err)
+	}
+
+	s := &Store{db: db, pageSize: DefaultPageSize}
+	for _, option := range options {
+		option(s)
+	}
+	return s, nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Change tells how Put changed the store.
+type Change int
+
+const (
+	Unchanged Change = iota // The entry was already stored
+	Added                   // The entry was not in the store
+	Updated                 // A new version of the entry, or a newer copy of a stored version
+)
+
+// Put stores an entry, recording its version in the entry's history. The
+// entry becomes the latest one unless a later version is already stored.
+func (s *Store) Put(entry arxiv.EntryMetadata) (Change, error) {
+	var change Change
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		var err error
+		change, err = put(tx, entry)
+		return err
+	})
+	return change, err
+}
+
+func put(tx *bolt.Tx, entry arxiv.EntryMetadata) (Change, error) {
+	id := entry.BaseID()
+	if id == "" {
+		return Unchanged, errors.New("store: entry has no ID")
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return Unchanged, err
+	}
+
+	versions, err := tx.Bucket(versionsBucket).CreateBucketIfNotExists([]byte(id))
+	if err != nil {
+		return Unchanged, err
+	}
+	key := versionKey(entry.Version())
+	change := Updated
+	if old := versions.Get(key); old != nil {
+		var stored arxiv.EntryMetadata
+		if err := json.Unmarshal(old, &stored); err != nil {
+			return Unchanged, err
+		}
+		if !entry.Updated.After(stored.Updated) {
+			return Unchanged, nil
+		}
+	} else if first, _ := versions.Cursor().First(); first == nil {
+		change = Added
+	}
+	if err := versions.Put(key, data); err != nil {
+		return Unchanged, err
+	}
+
+	// The latest version is the last key of the history.
+	if last, _ := versions.Cursor().Last(); string(last) == string(key) {
+		if err := tx.Bucket(entriesBucket).Put([]byte(id), data); err != nil {
+			return Unchanged, err
+		}
+	}
+	return change, nil
+}
+
+// Get returns the latest stored version of the entry with the given ID, or
+// the given version if the ID has a version suffix such as "v2".
+func (s *Store) Get(id string) (arxiv.EntryMetadata, error) {
+	base := arxiv.BaseID(id)
+	var entry arxiv.EntryMetadata
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var data []byte
+		if base == id {
+			data = tx.Bucket(entriesBucket).Get([]byte(id))
+		} else if versions := tx.Bucket(versionsBucket).Bucket([]byte(base)); versions != nil {
+			n, _ := strconv.Atoi(id[len(base)+1:])
+			data = versions.Get(versionKey(n))
+		}
+		if data == nil {
+			return fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		return json.Unmarshal(data, &entry)
+	})
+	return entry, err
+}
+
+// Versions returns every stored version of the entry with the given base
+// ID, oldest first.
+func (s *Store) Versions(id string) ([]arxiv.EntryMetadata, error) {
+	var entries []arxiv.EntryMetadata
+	err := s.db.View(func(tx *bolt.Tx) error {
+		versions := tx.Bucket(versionsBucket).Bucket([]byte(arxiv.BaseID(id)))
+		if versions == nil {
+			return fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		return versions.ForEach(func(_, data []byte) error {
+			var entry arxiv.EntryMetadata
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return err
+			}
+			entries = append(entries, entry)
+			return nil
+		})
+	})
+	return entries, err
+}
+
+// Delete removes the entry with the given base ID and its history.
+func (s *Store) Delete(id string) error {
+	id = arxiv.BaseID(id)
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(entriesBucket).Get([]byte(id)) == nil {
+			return fmt.Errorf("%w: %s", ErrNotFound, id)
+		}
+		if err := tx.Bucket(entriesBucket).Delete([]byte(id)); err != nil {
+			return err
+		}
+		return tx.Bucket(versionsBucket).DeleteBucket([]byte(id))
+	})
+}
+
+// Len returns the number of entries in the store.
+func (s *Store) Len() (int, error) {
+	var n int
+	err := s.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(entriesBucket).Stats().KeyN
+		return nil
+	})
+	return n, err
+}
+
+// ForEach calls fn with the latest version of every entry, in order of base
+// ID, stopping at the first error fn returns. The store must not be
+// modified from fn.
+func (s *Store) ForEach(fn func(arxiv.EntryMetadata) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(entriesBucket).ForEach(func(_, data []byte) error {
+			var entry arxiv.EntryMetadata
+			if err := json.Unmarshal(data, &entry); err != nil {
+				return err
+			}
+			return fn(entry)
+		})
+	})
+}
+
+// versionKey encodes a version number so that keys sort in version order.
+func versionKey(n int) []byte {
+	return binary.BigEndian.AppendUint32(nil, uint32(n))
+}
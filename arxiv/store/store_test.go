@@ -0,0 +1,268 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+var day = func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+
+// paper is an entry served by apiServer.
+type paper struct {
+	id      string // Versioned arXiv ID
+	updated time.Time
+}
+
+// apiServer serves *papers, which must be sorted most recently updated
+// first, and records the start of each request. Requests starting at
+// failAt fail.
+func apiServer(t *testing.T, papers *[]paper, starts *[]int, failAt *int) *arxiv.Client {
+	t.Helper()
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("sortBy") != "lastUpdatedDate" || q.Get("sortOrder") != "descending" {
+			t.Errorf("unexpected sort: %s", r.URL.RawQuery)
+		}
+		var start, max int
+		fmt.Sscan(q.Get("start"), &start)
+		fmt.Sscan(q.Get("max_results"), &max)
+		mu.Lock()
+		*starts = append(*starts, start)
+		mu.Unlock()
+		if failAt != nil && start == *failAt {
+			http.Error(w, "unavailable", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, `<feed xmlns="http://www.w3.org/2005/Atom" xmlns:opensearch="http://a9.com/-/spec/opensearch/1.1/">
+  <opensearch:totalResults>%d</opensearch:totalResults>
+  <opensearch:startIndex>%d</opensearch:startIndex>
+  <opensearch:itemsPerPage>%d</opensearch:itemsPerPage>`, len(*papers), start, max)
+		for _, p := range (*papers)[start:min(start+max, len(*papers))] {
+			fmt.Fprintf(w, `<entry><id>http://arxiv.org/abs/%s</id><published>2024-03-01T00:00:00Z</published><updated>%s</updated><title>Paper %s</title></entry>`,
+				p.id, p.updated.Format(time.RFC3339), p.id)
+		}
+		fmt.Fprint(w, `</feed>`)
+	}))
+	t.Cleanup(server.Close)
+	return arxiv.NewClient(arxiv.WithBaseURL(server.URL), arxiv.WithRateLimit(0))
+}
+
+func openStore(t *testing.T, path string, options ...Option) *Store {
+	t.Helper()
+	s, err := Open(path, options...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestSync(t *testing.T) {
+	papers := []paper{
+		{"2403.00005v1", day(5)},
+		{"2403.00004v1", day(4)},
+		{"2403.00003v1", day(3)},
+		{"2403.00002v1", day(2)},
+		{"2403.00001v1", day(1)},
+	}
+	var starts []int
+	client := apiServer(t, &papers, &starts, nil)
+	path := filepath.Join(t.TempDir(), "arxiv.db")
+	s := openStore(t, path, WithPageSize(2))
+	ctx := context.Background()
+
+	result, err := s.Sync(ctx, client, "cat:cs.LG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SyncResult{Fetched: 5, Added: 5, Pages: 3, Watermark: day(5), Complete: true}
+	if result != want {
+		t.Errorf("first sync = %+v, want %+v", result, want)
+	}
+
+	// A new paper, and a new version of an old one.
+	papers = append([]paper{{"2403.00007v1", day(7)}, {"2403.00004v2", day(6)}}, papers...)
+	papers = append(papers[:3], papers[4:]...)
+	starts = nil
+	result, err = s.Sync(ctx, client, "cat:cs.LG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = SyncResult{Fetched: 3, Added: 1, Updated: 1, Pages: 2, Watermark: day(7), Complete: true}
+	if result != want {
+		t.Errorf("second sync = %+v, want %+v", result, want)
+	}
+	if fmt.Sprint(starts) != "[0 2]" {
+		t.Errorf("second sync requested starts %v, want it to stop at the watermark", starts)
+	}
+
+	if n, _ := s.Len(); n != 6 {
+		t.Errorf("Len = %d, want 6", n)
+	}
+	versions, err := s.Versions("2403.00004")
+	if err != nil || len(versions) != 2 || versions[0].ArxivID() != "2403.00004v1" || versions[1].ArxivID() != "2403.00004v2" {
+		t.Errorf("Versions = %v, %v", versions, err)
+	}
+	if e, err := s.Get("2403.00004"); err != nil || e.ArxivID() != "2403.00004v2" {
+		t.Errorf("Get latest = %q, %v", e.ArxivID(), err)
+	}
+	if e, err := s.Get("2403.00004v1"); err != nil || e.ArxivID() != "2403.00004v1" {
+		t.Errorf("Get v1 = %q, %v", e.ArxivID(), err)
+	}
+	if _, err := s.Get("2403.09999"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of missing entry error = %v, want ErrNotFound", err)
+	}
+
+	// The store and the watermark survive reopening.
+	s.Close()
+	s = openStore(t, path)
+	if watermark, err := s.Watermark("cat:cs.LG"); err != nil || !watermark.Equal(day(7)) {
+		t.Errorf("Watermark after reopening = %v, %v", watermark, err)
+	}
+	if watermark, _ := s.Watermark("cat:cs.AI"); !watermark.IsZero() {
+		t.Errorf("Watermark of unsynced query = %v", watermark)
+	}
+	var ids []string
+	s.ForEach(func(e arxiv.EntryMetadata) error {
+		ids = append(ids, e.BaseID())
+		return nil
+	})
+	if len(ids) != 6 || ids[0] != "2403.00001" {
+		t.Errorf("ForEach visited %v", ids)
+	}
+}
+
+func TestSyncInitialLimit(t *testing.T) {
+	papers := []paper{{"2403.00005v1", day(5)}, {"2403.00004v1", day(4)}, {"2403.00003v1", day(3)}, {"2403.00002v1", day(2)}}
+	var starts []int
+	client := apiServer(t, &papers, &starts, nil)
+	s := openStore(t, filepath.Join(t.TempDir(), "arxiv.db"), WithPageSize(2), WithInitialSyncLimit(3))
+
+	result, err := s.Sync(context.Background(), client, "all:x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SyncResult{Fetched: 3, Added: 3, Pages: 2, Watermark: day(5)}
+	if result != want {
+		t.Errorf("limited sync = %+v, want %+v", result, want)
+	}
+
+	// Later syncs are not limited and continue from the watermark.
+	papers = append([]paper{{"2403.00010v1", day(10)}, {"2403.00009v1", day(9)}, {"2403.00008v1", day(8)}}, papers...)
+	result, err = s.Sync(context.Background(), client, "all:x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Added != 3 || !result.Complete || !result.Watermark.Equal(day(10)) {
+		t.Errorf("second sync = %+v", result)
+	}
+}
+
+func TestSyncFailureKeepsWatermark(t *testing.T) {
+	papers := []paper{{"2403.00003v1", day(3)}, {"2403.00002v1", day(2)}, {"2403.00001v1", day(1)}}
+	var starts []int
+	client := apiServer(t, &papers, &starts, nil)
+	s := openStore(t, filepath.Join(t.TempDir(), "arxiv.db"), WithPageSize(1))
+	ctx := context.Background()
+	if _, err := s.Sync(ctx, client, "all:x"); err != nil {
+		t.Fatal(err)
+	}
+
+	papers = append([]paper{{"2403.00006v1", day(6)}, {"2403.00005v1", day(5)}}, papers...)
+	failAt := 1
+	client = apiServer(t, &papers, &starts, &failAt)
+	result, err := s.Sync(ctx, client, "all:x")
+	if err == nil {
+		t.Fatal("sync with a failing page succeeded")
+	}
+	if result.Added != 1 || result.Complete {
+		t.Errorf("failed sync = %+v, want the first page stored", result)
+	}
+	if watermark, _ := s.Watermark("all:x"); !watermark.Equal(day(3)) {
+		t.Errorf("Watermark after a failed sync = %v, want it unchanged", watermark)
+	}
+}
+
+func TestSyncWithoutPageSize(t *testing.T) {
+	// Pages that report no itemsPerPage still advance by their entries.
+	var starts []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var start int
+		fmt.Sscan(r.URL.Query().Get("start"), &start)
+		starts = append(starts, start)
+		fmt.Fprintf(w, `<feed xmlns="http://www.w3.org/2005/Atom" xmlns:opensearch="http://a9.com/-/spec/opensearch/1.1/">
+  <opensearch:totalResults>3</opensearch:totalResults>
+  <opensearch:startIndex>%d</opensearch:startIndex>
+  <opensearch:itemsPerPage>0</opensearch:itemsPerPage>`, start)
+		for i := start; i < min(start+2, 3); i++ {
+			fmt.Fprintf(w, `<entry><id>http://arxiv.org/abs/2403.0000%dv1</id><updated>%s</updated></entry>`, 3-i, day(3-i).Format(time.RFC3339))
+		}
+		fmt.Fprint(w, `</feed>`)
+	}))
+	defer server.Close()
+	client := arxiv.NewClient(arxiv.WithBaseURL(server.URL), arxiv.WithRateLimit(0))
+	s := openStore(t, filepath.Join(t.TempDir(), "arxiv.db"), WithPageSize(2))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result, err := s.Sync(ctx, client, "all:x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Fetched != 3 || !result.Complete || fmt.Sprint(starts) != "[0 2]" {
+		t.Errorf("sync = %+v after starts %v, want all 3 entries from starts [0 2]", result, starts)
+	}
+}
+
+func TestPut(t *testing.T) {
+	s := openStore(t, filepath.Join(t.TempDir(), "arxiv.db"))
+	entry := func(id string, updated time.Time) arxiv.EntryMetadata {
+		return arxiv.EntryMetadata{ID: "http://arxiv.org/abs/" + id, Updated: updated}
+	}
+	steps := []struct {
+		entry  arxiv.EntryMetadata
+		change Change
+		latest string
+	}{
+		{entry("2401.00001v2", day(2)), Added, "2401.00001v2"},
+		{entry("2401.00001v2", day(2)), Unchanged, "2401.00001v2"},
+		{entry("2401.00001v1", day(1)), Updated, "2401.00001v2"},
+		{entry("2401.00001v2", day(3)), Updated, "2401.00001v2"},
+		{entry("2401.00001v10", day(4)), Updated, "2401.00001v10"},
+	}
+	for i, step := range steps {
+		change, err := s.Put(step.entry)
+		if err != nil || change != step.change {
+			t.Errorf("step %d: Put = %v, %v, want %v", i, change, err, step.change)
+		}
+		if e, _ := s.Get("2401.00001"); e.ArxivID() != step.latest {
+			t.Errorf("step %d: latest = %q, want %q", i, e.ArxivID(), step.latest)
+		}
+	}
+	if versions, _ := s.Versions("2401.00001v1"); len(versions) != 3 {
+		t.Errorf("got %d versions, want 3", len(versions))
+	}
+	if _, err := s.Put(arxiv.EntryMetadata{}); err == nil {
+		t.Error("Put of an entry without ID succeeded")
+	}
+
+	if err := s.Delete("2401.00001v2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Versions("2401.00001"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Versions after Delete error = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete("2401.00001"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete error = %v, want ErrNotFound", err)
+	}
+}
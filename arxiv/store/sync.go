@@ -0,0 +1,157 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// maxStart is the largest start index the arXiv API accepts.
+const maxStart = 30000
+
+// SyncResult reports what a sync fetched and changed.
+type SyncResult struct {
+	Fetched   int       // Entries returned by the API
+	Added     int       // Entries that were not in the store
+	Updated   int       // Entries stored in a new version or with a newer update time
+	Pages     int       // Requests made
+	Watermark time.Time // Update time of the newest entry synced for the query
+	Complete  bool      // Whether every entry updated since the previous sync was fetched
+}
+
+// syncState is what the store remembers about a query between syncs.
+type syncState struct {
+	Watermark time.Time `json:"watermark"` // Update time of the newest entry synced
+	LastSync  time.Time `json:"lastSync"`  // Time the sync finished
+}
+
+// Sync stores the entries matching query that were updated since the
+// previous sync of the same query, or all of them on the first sync (see
+// WithInitialSyncLimit). Results are requested most recently updated first,
+// and paging stops at the first entry older than the watermark left by the
+// previous sync. Entries are stored page by page as they arrive.
+//
+// The watermark only advances once a sync has caught up with the previous
+// one, so a sync that fails or is cut short is repeated in full next time.
+// A query with more updates between syncs than the API can page through
+// never catches up; Complete reports this.
+func (s *Store) Sync(ctx context.Context, client *arxiv.Client, query string) (SyncResult, error) {
+	state, err := s.syncState(query)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	result := SyncResult{Watermark: state.Watermark}
+	initial := state.Watermark.IsZero()
+	newest := state.Watermark
+
+	params := arxiv.SearchParams{
+		Query:      query,
+		MaxResults: s.pageSize,
+		SortBy:     arxiv.SortByLastUpdatedDate,
+		SortOrder:  arxiv.SortOrderDescending,
+	}
+	for {
+		page, err := client.Search(ctx, params)
+		if err != nil {
+			return result, err
+		}
+		result.Pages++
+
+		var entries []arxiv.EntryMetadata
+		reached, limited := false, false
+		for _, entry := range page.Entries {
+			if entry.Updated.Before(state.Watermark) {
+				reached = true
+				break
+			}
+			if initial && s.initialSyncLimit > 0 && result.Fetched >= s.initialSyncLimit {
+				limited = true
+				break
+			}
+			entries = append(entries, entry)
+			result.Fetched++
+			if entry.Updated.After(newest) {
+				newest = entry.Updated
+			}
+		}
+		if err := s.putAll(entries, &result); err != nil {
+			return result, err
+		}
+
+		limited = limited || initial && s.initialSyncLimit > 0 && result.Fetched >= s.initialSyncLimit
+		// Advance by the entries received if the page size is missing, and
+		// stop rather than request the same page again.
+		next := page.StartIndex + max(page.ItemsPerPage, len(page.Entries))
+		more := page.TotalResults > 0 && next < page.TotalResults
+		switch {
+		case reached || !limited && (!more || len(page.Entries) == 0):
+			result.Complete = true
+		case !limited && next > params.Start && next <= maxStart:
+			params.Start = next
+			continue
+		}
+
+		// A first sync has no earlier watermark to catch up with, so it
+		// advances even when limited.
+		if result.Complete || initial {
+			result.Watermark = newest
+			err = s.setSyncState(query, syncState{Watermark: newest, LastSync: time.Now().UTC()})
+		}
+		return result, err
+	}
+}
+
+// Watermark returns the update time of the newest entry synced for query,
+// or the zero time if the query was never synced.
+func (s *Store) Watermark(query string) (time.Time, error) {
+	state, err := s.syncState(query)
+	return state.Watermark, err
+}
+
+// putAll stores the entries of one page in a single transaction.
+func (s *Store) putAll(entries []arxiv.EntryMetadata, result *SyncResult) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, entry := range entries {
+			change, err := put(tx, entry)
+			if err != nil {
+				return err
+			}
+			switch change {
+			case Added:
+				result.Added++
+			case Updated:
+				result.Updated++
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) syncState(query string) (syncState, error) {
+	var state syncState
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(syncBucket).Get([]byte(query))
+		if data == nil {
+			return nil
+		}
+		return json.Unmarshal(data, &state)
+	})
+	return state, err
+}
+
+func (s *Store) setSyncState(query string, state syncState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(syncBucket).Put([]byte(query), data)
+	})
+}
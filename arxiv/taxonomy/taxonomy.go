@@ -0,0 +1,469 @@
+// Package taxonomy describes arXiv's subject classification: the groups,
+// archives and categories papers are filed under, such as the category
+// "cs.LG" (Machine Learning) in the archive "cs" of the group "Computer
+// Science".
+//
+//	c, ok := taxonomy.Lookup("cs.LG")
+//	fmt.Println(c.Name, c.Group) // Machine Learning Computer Science
+//
+// Some categories are aliases of others, such as math.IT of cs.IT, and some
+// archives were merged into newer categories, such as solv-int into nlin.SI.
+// Both can still appear on papers and in queries. Canonical resolves them:
+//
+//	taxonomy.Canonical("math.IT")  // "cs.IT"
+//	taxonomy.Canonical("solv-int") // "nlin.SI"
+//
+// Expand lists the categories matching a pattern such as "cs.*".
+package taxonomy
+
+import (
+	"cmp"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Groups of archives, as arXiv shows them on its front page.
+const (
+	GroupCS      = "Computer Science"
+	GroupEcon    = "Economics"
+	GroupEESS    = "Electrical Engineering and Systems Science"
+	GroupMath    = "Mathematics"
+	GroupPhysics = "Physics"
+	GroupQBio    = "Quantitative Biology"
+	GroupQFin    = "Quantitative Finance"
+	GroupStat    = "Statistics"
+)
+
+// Category is a subject class papers can be filed under.
+type Category struct {
+	ID         string // Identifier used on papers and in queries, such as "cs.LG" or "hep-th"
+	Name       string // Human-readable name, such as "Machine Learning"
+	Archive    string // Archive the category belongs to, such as "cs"
+	Group      string // Group of the archive, such as GroupCS
+	Canonical  string // For an alias or a merged archive, the category it stands for; otherwise ""
+	Deprecated bool   // Whether new papers are no longer filed under the category
+}
+
+// IsAlias reports whether the category stands for another one.
+func (c Category) IsAlias() bool {
+	return c.Canonical != ""
+}
+
+func (c Category) String() string {
+	return c.ID
+}
+
+// Archive is a collection of categories, such as "cs" or "astro-ph". Archives
+// without subject classes, such as "hep-th", are also categories.
+type Archive struct {
+	ID    string // Identifier, such as "cs"
+	Name  string // Human-readable name, such as "Computer Science"
+	Group string // Group the archive belongs to
+}
+
+var archives = []Archive{
+	{"astro-ph", "Astrophysics", GroupPhysics},
+	{"cond-mat", "Condensed Matter", GroupPhysics},
+	{"cs", "Computer Science", GroupCS},
+	{"econ", "Economics", GroupEcon},
+	{"eess", "Electrical Engineering and Systems Science", GroupEESS},
+	{"gr-qc", "General Relativity and Quantum Cosmology", GroupPhysics},
+	{"hep-ex", "High Energy Physics - Experiment", GroupPhysics},
+	{"hep-lat", "High Energy Physics - Lattice", GroupPhysics},
+	{"hep-ph", "High Energy Physics - Phenomenology", GroupPhysics},
+	{"hep-th", "High Energy Physics - Theory", GroupPhysics},
+	{"math", "Mathematics", GroupMath},
+	{"math-ph", "Mathematical Physics", GroupPhysics},
+	{"nlin", "Nonlinear Sciences", GroupPhysics},
+	{"nucl-ex", "Nuclear Experiment", GroupPhysics},
+	{"nucl-th", "Nuclear Theory", GroupPhysics},
+	{"physics", "Physics", GroupPhysics},
+	{"q-bio", "Quantitative Biology", GroupQBio},
+	{"q-fin", "Quantitative Finance", GroupQFin},
+	{"quant-ph", "Quantum Physics", GroupPhysics},
+	{"stat", "Statistics", GroupStat},
+}
+
+// categories lists every category as {ID, Name, Canonical}. The archive is
+// the part of the ID before the dot.
+var categories = [][3]string{
+	{"astro-ph", "Astrophysics", ""},
+	{"astro-ph.CO", "Cosmology and Nongalactic Astrophysics", ""},
+	{"astro-ph.EP", "Earth and Planetary Astrophysics", ""},
+	{"astro-ph.GA", "Astrophysics of Galaxies", ""},
+	{"astro-ph.HE", "High Energy Astrophysical Phenomena", ""},
+	{"astro-ph.IM", "Instrumentation and Methods for Astrophysics", ""},
+	{"astro-ph.SR", "Solar and Stellar Astrophysics", ""},
+
+	{"cond-mat", "Condensed Matter", ""},
+	{"cond-mat.dis-nn", "Disordered Systems and Neural Networks", ""},
+	{"cond-mat.mes-hall", "Mesoscale and Nanoscale Physics", ""},
+	{"cond-mat.mtrl-sci", "Materials Science", ""},
+	{"cond-mat.other", "Other Condensed Matter", ""},
+	{"cond-mat.quant-gas", "Quantum Gases", ""},
+	{"cond-mat.soft", "Soft Condensed Matter", ""},
+	{"cond-mat.stat-mech", "Statistical Mechanics", ""},
+	{"cond-mat.str-el", "Strongly Correlated Electrons", ""},
+	{"cond-mat.supr-con", "Superconductivity", ""},
+
+	{"cs.AI", "Artificial Intelligence", ""},
+	{"cs.AR", "Hardware Architecture", ""},
+	{"cs.CC", "Computational Complexity", ""},
+	{"cs.CE", "Computational Engineering, Finance, and Science", ""},
+	{"cs.CG", "Computational Geometry", ""},
+	{"cs.CL", "Computation and Language", ""},
+	{"cs.CR", "Cryptography and Security", ""},
+	{"cs.CV", "Computer Vision and Pattern Recognition", ""},
+	{"cs.CY", "Computers and Society", ""},
+	{"cs.DB", "Databases", ""},
+	{"cs.DC", "Distributed, Parallel, and Cluster Computing", ""},
+	{"cs.DL", "Digital Libraries", ""},
+	{"cs.DM", "Discrete Mathematics", ""},
+	{"cs.DS", "Data Structures and Algorithms", ""},
+	{"cs.ET", "Emerging Technologies", ""},
+	{"cs.FL", "Formal Languages and Automata Theory", ""},
+	{"cs.GL", "General Literature", ""},
+	{"cs.GR", "Graphics", ""},
+	{"cs.GT", "Computer Science and Game Theory", ""},
+	{"cs.HC", "Human-Computer Interaction", ""},
+	{"cs.IR", "Information Retrieval", ""},
+	{"cs.IT", "Information Theory", ""},
+	{"cs.LG", "Machine Learning", ""},
+	{"cs.LO", "Logic in Computer Science", ""},
+	{"cs.MA", "Multiagent Systems", ""},
+	{"cs.MM", "Multimedia", ""},
+	{"cs.MS", "Mathematical Software", ""},
+	{"cs.NA", "Numerical Analysis", "math.NA"},
+	{"cs.NE", "Neural and Evolutionary Computing", ""},
+	{"cs.NI", "Networking and Internet Architecture", ""},
+	{"cs.OH", "Other Computer Science", ""},
+	{"cs.OS", "Operating Systems", ""},
+	{"cs.PF", "Performance", ""},
+	{"cs.PL", "Programming Languages", ""},
+	{"cs.RO", "Robotics", ""},
+	{"cs.SC", "Symbolic Computation", ""},
+	{"cs.SD", "Sound", ""},
+	{"cs.SE", "Software Engineering", ""},
+	{"cs.SI", "Social and Information Networks", ""},
+	{"cs.SY", "Systems and Control", "eess.SY"},
+
+	{"econ.EM", "Econometrics", ""},
+	{"econ.GN", "General Economics", ""},
+	{"econ.TH", "Theoretical Economics", ""},
+
+	{"eess.AS", "Audio and Speech Processing", ""},
+	{"eess.IV", "Image and Video Processing", ""},
+	{"eess.SP", "Signal Processing", ""},
+	{"eess.SY", "Systems and Control", ""},
+
+	{"gr-qc", "General Relativity and Quantum Cosmology", ""},
+	{"hep-ex", "High Energy Physics - Experiment", ""},
+	{"hep-lat", "High Energy Physics - Lattice", ""},
+	{"hep-ph", "High Energy Physics - Phenomenology", ""},
+	{"hep-th", "High Energy Physics - Theory", ""},
+
+	{"math.AC", "Commutative Algebra", ""},
+	{"math.AG", "Algebraic Geometry", ""},
+	{"math.AP", "Analysis of PDEs", ""},
+	{"math.AT", "Algebraic Topology", ""},
+	{"math.CA", "Classical Analysis and ODEs", ""},
+	{"math.CO", "Combinatorics", ""},
+	{"math.CT", "Category Theory", ""},
+	{"math.CV", "Complex Variables", ""},
+	{"math.DG", "Differential Geometry", ""},
+	{"math.DS", "Dynamical Systems", ""},
+	{"math.FA", "Functional Analysis", ""},
+	{"math.GM", "General Mathematics", ""},
+	{"math.GN", "General Topology", ""},
+	{"math.GR", "Group Theory", ""},
+	{"math.GT", "Geometric Topology", ""},
+	{"math.HO", "History and Overview", ""},
+	{"math.IT", "Information Theory", "cs.IT"},
+	{"math.KT", "K-Theory and Homology", ""},
+	{"math.LO", "Logic", ""},
+	{"math.MG", "Metric Geometry", ""},
+	{"math.MP", "Mathematical Physics", "math-ph"},
+	{"math.NA", "Numerical Analysis", ""},
+	{"math.NT", "Number Theory", ""},
+	{"math.OA", "Operator Algebras", ""},
+	{"math.OC", "Optimization and Control", ""},
+	{"math.PR", "Probability", ""},
+	{"math.QA", "Quantum Algebra", ""},
+	{"math.RA", "Rings and Algebras", ""},
+	{"math.RT", "Representation Theory", ""},
+	{"math.SG", "Symplectic Geometry", ""},
+	{"math.SP", "Spectral Theory", ""},
+	{"math.ST", "Statistics Theory", ""},
+
+	{"math-ph", "Mathematical Physics", ""},
+
+	{"nlin.AO", "Adaptation and Self-Organizing Systems", ""},
+	{"nlin.CD", "Chaotic Dynamics", ""},
+	{"nlin.CG", "Cellular Automata and Lattice Gases", ""},
+	{"nlin.PS", "Pattern Formation and Solitons", ""},
+	{"nlin.SI", "Exactly Solvable and Integrable Systems", ""},
+
+	{"nucl-ex", "Nuclear Experiment", ""},
+	{"nucl-th", "Nuclear Theory", ""},
+
+	{"physics.acc-ph", "Accelerator Physics", ""},
+	{"physics.ao-ph", "Atmospheric and Oceanic Physics", ""},
+	{"physics.app-ph", "Applied Physics", ""},
+	{"physics.atm-clus", "Atomic and Molecular Clusters", ""},
+	{"physics.atom-ph", "Atomic Physics", ""},
+	{"physics.bio-ph", "Biological Physics", ""},
+	{"physics.chem-ph", "Chemical Physics", ""},
+	{"physics.class-ph", "Classical Physics", ""},
+	{"physics.comp-ph", "Computational Physics", ""},
+	{"physics.data-an", "Data Analysis, Statistics and Probability", ""},
+	{"physics.ed-ph", "Physics Education", ""},
+	{"physics.flu-dyn", "Fluid Dynamics", ""},
+	{"physics.gen-ph", "General Physics", ""},
+	{"physics.geo-ph", "Geophysics", ""},
+	{"physics.hist-ph", "History and Philosophy of Physics", ""},
+	{"physics.ins-det", "Instrumentation and Detectors", ""},
+	{"physics.med-ph", "Medical Physics", ""},
+	{"physics.optics", "Optics", ""},
+	{"physics.plasm-ph", "Plasma Physics", ""},
+	{"physics.pop-ph", "Popular Physics", ""},
+	{"physics.soc-ph", "Physics and Society", ""},
+	{"physics.space-ph", "Space Physics", ""},
+
+	{"q-bio.BM", "Biomolecules", ""},
+	{"q-bio.CB", "Cell Behavior", ""},
+	{"q-bio.GN", "Genomics", ""},
+	{"q-bio.MN", "Molecular Networks", ""},
+	{"q-bio.NC", "Neurons and Cognition", ""},
+	{"q-bio.OT", "Other Quantitative Biology", ""},
+	{"q-bio.PE", "Populations and Evolution", ""},
+	{"q-bio.QM", "Quantitative Methods", ""},
+	{"q-bio.SC", "Subcellular Processes", ""},
+	{"q-bio.TO", "Tissues and Organs", ""},
+
+	{"q-fin.CP", "Computational Finance", ""},
+	{"q-fin.EC", "Economics", "econ.GN"},
+	{"q-fin.GN", "General Finance", ""},
+	{"q-fin.MF", "Mathematical Finance", ""},
+	{"q-fin.PM", "Portfolio Management", ""},
+	{"q-fin.PR", "Pricing of Securities", ""},
+	{"q-fin.RM", "Risk Management", ""},
+	{"q-fin.ST", "Statistical Finance", ""},
+	{"q-fin.TR", "Trading and Market Microstructure", ""},
+
+	{"quant-ph", "Quantum Physics", ""},
+
+	{"stat.AP", "Applications", ""},
+	{"stat.CO", "Computation", ""},
+	{"stat.ME", "Methodology", ""},
+	{"stat.ML", "Machine Learning", ""},
+	{"stat.OT", "Other Statistics", ""},
+	{"stat.TH", "Statistics Theory", "math.ST"},
+}
+
+// merged lists the archives that were merged into categories of newer
+// archives, as {ID, Name, Group, Canonical}. They are deprecated but still
+// appear on older papers.
+var merged = [][4]string{
+	{"acc-phys", "Accelerator Physics", GroupPhysics, "physics.acc-ph"},
+	{"adap-org", "Adaptation, Noise, and Self-Organizing Systems", GroupPhysics, "nlin.AO"},
+	{"alg-geom", "Algebraic Geometry", GroupMath, "math.AG"},
+	{"ao-sci", "Atmospheric-Oceanic Sciences", GroupPhysics, "physics.ao-ph"},
+	{"atom-ph", "Atomic, Molecular and Optical Physics", GroupPhysics, "physics.atom-ph"},
+	{"bayes-an", "Bayesian Analysis", GroupPhysics, "physics.data-an"},
+	{"chao-dyn", "Chaotic Dynamics", GroupPhysics, "nlin.CD"},
+	{"chem-ph", "Chemical Physics", GroupPhysics, "physics.chem-ph"},
+	{"cmp-lg", "Computation and Language", GroupCS, "cs.CL"},
+	{"comp-gas", "Cellular Automata and Lattice Gases", GroupPhysics, "nlin.CG"},
+	{"dg-ga", "Differential Geometry", GroupMath, "math.DG"},
+	{"funct-an", "Functional Analysis", GroupMath, "math.FA"},
+	{"mtrl-th", "Materials Theory", GroupPhysics, "cond-mat.mtrl-sci"},
+	{"patt-sol", "Pattern Formation and Solitons", GroupPhysics, "nlin.PS"},
+	{"plasm-ph", "Plasma Physics", GroupPhysics, "physics.plasm-ph"},
+	{"q-alg", "Quantum Algebra and Topology", GroupMath, "math.QA"},
+	{"solv-int", "Exactly Solvable and Integrable Systems", GroupPhysics, "nlin.SI"},
+	{"supr-con", "Superconductivity", GroupPhysics, "cond-mat.supr-con"},
+}
+
+var (
+	all       []Category          // Current categories, sorted by ID
+	byID      map[string]Category // Every category, including merged archives
+	byFoldID  map[string]string   // Lower-cased ID to ID
+	archiveOf map[string]Archive
+	aliasesOf map[string][]string // Canonical ID to the IDs standing for it
+)
+
+func init() {
+	archiveOf = make(map[string]Archive, len(archives))
+	for _, a := range archives {
+		archiveOf[a.ID] = a
+	}
+
+	byID = make(map[string]Category, len(categories)+len(merged))
+	add := func(c Category) {
+		byID[c.ID] = c
+		if c.Canonical != "" {
+			aliasesOf[c.Canonical] = append(aliasesOf[c.Canonical], c.ID)
+		}
+	}
+	aliasesOf = make(map[string][]string)
+	for _, c := range categories {
+		archive, _, _ := strings.Cut(c[0], ".")
+		category := Category{ID: c[0], Name: c[1], Archive: archive, Group: archiveOf[archive].Group, Canonical: c[2]}
+		all = append(all, category)
+		add(category)
+	}
+	for _, m := range merged {
+		add(Category{ID: m[0], Name: m[1], Archive: m[0], Group: m[2], Canonical: m[3], Deprecated: true})
+	}
+	slices.SortFunc(all, func(a, b Category) int { return cmp.Compare(a.ID, b.ID) })
+
+	byFoldID = make(map[string]string, len(byID))
+	for id := range byID {
+		byFoldID[strings.ToLower(id)] = id
+	}
+}
+
+// Lookup returns the category with the given ID, which may be an alias or a
+// merged archive. IDs are matched case-insensitively, so "cs.lg" finds cs.LG.
+func Lookup(id string) (Category, bool) {
+	id, ok := byFoldID[strings.ToLower(strings.TrimSpace(id))]
+	if !ok {
+		return Category{}, false
+	}
+	return byID[id], true
+}
+
+// Valid reports whether id is a known category, alias or merged archive.
+func Valid(id string) bool {
+	_, ok := Lookup(id)
+	return ok
+}
+
+// Validate returns an error if id is not a known category, suggesting the
+// correctly-cased ID when only the case is wrong.
+func Validate(id string) error {
+	c, ok := Lookup(id)
+	switch {
+	case !ok:
+		return fmt.Errorf("taxonomy: unknown category %q", id)
+	case c.ID != id:
+		return fmt.Errorf("taxonomy: unknown category %q (did you mean %q?)", id, c.ID)
+	}
+	return nil
+}
+
+// Canonical returns the category an alias or merged archive stands for, the
+// correctly-cased ID of any other known category, and id unchanged if it is
+// not known.
+func Canonical(id string) string {
+	c, ok := Lookup(id)
+	switch {
+	case !ok:
+		return id
+	case c.Canonical != "":
+		return c.Canonical
+	}
+	return c.ID
+}
+
+// Aliases returns the other IDs that stand for the same category as id, such
+// as cs.IT for math.IT, sorted.
+func Aliases(id string) []string {
+	canonical := Canonical(id)
+	if !Valid(canonical) {
+		return nil
+	}
+	var ids []string
+	for _, alias := range append([]string{canonical}, aliasesOf[canonical]...) {
+		if !strings.EqualFold(alias, id) {
+			ids = append(ids, alias)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Equivalent reports whether two IDs stand for the same category.
+func Equivalent(a, b string) bool {
+	return Valid(a) && Canonical(a) == Canonical(b)
+}
+
+// Categories returns all current categories, including aliases but not
+// merged archives, sorted by ID.
+func Categories() []Category {
+	return slices.Clone(all)
+}
+
+// Archives returns all current archives, sorted by ID.
+func Archives() []Archive {
+	return slices.Clone(archives)
+}
+
+// LookupArchive returns the archive with the given ID, such as "cs".
+func LookupArchive(id string) (Archive, bool) {
+	a, ok := archiveOf[strings.ToLower(strings.TrimSpace(id))]
+	return a, ok
+}
+
+// Groups returns the names of all groups, sorted.
+func Groups() []string {
+	return []string{GroupCS, GroupEcon, GroupEESS, GroupMath, GroupPhysics, GroupQBio, GroupQFin, GroupStat}
+}
+
+// ByGroup returns the current categories of a group, such as GroupMath,
+// sorted by ID. The group name is matched case-insensitively.
+func ByGroup(group string) []Category {
+	var list []Category
+	for _, c := range all {
+		if strings.EqualFold(c.Group, group) {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// ByArchive returns the current categories of an archive, such as "cs",
+// sorted by ID. For an archive without subject classes, such as "hep-th",
+// that is the archive itself.
+func ByArchive(archive string) []Category {
+	var list []Category
+	for _, c := range all {
+		if strings.EqualFold(c.Archive, archive) {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// Expand returns the current categories whose IDs match a pattern, sorted by
+// ID. Patterns use the syntax of path.Match, so "cs.*" matches every
+// computer science category and "*.NA" both cs.NA and math.NA. A pattern
+// without wildcards matches the categories of the archive with that ID, or
+// else the category with that ID. IDs are matched case-insensitively.
+func Expand(pattern string) []Category {
+	pattern = strings.TrimSpace(pattern)
+	if !strings.ContainsAny(pattern, "*?[") {
+		if c, ok := Lookup(pattern); ok && !c.Deprecated {
+			if list := ByArchive(c.ID); len(list) > 1 {
+				return list
+			}
+			return []Category{c}
+		}
+		return ByArchive(pattern)
+	}
+	lower := strings.ToLower(pattern)
+	var list []Category
+	for _, c := range all {
+		if ok, _ := path.Match(lower, strings.ToLower(c.ID)); ok {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// IsPattern reports whether s contains the wildcards Expand accepts.
+func IsPattern(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
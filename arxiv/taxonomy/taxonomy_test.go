@@ -0,0 +1,159 @@
+package taxonomy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		id   string
+		want Category
+		ok   bool
+	}{
+		{"cs.LG", Category{ID: "cs.LG", Name: "Machine Learning", Archive: "cs", Group: GroupCS}, true},
+		{"cs.lg", Category{ID: "cs.LG", Name: "Machine Learning", Archive: "cs", Group: GroupCS}, true},
+		{" hep-th ", Category{ID: "hep-th", Name: "High Energy Physics - Theory", Archive: "hep-th", Group: GroupPhysics}, true},
+		{"math.IT", Category{ID: "math.IT", Name: "Information Theory", Archive: "math", Group: GroupMath, Canonical: "cs.IT"}, true},
+		{"solv-int", Category{ID: "solv-int", Name: "Exactly Solvable and Integrable Systems", Archive: "solv-int", Group: GroupPhysics, Canonical: "nlin.SI", Deprecated: true}, true},
+		{"cs", Category{}, false},
+		{"cs.XX", Category{}, false},
+		{"", Category{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := Lookup(tt.id)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("Lookup(%q) = %+v, %v, want %+v, %v", tt.id, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		id      string
+		wantErr string
+	}{
+		{"cs.LG", ""},
+		{"q-fin.EC", ""},
+		{"chao-dyn", ""},
+		{"cs.lg", `did you mean "cs.LG"`},
+		{"physics.quant-ph", `unknown category "physics.quant-ph"`},
+	}
+	for _, tt := range tests {
+		err := Validate(tt.id)
+		if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("Validate(%q) = %v, want %q", tt.id, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCanonicalAndAliases(t *testing.T) {
+	tests := []struct {
+		id        string
+		canonical string
+		aliases   []string
+	}{
+		{"math.IT", "cs.IT", []string{"cs.IT"}},
+		{"cs.IT", "cs.IT", []string{"math.IT"}},
+		{"math.MP", "math-ph", []string{"math-ph"}},
+		{"math-ph", "math-ph", []string{"math.MP"}},
+		{"stat.TH", "math.ST", []string{"math.ST"}},
+		{"q-fin.EC", "econ.GN", []string{"econ.GN"}},
+		{"cs.NA", "math.NA", []string{"math.NA"}},
+		{"cs.SY", "eess.SY", []string{"eess.SY"}},
+		{"nlin.SI", "nlin.SI", []string{"solv-int"}},
+		{"cmp-lg", "cs.CL", []string{"cs.CL"}},
+		{"cs.lg", "cs.LG", nil},
+		{"cs.XX", "cs.XX", nil},
+	}
+	for _, tt := range tests {
+		if got := Canonical(tt.id); got != tt.canonical {
+			t.Errorf("Canonical(%q) = %q, want %q", tt.id, got, tt.canonical)
+		}
+		if got := Aliases(tt.id); !reflect.DeepEqual(got, tt.aliases) {
+			t.Errorf("Aliases(%q) = %q, want %q", tt.id, got, tt.aliases)
+		}
+	}
+	if !Equivalent("math.IT", "cs.IT") || Equivalent("cs.LG", "stat.ML") || Equivalent("cs.XX", "cs.XX") {
+		t.Error("Equivalent gave the wrong answer")
+	}
+}
+
+func TestTaxonomyIsConsistent(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, c := range Categories() {
+		if seen[c.ID] {
+			t.Errorf("category %s listed twice", c.ID)
+		}
+		seen[c.ID] = true
+		if c.Name == "" || c.Group == "" || c.Deprecated {
+			t.Errorf("category %+v is incomplete", c)
+		}
+		if _, ok := LookupArchive(c.Archive); !ok {
+			t.Errorf("category %s has unknown archive %q", c.ID, c.Archive)
+		}
+	}
+	for id, c := range byID {
+		if c.Canonical == "" {
+			continue
+		}
+		if target, ok := byID[c.Canonical]; !ok || target.Canonical != "" {
+			t.Errorf("%s stands for %q, which is not a canonical category", id, c.Canonical)
+		}
+	}
+	total := 0
+	for _, group := range Groups() {
+		total += len(ByGroup(group))
+	}
+	if total != len(Categories()) {
+		t.Errorf("groups hold %d categories, want %d", total, len(Categories()))
+	}
+}
+
+func TestExpand(t *testing.T) {
+	ids := func(list []Category) []string {
+		var ids []string
+		for _, c := range list {
+			ids = append(ids, c.ID)
+		}
+		return ids
+	}
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"econ.*", []string{"econ.EM", "econ.GN", "econ.TH"}},
+		{"ECON.*", []string{"econ.EM", "econ.GN", "econ.TH"}},
+		{"econ", []string{"econ.EM", "econ.GN", "econ.TH"}},
+		{"*.NA", []string{"cs.NA", "math.NA"}},
+		{"eess.S?", []string{"eess.SP", "eess.SY"}},
+		{"hep-th", []string{"hep-th"}},
+		{"cs.LG", []string{"cs.LG"}},
+		{"solv-int", nil},
+		{"bogus.*", nil},
+	}
+	for _, tt := range tests {
+		if got := ids(Expand(tt.pattern)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Expand(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+	if n := len(Expand("astro-ph*")); n != 7 {
+		t.Errorf("Expand(astro-ph*) = %d categories, want 7", n)
+	}
+}
+
+func TestByGroup(t *testing.T) {
+	fin := ByGroup("quantitative finance")
+	if len(fin) != 9 || fin[0].ID != "q-fin.CP" {
+		t.Errorf("ByGroup(quantitative finance) = %v", fin)
+	}
+	for _, c := range ByGroup(GroupPhysics) {
+		if strings.HasPrefix(c.ID, "cs.") || strings.HasPrefix(c.ID, "math.") {
+			t.Errorf("physics group contains %s", c.ID)
+		}
+	}
+	if ByGroup("Chemistry") != nil {
+		t.Error("ByGroup of an unknown group is not empty")
+	}
+}
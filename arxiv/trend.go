@@ -0,0 +1,289 @@
+package arxiv
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Period is a length of time that counts are grouped by.
+type Period int
+
+// Periods, from shortest to longest. Weeks start on Monday.
+const (
+	Day Period = iota
+	Week
+	Month
+	Quarter
+	Year
+)
+
+var periodNames = []string{"day", "week", "month", "quarter", "year"}
+
+func (p Period) String() string {
+	if p >= 0 && int(p) < len(periodNames) {
+		return periodNames[p]
+	}
+	return fmt.Sprintf("Period(%d)", int(p))
+}
+
+// ParsePeriod parses the name of a period, as returned by Period.String.
+func ParsePeriod(name string) (Period, error) {
+	if i := slices.Index(periodNames, strings.ToLower(name)); i >= 0 {
+		return Period(i), nil
+	}
+	return 0, fmt.Errorf("unknown period %q (want day, week, month, quarter or year)", name)
+}
+
+// Start returns the start of the period holding t, in UTC.
+func (p Period) Start(t time.Time) time.Time {
+	t = t.UTC()
+	switch p {
+	case Week:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case Month:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case Quarter:
+		return time.Date(t.Year(), t.Month()-(t.Month()-1)%3, 1, 0, 0, 0, 0, time.UTC)
+	case Year:
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Next returns the start of the period after the one holding t.
+func (p Period) Next(t time.Time) time.Time {
+	start := p.Start(t)
+	switch p {
+	case Week:
+		return start.AddDate(0, 0, 7)
+	case Month:
+		return start.AddDate(0, 1, 0)
+	case Quarter:
+		return start.AddDate(0, 3, 0)
+	case Year:
+		return start.AddDate(1, 0, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// Window is a span of time from Start up to, but not including, End.
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Windows returns the periods covering the time from start up to end, the
+// first starting at or before start.
+func Windows(start, end time.Time, period Period) []Window {
+	var windows []Window
+	for t := period.Start(start); t.Before(end); t = period.Next(t) {
+		windows = append(windows, Window{Start: t, End: period.Next(t)})
+	}
+	return windows
+}
+
+// TrendOptions sets the windows of a trend and how its counts are split.
+type TrendOptions struct {
+	Start  time.Time // Start of the first window
+	End    time.Time // End of the last window (default now)
+	Period Period    // Length of each window
+
+	// Categories splits the counts into a series for each category, such
+	// as "cs.CL".
+	Categories []string
+	// Terms splits the counts into a series for each term. A term in query
+	// syntax, such as `ti:"language model"`, is used as is; others are
+	// searched for in all fields.
+	Terms []string
+}
+
+// TrendSeries is the number of matching entries in each window of a trend.
+type TrendSeries struct {
+	Label  string // Category and term of the series, or the query if it is not split
+	Query  string // Query counted in each window, without the date range
+	Counts []int  // Count for each window
+}
+
+// Total returns the sum of the counts.
+func (s TrendSeries) Total() int {
+	total := 0
+	for _, n := range s.Counts {
+		total += n
+	}
+	return total
+}
+
+// Trend is a time series of counts of search results.
+type Trend struct {
+	Windows []Window
+	Series  []TrendSeries
+}
+
+// Trend counts the entries matching query submitted in each window from
+// opts.Start to opts.End, without downloading them: each count is one
+// request for a single result, from which only TotalResults is read. With
+// Categories or Terms, there is a series for each category, each term, or
+// each pair of both; otherwise a single series. Requests run as by
+// SearchMany, so a trend over many windows takes a while at the default
+// rate limit. The query may be nil if Categories or Terms are given.
+func (c *Client) Trend(ctx context.Context, query *SearchQuery, opts TrendOptions) (Trend, error) {
+	if opts.End.IsZero() {
+		opts.End = time.Now()
+	}
+	if opts.Start.IsZero() || !opts.Start.Before(opts.End) {
+		return Trend{}, errors.New("arxiv: trend needs a start before its end")
+	}
+	trend := Trend{Windows: Windows(opts.Start, opts.End, opts.Period)}
+
+	categories, terms := opts.Categories, opts.Terms
+	if len(categories) == 0 {
+		categories = []string{""}
+	}
+	if len(terms) == 0 {
+		terms = []string{""}
+	}
+	var queries []*SearchQuery
+	for _, category := range categories {
+		for _, term := range terms {
+			q, err := trendQuery(query, category, term)
+			if err != nil {
+				return Trend{}, err
+			}
+			label := joinNonEmpty(category, term)
+			if label == "" {
+				label = q.String()
+			}
+			trend.Series = append(trend.Series, TrendSeries{Label: label, Query: q.String()})
+			queries = append(queries, q)
+		}
+	}
+
+	var params []SearchParams
+	for _, q := range queries {
+		for _, w := range trend.Windows {
+			windowed := &SearchQuery{nodes: slices.Clone(q.nodes)}
+			// Date ranges include their end minute.
+			windowed.SubmittedBetween(w.Start, w.End.Add(-time.Minute))
+			params = append(params, SearchParams{Query: windowed.String(), MaxResults: 1})
+		}
+	}
+	results := c.SearchMany(ctx, params)
+	for i := range trend.Series {
+		counts := make([]int, len(trend.Windows))
+		for j := range counts {
+			result := results[i*len(counts)+j]
+			if result.Err != nil {
+				return Trend{}, fmt.Errorf("arxiv: counting %s in %s: %w", trend.Series[i].Label, trend.Windows[j].Start.Format(time.DateOnly), result.Err)
+			}
+			counts[j] = result.Results.TotalResults
+		}
+		trend.Series[i].Counts = counts
+	}
+	return trend, nil
+}
+
+// trendQuery returns query restricted to a category and a term, either of
+// which may be empty.
+func trendQuery(query *SearchQuery, category, term string) (*SearchQuery, error) {
+	q := NewSearchQuery()
+	if query != nil {
+		q.appendGroup(query.nodes)
+	}
+	if category != "" {
+		q.And().Category(category)
+	}
+	if term != "" {
+		t := NewSearchQuery()
+		if strings.Contains(term, ":") {
+			parsed, err := ParseSearchQuery(term)
+			if err != nil {
+				return nil, fmt.Errorf("arxiv: trend term %q: %w", term, err)
+			}
+			t = parsed
+		} else if strings.ContainsRune(strings.TrimSpace(term), ' ') {
+			t.All(`"` + strings.Trim(term, `" `) + `"`)
+		} else {
+			t.All(strings.TrimSpace(term))
+		}
+		q.And().appendGroup(t.nodes)
+	}
+	if len(q.nodes) == 0 {
+		return nil, errors.New("arxiv: trend needs a query, categories or terms")
+	}
+	return q, nil
+}
+
+// appendGroup appends nodes, in parentheses if there are several.
+func (q *SearchQuery) appendGroup(nodes []queryNode) *SearchQuery {
+	switch len(nodes) {
+	case 0:
+	case 1:
+		q.nodes = append(q.nodes, nodes[0])
+	default:
+		q.nodes = append(q.nodes, &groupQuery{nodes: slices.Clone(nodes)})
+	}
+	return q
+}
+
+// WriteCSV writes the trend as CSV, with a row for each window giving its
+// start and end dates and then the count of each series.
+func (t Trend) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"start", "end"}
+	for _, s := range t.Series {
+		header = append(header, s.Label)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for i, window := range t.Windows {
+		row := []string{window.Start.Format(time.DateOnly), window.End.Format(time.DateOnly)}
+		for _, s := range t.Series {
+			row = append(row, strconv.Itoa(s.Counts[i]))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Sparkline ramps, from lowest to highest.
+const (
+	SparkBlocks = "▁▂▃▄▅▆▇█"
+	SparkASCII  = "_.-=+*#@"
+)
+
+// Sparkline draws counts as a line of characters from ramp, such as
+// SparkBlocks or SparkASCII, scaled from zero to the largest count. Zero
+// counts use the first character and the largest the last.
+func Sparkline(counts []int, ramp string) string {
+	levels := []rune(ramp)
+	if len(levels) == 0 {
+		levels = []rune(SparkBlocks)
+	}
+	top := 0
+	for _, n := range counts {
+		top = max(top, n)
+	}
+	var b strings.Builder
+	for _, n := range counts {
+		level := 0
+		if top > 0 && n > 0 {
+			// Any count above zero rises off the bottom.
+			level = 1 + (n*(len(levels)-1)-1)/top
+			level = min(max(level, 1), len(levels)-1)
+		}
+		b.WriteRune(levels[level])
+	}
+	return b.String()
+}
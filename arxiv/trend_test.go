@@ -0,0 +1,193 @@
+package arxiv_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+	"github.com/Epistemic-Technology/arxiv/arxiv/arxivtest"
+)
+
+// trendEntries returns 30 entries submitted every three days from the
+// start of 2024, in cs.CL or cs.LG, every other one about language.
+func trendEntries() []arxiv.EntryMetadata {
+	var entries []arxiv.EntryMetadata
+	for i := range 30 {
+		category := "cs.LG"
+		if i%3 == 0 {
+			category = "cs.CL"
+		}
+		title := fmt.Sprintf("Paper %d", i)
+		if i%2 == 0 {
+			title = fmt.Sprintf("Language models %d", i)
+		}
+		published := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC).AddDate(0, 0, 3*i)
+		entries = append(entries, arxiv.EntryMetadata{
+			ID:              fmt.Sprintf("http://arxiv.org/abs/2401.%05dv1", i+1),
+			Title:           title,
+			Published:       published,
+			Updated:         published,
+			PrimaryCategory: arxiv.Category{Term: category},
+			Categories:      []arxiv.Category{{Term: category}},
+		})
+	}
+	return entries
+}
+
+// countByMonth counts the entries matching keep in each month of 2024 from
+// January to March.
+func countByMonth(entries []arxiv.EntryMetadata, keep func(arxiv.EntryMetadata) bool) []int {
+	counts := make([]int, 3)
+	for _, e := range entries {
+		if keep(e) {
+			counts[e.Published.Month()-1]++
+		}
+	}
+	return counts
+}
+
+func TestTrend(t *testing.T) {
+	entries := trendEntries()
+	server := arxivtest.NewServer(entries...)
+	defer server.Close()
+	client := server.Client()
+	opts := arxiv.TrendOptions{
+		Start:  time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		End:    time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+		Period: arxiv.Month,
+	}
+	language := func(e arxiv.EntryMetadata) bool { return strings.HasPrefix(e.Title, "Language") }
+	inCategory := func(category string) func(arxiv.EntryMetadata) bool {
+		return func(e arxiv.EntryMetadata) bool { return e.PrimaryCategory.Term == category }
+	}
+
+	trend, err := client.Trend(context.Background(), arxiv.NewSearchQuery().Title("language"), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trend.Windows) != 3 || !trend.Windows[0].Start.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) ||
+		!trend.Windows[2].End.Equal(opts.End) {
+		t.Errorf("windows = %+v", trend.Windows)
+	}
+	if len(trend.Series) != 1 || trend.Series[0].Label != "ti:language" {
+		t.Fatalf("series = %+v", trend.Series)
+	}
+	if got, want := trend.Series[0].Counts, countByMonth(entries, language); !slices.Equal(got, want) {
+		t.Errorf("counts = %v, want %v", got, want)
+	}
+	if got := trend.Series[0].Total(); got != 15 {
+		t.Errorf("Total() = %d, want 15", got)
+	}
+	for _, p := range server.Requests() {
+		if p.MaxResults != 1 || !strings.Contains(p.Query, "submittedDate:[") {
+			t.Errorf("request = %+v, want one result in a date range", p)
+		}
+	}
+
+	opts.Categories = []string{"cs.CL", "cs.LG"}
+	opts.Terms = []string{"language", `ti:"models"`}
+	trend, err = client.Trend(context.Background(), nil, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var labels []string
+	for _, s := range trend.Series {
+		labels = append(labels, s.Label)
+	}
+	if want := []string{"cs.CL language", `cs.CL ti:"models"`, "cs.LG language", `cs.LG ti:"models"`}; !slices.Equal(labels, want) {
+		t.Fatalf("labels = %q, want %q", labels, want)
+	}
+	for i, category := range opts.Categories {
+		want := countByMonth(entries, func(e arxiv.EntryMetadata) bool { return language(e) && inCategory(category)(e) })
+		if got := trend.Series[2*i].Counts; !slices.Equal(got, want) {
+			t.Errorf("%s counts = %v, want %v", trend.Series[2*i].Label, got, want)
+		}
+	}
+	if got, want := trend.Series[0].Query, `cat:cs.CL AND all:language`; got != want {
+		t.Errorf("Query = %q, want %q", got, want)
+	}
+
+	opts.Categories, opts.Terms = nil, nil
+	if _, err := client.Trend(context.Background(), nil, opts); err == nil {
+		t.Error("Trend without a query succeeded")
+	}
+	opts.Start = opts.End
+	if _, err := client.Trend(context.Background(), arxiv.NewSearchQuery().Title("x"), opts); err == nil {
+		t.Error("Trend with an empty span succeeded")
+	}
+
+	server.Inject(arxivtest.Status(500))
+	opts.Start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := client.Trend(context.Background(), arxiv.NewSearchQuery().Title("x"), opts); err == nil {
+		t.Error("Trend succeeded despite a server error")
+	}
+}
+
+func TestTrendWriteCSV(t *testing.T) {
+	trend := arxiv.Trend{
+		Windows: arxiv.Windows(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), arxiv.Month),
+		Series: []arxiv.TrendSeries{
+			{Label: "cs.CL", Counts: []int{3, 5}},
+			{Label: `all:"a, b"`, Counts: []int{0, 1}},
+		},
+	}
+	var b strings.Builder
+	if err := trend.WriteCSV(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := "start,end,cs.CL,\"all:\"\"a, b\"\"\"\n2024-01-01,2024-02-01,3,0\n2024-02-01,2024-03-01,5,1\n"
+	if b.String() != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", b.String(), want)
+	}
+}
+
+func TestPeriods(t *testing.T) {
+	at := time.Date(2024, 5, 19, 15, 30, 0, 0, time.UTC) // A Sunday
+	tests := []struct {
+		period      arxiv.Period
+		start, next string
+	}{
+		{arxiv.Day, "2024-05-19", "2024-05-20"},
+		{arxiv.Week, "2024-05-13", "2024-05-20"},
+		{arxiv.Month, "2024-05-01", "2024-06-01"},
+		{arxiv.Quarter, "2024-04-01", "2024-07-01"},
+		{arxiv.Year, "2024-01-01", "2025-01-01"},
+	}
+	for _, tt := range tests {
+		if got := tt.period.Start(at).Format(time.DateOnly); got != tt.start {
+			t.Errorf("%v Start = %s, want %s", tt.period, got, tt.start)
+		}
+		if got := tt.period.Next(at).Format(time.DateOnly); got != tt.next {
+			t.Errorf("%v Next = %s, want %s", tt.period, got, tt.next)
+		}
+		if p, err := arxiv.ParsePeriod(strings.ToUpper(tt.period.String())); err != nil || p != tt.period {
+			t.Errorf("ParsePeriod(%q) = %v, %v", tt.period, p, err)
+		}
+	}
+	if _, err := arxiv.ParsePeriod("fortnight"); err == nil {
+		t.Error("ParsePeriod(fortnight) succeeded")
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		counts []int
+		ramp   string
+		want   string
+	}{
+		{[]int{0, 1, 2, 4, 8}, arxiv.SparkBlocks, "▁▂▃▅█"},
+		{[]int{0, 1, 2, 4, 8}, arxiv.SparkASCII, "_.-+@"},
+		{[]int{0, 0}, arxiv.SparkASCII, "__"},
+		{[]int{5, 5}, "", "██"},
+		{nil, arxiv.SparkASCII, ""},
+	}
+	for _, tt := range tests {
+		if got := arxiv.Sparkline(tt.counts, tt.ramp); got != tt.want {
+			t.Errorf("Sparkline(%v, %q) = %q, want %q", tt.counts, tt.ramp, got, tt.want)
+		}
+	}
+}
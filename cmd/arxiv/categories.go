@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv/taxonomy"
+)
+
+var categoriesCommand = &command{
+	name:    "categories",
+	usage:   "[flags] [pattern]...",
+	summary: "list arXiv categories",
+	help: `
+Categories lists the subject categories that can be used in cat: query
+terms, grouped as on arXiv's front page. Patterns select categories by ID,
+either exactly, by archive or with wildcards:
+
+	arxiv categories cs.LG
+	arxiv categories math 'stat.*'
+	arxiv categories -group 'Quantitative Biology'
+
+Some categories are aliases of others, such as math.IT of cs.IT; they are
+listed with the category they stand for. Use -deprecated to also list the
+old archives that were merged into current categories, such as solv-int.`,
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, a *app, args []string) error {
+		group := fs.String("group", "", "only list categories of this `group`")
+		deprecated := fs.Bool("deprecated", false, "also list archives merged into other categories")
+		return func(ctx context.Context, a *app, args []string) error {
+			if *group != "" && !slices.ContainsFunc(taxonomy.Groups(), func(g string) bool { return strings.EqualFold(g, *group) }) {
+				return usageErrorf("unknown group %q; groups are: %s", *group, strings.Join(taxonomy.Groups(), ", "))
+			}
+
+			var list []taxonomy.Category
+			if len(args) == 0 {
+				list = taxonomy.Categories()
+			}
+			for _, pattern := range args {
+				matches := taxonomy.Expand(pattern)
+				if c, ok := taxonomy.Lookup(pattern); ok && c.Deprecated {
+					matches = []taxonomy.Category{c}
+				}
+				if len(matches) == 0 {
+					return usageErrorf("no category matches %q", pattern)
+				}
+				list = append(list, matches...)
+			}
+			if *deprecated && len(args) == 0 {
+				list = append(list, deprecatedCategories()...)
+			}
+			writeCategories(a, list, *group)
+			return nil
+		}
+	},
+}
+
+// deprecatedCategories returns the merged archives, which Categories omits.
+func deprecatedCategories() []taxonomy.Category {
+	var list []taxonomy.Category
+	for _, c := range taxonomy.Categories() {
+		for _, alias := range taxonomy.Aliases(c.ID) {
+			if d, ok := taxonomy.Lookup(alias); ok && d.Deprecated {
+				list = append(list, d)
+			}
+		}
+	}
+	return list
+}
+
+// writeCategories prints the categories under a heading per group, in the
+// order of taxonomy.Groups, leaving out those of other groups if group is set.
+func writeCategories(a *app, list []taxonomy.Category, group string) {
+	slices.SortFunc(list, func(x, y taxonomy.Category) int { return strings.Compare(x.ID, y.ID) })
+	list = slices.CompactFunc(list, func(x, y taxonomy.Category) bool { return x.ID == y.ID })
+
+	first := true
+	for _, g := range taxonomy.Groups() {
+		if group != "" && !strings.EqualFold(g, group) {
+			continue
+		}
+		var inGroup []taxonomy.Category
+		for _, c := range list {
+			if c.Group == g {
+				inGroup = append(inGroup, c)
+			}
+		}
+		if len(inGroup) == 0 {
+			continue
+		}
+		if !first {
+			fmt.Fprintln(a.stdout)
+		}
+		first = false
+		fmt.Fprintf(a.stdout, "%s\n", g)
+		tw := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+		for _, c := range inGroup {
+			var note string
+			switch {
+			case c.Deprecated:
+				note = "\t(merged into " + c.Canonical + ")"
+			case c.IsAlias():
+				note = "\t(alias of " + c.Canonical + ")"
+			}
+			fmt.Fprintf(tw, "  %s\t%s%s\n", c.ID, c.Name, note)
+		}
+		tw.Flush()
+	}
+}
@@ -0,0 +1,388 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+var configCommand = &command{
+	name:    "config",
+	usage:   "show",
+	summary: "show the effective configuration",
+	help: `
+Config show prints the client settings in effect after reading the config
+file, the selected profile and the environment, along with where each
+setting came from, and the saved queries.
+
+The config file is read from -config, $ARXIV_CONFIG or
+$XDG_CONFIG_HOME/arxiv/config.toml (~/.config/arxiv/config.toml), and may
+look like this:
+
+	profile = "default"     # profile used when -profile is not given
+	timeout = "30s"
+	rate_limit = "3s"
+
+	[retry]
+	max_attempts = 5
+	initial_interval = "2s"
+
+	[profiles.mirror]
+	base_url = "http://localhost:8080/api/query"
+	rate_limit = "0s"
+
+	[queries]
+	llm = 'cat:cs.CL AND abs:"large language model"'
+
+Settings at the top level apply to every profile. A profile's settings
+override them, and these environment variables override both:
+
+	ARXIV_BASE_URL, ARXIV_TIMEOUT, ARXIV_RATE_LIMIT,
+	ARXIV_RETRY_MAX_ATTEMPTS, ARXIV_RETRY_INITIAL_INTERVAL,
+	ARXIV_RETRY_MAX_INTERVAL, ARXIV_RETRY_MULTIPLIER
+
+The profile is chosen with -profile, $ARXIV_PROFILE or the profile key of
+the config file. Saved queries can be used in place of a query by any
+command that takes one, by writing their name after an @:
+
+	arxiv search @llm`,
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, a *app, args []string) error {
+		return func(ctx context.Context, a *app, args []string) error {
+			if len(args) != 1 || args[0] != "show" {
+				return usageErrorf("expected 'show'")
+			}
+			return a.settings.write(a.stdout)
+		}
+	},
+}
+
+// Sources of settings, from lowest to highest precedence.
+const (
+	sourceDefault = "default"
+	sourceFile    = "config file"
+	sourceEnv     = "environment"
+)
+
+// configFile is the contents of a config file.
+type configFile struct {
+	Profile string `toml:"profile"`
+	profileConfig
+	Profiles map[string]profileConfig `toml:"profiles"`
+	Queries  map[string]string        `toml:"queries"`
+}
+
+// profileConfig holds the client settings of one layer of configuration.
+// Empty values leave the setting of the layer below unchanged.
+type profileConfig struct {
+	BaseURL   string       `toml:"base_url"`
+	Timeout   string       `toml:"timeout"`
+	RateLimit string       `toml:"rate_limit"`
+	Retry     *retryConfig `toml:"retry"`
+}
+
+type retryConfig struct {
+	MaxAttempts     int     `toml:"max_attempts"`
+	InitialInterval string  `toml:"initial_interval"`
+	MaxInterval     string  `toml:"max_interval"`
+	Multiplier      float64 `toml:"multiplier"`
+}
+
+// settings are the effective client settings and saved queries.
+type settings struct {
+	file    string // Config file that was read, if any
+	profile string // Selected profile, if any
+
+	baseURL   string
+	timeout   time.Duration
+	rateLimit time.Duration
+	retry     *arxiv.RetryConfig // nil when retry is disabled
+
+	sources map[string]string // Where each setting came from, by name
+	queries map[string]string
+}
+
+// loadSettings reads the config file and environment. configPath and profile
+// are the values of the -config and -profile flags.
+func (a *app) loadSettings(configPath, profile string) (*settings, error) {
+	defaults := arxiv.NewClient(arxiv.WithRateLimit(0))
+	s := &settings{
+		baseURL:   defaults.BaseURL,
+		timeout:   defaults.Timeout,
+		rateLimit: arxiv.DefaultRateLimit,
+		sources:   make(map[string]string),
+		queries:   make(map[string]string),
+	}
+
+	required := true
+	if configPath == "" {
+		configPath = a.getenv("ARXIV_CONFIG")
+	}
+	if configPath == "" {
+		configPath = a.defaultConfigPath()
+		required = false
+	}
+	var file configFile
+	if configPath != "" {
+		_, err := toml.DecodeFile(configPath, &file)
+		switch {
+		case err == nil:
+			s.file = configPath
+		case errors.Is(err, fs.ErrNotExist) && !required:
+		default:
+			return nil, fmt.Errorf("reading config: %w", err)
+		}
+	}
+
+	if profile == "" {
+		profile = a.getenv("ARXIV_PROFILE")
+	}
+	if profile == "" {
+		profile = file.Profile
+	}
+
+	if err := s.apply(sourceFile, file.profileConfig); err != nil {
+		return nil, err
+	}
+	if profile != "" {
+		p, ok := file.Profiles[profile]
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q", profile)
+		}
+		s.profile = profile
+		if err := s.apply("profile "+profile, p); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.apply(sourceEnv, a.envConfig()); err != nil {
+		return nil, err
+	}
+
+	for name, query := range file.Queries {
+		if !arxiv.IsValidSearchQuery(query) {
+			_, err := arxiv.ParseSearchQuery(query)
+			return nil, fmt.Errorf("saved query %q: %v", name, err)
+		}
+		s.queries[name] = query
+	}
+	return s, nil
+}
+
+// defaultConfigPath returns the path of the config file read when none is
+// given, or "" if the user's config directory is unknown.
+func (a *app) defaultConfigPath() string {
+	dir := a.getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home := a.getenv("HOME")
+		if home == "" {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "arxiv", "config.toml")
+}
+
+// envConfig returns the settings given by ARXIV_* environment variables.
+func (a *app) envConfig() profileConfig {
+	p := profileConfig{
+		BaseURL:   a.getenv("ARXIV_BASE_URL"),
+		Timeout:   a.getenv("ARXIV_TIMEOUT"),
+		RateLimit: a.getenv("ARXIV_RATE_LIMIT"),
+	}
+	var r retryConfig
+	var err error
+	set := false
+	if v := a.getenv("ARXIV_RETRY_MAX_ATTEMPTS"); v != "" {
+		set = true
+		if r.MaxAttempts, err = strconv.Atoi(v); err != nil {
+			r.MaxAttempts = -1 // Reported as invalid by apply
+		}
+	}
+	if v := a.getenv("ARXIV_RETRY_MULTIPLIER"); v != "" {
+		set = true
+		if r.Multiplier, err = strconv.ParseFloat(v, 64); err != nil {
+			r.Multiplier = -1
+		}
+	}
+	r.InitialInterval = a.getenv("ARXIV_RETRY_INITIAL_INTERVAL")
+	r.MaxInterval = a.getenv("ARXIV_RETRY_MAX_INTERVAL")
+	if set || r.InitialInterval != "" || r.MaxInterval != "" {
+		p.Retry = &r
+	}
+	return p
+}
+
+func (a *app) getenv(key string) string {
+	if a.env == nil {
+		return ""
+	}
+	return a.env(key)
+}
+
+// apply overrides the settings with the non-empty values of p.
+func (s *settings) apply(source string, p profileConfig) error {
+	if p.BaseURL != "" {
+		s.baseURL = p.BaseURL
+		s.sources["base_url"] = source
+	}
+	if p.Timeout != "" {
+		d, err := parseDuration(source, "timeout", p.Timeout)
+		if err != nil {
+			return err
+		}
+		s.timeout = d
+		s.sources["timeout"] = source
+	}
+	if p.RateLimit != "" {
+		d, err := parseDuration(source, "rate_limit", p.RateLimit)
+		if err != nil {
+			return err
+		}
+		s.rateLimit = d
+		s.sources["rate_limit"] = source
+	}
+	if r := p.Retry; r != nil {
+		if s.retry == nil {
+			s.retry = &arxiv.RetryConfig{
+				MaxAttempts:     3,
+				InitialInterval: time.Second,
+				MaxInterval:     30 * time.Second,
+				Multiplier:      2,
+			}
+		}
+		if r.MaxAttempts < 0 {
+			return fmt.Errorf("%s: invalid retry max_attempts", source)
+		}
+		if r.MaxAttempts > 0 {
+			s.retry.MaxAttempts = r.MaxAttempts
+		}
+		if r.InitialInterval != "" {
+			d, err := parseDuration(source, "retry initial_interval", r.InitialInterval)
+			if err != nil {
+				return err
+			}
+			s.retry.InitialInterval = d
+		}
+		if r.MaxInterval != "" {
+			d, err := parseDuration(source, "retry max_interval", r.MaxInterval)
+			if err != nil {
+				return err
+			}
+			s.retry.MaxInterval = d
+		}
+		if r.Multiplier < 0 {
+			return fmt.Errorf("%s: invalid retry multiplier", source)
+		}
+		if r.Multiplier > 0 {
+			s.retry.Multiplier = r.Multiplier
+		}
+		s.sources["retry"] = source
+	}
+	return nil
+}
+
+func parseDuration(source, name, value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%s: invalid %s %q", source, name, value)
+	}
+	return d, nil
+}
+
+// clientOptions returns the options that configure a client with the
+// settings. Settings left at their defaults add no options, so the client
+// keeps sharing the default rate limiter.
+func (s *settings) clientOptions() []arxiv.ClientOption {
+	var options []arxiv.ClientOption
+	if _, ok := s.sources["base_url"]; ok {
+		options = append(options, arxiv.WithBaseURL(s.baseURL))
+	}
+	if _, ok := s.sources["timeout"]; ok {
+		options = append(options, arxiv.WithTimeout(s.timeout))
+	}
+	if _, ok := s.sources["rate_limit"]; ok {
+		options = append(options, arxiv.WithRateLimit(s.rateLimit))
+	}
+	if s.retry != nil {
+		options = append(options, arxiv.WithRetry(*s.retry))
+	}
+	return options
+}
+
+// query returns the query to run for q, expanding a saved query written as
+// "@name".
+func (s *settings) query(q string) (string, error) {
+	name, ok := strings.CutPrefix(q, "@")
+	if !ok {
+		return q, nil
+	}
+	query, ok := s.queries[name]
+	if !ok {
+		return "", usageErrorf("unknown saved query %q", name)
+	}
+	return query, nil
+}
+
+// write prints the settings for "arxiv config show".
+func (s *settings) write(w io.Writer) error {
+	source := func(name string) string {
+		if source, ok := s.sources[name]; ok {
+			return source
+		}
+		return sourceDefault
+	}
+	file := s.file
+	if file == "" {
+		file = "(none)"
+	}
+	profile := s.profile
+	if profile == "" {
+		profile = "(none)"
+	}
+	retry := "disabled"
+	if r := s.retry; r != nil {
+		retry = fmt.Sprintf("%d attempts, %v to %v, x%g", r.MaxAttempts, r.InitialInterval, r.MaxInterval, r.Multiplier)
+	}
+
+	fmt.Fprintf(w, "Config file: %s\nProfile: %s\n\n", file, profile)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "base_url\t%s\t(%s)\n", s.baseURL, source("base_url"))
+	fmt.Fprintf(tw, "timeout\t%v\t(%s)\n", s.timeout, source("timeout"))
+	fmt.Fprintf(tw, "rate_limit\t%v\t(%s)\n", s.rateLimit, source("rate_limit"))
+	fmt.Fprintf(tw, "retry\t%s\t(%s)\n", retry, source("retry"))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(s.queries) > 0 {
+		fmt.Fprintf(w, "\nSaved queries:\n")
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		names := make([]string, 0, len(s.queries))
+		for name := range s.queries {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			fmt.Fprintf(tw, "  @%s\t%s\n", name, s.queries[name])
+		}
+		return tw.Flush()
+	}
+	return nil
+}
+
+// newConfiguredClient creates a client with the loaded settings.
+func (a *app) newConfiguredClient() *arxiv.Client {
+	return arxiv.NewClient(a.settings.clientOptions()...)
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// defaultDownloadLayout is the default value of -layout.
+const defaultDownloadLayout = "{{.ID}}.{{.Ext}}"
+
+var downloadCommand = &command{
+	name:    "download",
+	usage:   "[flags] [id...]",
+	summary: "download PDFs or source files",
+	help: `
+Download fetches the PDF or e-print source of the given arXiv IDs, or of the
+results of -query if no IDs are given, into -dir. Interrupted downloads are
+resumed on the next run and files that already exist are skipped. For each
+file, its path, size and SHA-256 checksum are printed, for example:
+
+	arxiv download 2408.03982 2408.03988v2
+	arxiv download -kind source -dir src -layout '{{.Category}}/{{.ID}}.{{.Ext}}' -query cat:cs.LG
+
+The -layout template builds the path of each file below -dir from these
+fields: ID (with version), BaseID (without version), Version, Category
+(primary category), Year and Month (of first publication), Kind ("pdf" or
+"source"), Ext ("pdf" or "tar.gz") and Entry (the arxiv.EntryMetadata).
+"/" in old-style IDs is replaced by "_".`,
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, a *app, args []string) error {
+		var sf searchFlags
+		sf.register(fs)
+		kind := fs.String("kind", "pdf", "file to download: pdf or source")
+		dir := fs.String("dir", ".", "directory to download to")
+		layout := fs.String("layout", defaultDownloadLayout, "template for the path of each file below -dir")
+		force := fs.Bool("force", false, "download files that already exist again")
+		return func(ctx context.Context, a *app, args []string) error {
+			downloadKind := arxiv.DownloadKind(*kind)
+			if downloadKind != arxiv.DownloadKindPDF && downloadKind != arxiv.DownloadKindSource {
+				return usageErrorf("invalid -kind %q", *kind)
+			}
+			tmpl, err := template.New("layout").Option("missingkey=error").Parse(*layout)
+			if err != nil {
+				return usageErrorf("invalid -layout: %v", err)
+			}
+
+			var params arxiv.SearchParams
+			if ids := splitIDs(args); len(ids) > 0 {
+				if sf.query != "" {
+					return usageErrorf("give either -query or IDs, not both")
+				}
+				params = arxiv.SearchParams{IdList: ids, MaxResults: len(ids)}
+			} else {
+				if params, err = sf.params(a, nil); err != nil {
+					return err
+				}
+				if params.Query == "" {
+					return usageErrorf("a query or at least one arXiv ID is required")
+				}
+			}
+
+			client := a.newClient()
+			response, err := client.Search(ctx, params)
+			if err != nil {
+				return err
+			}
+
+			var failed int
+			for _, entry := range response.Entries {
+				path, err := downloadPath(tmpl, *dir, downloadKind, entry)
+				if err == nil {
+					err = downloadEntry(ctx, a, client, downloadKind, entry, path, *force)
+				}
+				if err != nil {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					fmt.Fprintf(a.stderr, "%s: %v\n", entry.ArxivID(), err)
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d downloads failed", failed, len(response.Entries))
+			}
+			return nil
+		}
+	},
+}
+
+// downloadEntry downloads one file to path and prints its path, size and
+// checksum.
+func downloadEntry(ctx context.Context, a *app, client *arxiv.Client, kind arxiv.DownloadKind, entry arxiv.EntryMetadata, path string, force bool) error {
+	if !force {
+		if _, err := os.Stat(path); err == nil {
+			fmt.Fprintf(a.stderr, "%s: %s exists, skipping\n", entry.ArxivID(), path)
+			return nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	result, err := client.DownloadFile(ctx, kind, entry.ArxivID(), path)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "%s\t%d\t%s\n", path, result.Size, result.SHA256)
+	return nil
+}
+
+// downloadPathData is the data passed to the -layout template.
+type downloadPathData struct {
+	ID       string
+	BaseID   string
+	Version  string
+	Category string
+	Year     string
+	Month    string
+	Kind     string
+	Ext      string
+	Entry    arxiv.EntryMetadata
+}
+
+// downloadPath returns the path below dir where the file of the given kind
+// for entry is saved.
+func downloadPath(tmpl *template.Template, dir string, kind arxiv.DownloadKind, entry arxiv.EntryMetadata) (string, error) {
+	id := entry.ArxivID()
+	baseID := entry.BaseID()
+	data := downloadPathData{
+		ID:       strings.ReplaceAll(id, "/", "_"),
+		BaseID:   strings.ReplaceAll(baseID, "/", "_"),
+		Version:  strings.TrimPrefix(id, baseID),
+		Category: entry.PrimaryCategory.Term,
+		Kind:     string(kind),
+		Ext:      "pdf",
+		Entry:    entry,
+	}
+	if kind == arxiv.DownloadKindSource {
+		data.Ext = "tar.gz"
+	}
+	if !entry.Published.IsZero() {
+		data.Year = entry.Published.Format("2006")
+		data.Month = entry.Published.Format("01")
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	rel := filepath.Clean(filepath.FromSlash(strings.TrimSpace(b.String())))
+	if rel == "." || filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("layout gives invalid path %q", b.String())
+	}
+	return filepath.Join(dir, rel), nil
+}
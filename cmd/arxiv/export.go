@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+var exportCommand = &command{
+	name:    "export",
+	usage:   "[flags] [id...]",
+	summary: "export entries as citations",
+	help: `
+Export prints citations for the given arXiv IDs, or for the results of
+-query if no IDs are given, for example:
+
+	arxiv export -format bibtex 2408.03982 2408.03988 > refs.bib
+	arxiv export -query 'au:Hinton AND ti:capsule' > capsules.bib`,
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, a *app, args []string) error {
+		var sf searchFlags
+		sf.register(fs)
+		format := fs.String("format", "bibtex", "citation format: bibtex")
+		return func(ctx context.Context, a *app, args []string) error {
+			if *format != "bibtex" {
+				return usageErrorf("unsupported format %q", *format)
+			}
+
+			var params arxiv.SearchParams
+			if ids := splitIDs(args); len(ids) > 0 {
+				if sf.query != "" {
+					return usageErrorf("give either -query or IDs, not both")
+				}
+				params = arxiv.SearchParams{IdList: ids, MaxResults: len(ids)}
+			} else {
+				var err error
+				if params, err = sf.params(a, nil); err != nil {
+					return err
+				}
+				if params.Query == "" {
+					return usageErrorf("a query or at least one arXiv ID is required")
+				}
+			}
+
+			response, err := a.newClient().Search(ctx, params)
+			if err != nil {
+				return err
+			}
+			return arxiv.WriteBibTeX(a.stdout, response.Entries)
+		}
+	},
+}
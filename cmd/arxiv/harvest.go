@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+var harvestCommand = &command{
+	name:    "harvest",
+	usage:   "-out <dir> [flags] [query]",
+	summary: "save all results of a query to a directory",
+	help: `
+Harvest pages through every result of a query and writes each entry to the
+output directory as <id>.json, with "/" in old-style IDs replaced by "_".
+An estimate of the work is printed before the harvest starts; use -dry-run
+to print only the estimate. Budgets stop the harvest early, for example:
+
+	arxiv harvest -out cs-lg -max-results 200 -max-duration 30m cat:cs.LG`,
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, a *app, args []string) error {
+		var sf searchFlags
+		sf.register(fs)
+		out := fs.String("out", "", "directory to write entries to (required)")
+		maxPages := fs.Int("max-pages", 0, "stop after this many pages (0 = no limit)")
+		maxEntries := fs.Int("max-entries", 0, "stop after this many entries (0 = no limit)")
+		maxDuration := fs.Duration("max-duration", 0, "stop after this much time (0 = no limit)")
+		dryRun := fs.Bool("dry-run", false, "print the estimate without harvesting")
+		return func(ctx context.Context, a *app, args []string) error {
+			params, err := sf.params(a, args)
+			if err != nil {
+				return err
+			}
+			if params.Query == "" {
+				return usageErrorf("a query is required")
+			}
+			if *out == "" && !*dryRun {
+				return usageErrorf("-out is required")
+			}
+
+			harvest := a.newClient().Harvest(params, arxiv.HarvestOptions{
+				MaxPages:    *maxPages,
+				MaxEntries:  *maxEntries,
+				MaxDuration: *maxDuration,
+			})
+			estimate, err := harvest.Estimate(ctx)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(a.stderr, "%d results; harvesting %d entries in %d pages, at least %v\n",
+				estimate.TotalResults, estimate.Entries, estimate.Pages, estimate.Duration.Round(time.Second))
+			if *dryRun {
+				return nil
+			}
+
+			if err := os.MkdirAll(*out, 0o755); err != nil {
+				return err
+			}
+			for entry := range harvest.Entries(ctx) {
+				if err := writeEntryFile(*out, entry); err != nil {
+					return err
+				}
+			}
+
+			stats := harvest.Stats()
+			err = harvest.Err()
+			if errors.Is(err, arxiv.ErrBudgetExceeded) {
+				fmt.Fprintf(a.stderr, "wrote %d entries to %s; %v\n", stats.Entries, *out, err)
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("after %d entries: %w", stats.Entries, err)
+			}
+			fmt.Fprintf(a.stderr, "wrote %d entries to %s\n", stats.Entries, *out)
+			return nil
+		}
+	},
+}
+
+// entryFileName returns the file name used for an entry in a harvest directory.
+func entryFileName(entry arxiv.EntryMetadata) string {
+	return strings.ReplaceAll(entry.BaseID(), "/", "_") + ".json"
+}
+
+func writeEntryFile(dir string, entry arxiv.EntryMetadata) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, entryFileName(entry)), append(data, '\n'), 0o644)
+}
@@ -1,55 +1,193 @@
+// Command arxiv searches the arXiv API from the command line.
+//
+// Usage:
+//
+//	arxiv <command> [flags] [arguments]
+//
+// Run "arxiv help" for the list of commands and "arxiv help <command>" for
+// the flags each command accepts.
 package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"strings"
-	"text/tabwriter"
 
 	"github.com/Epistemic-Technology/arxiv/arxiv"
 )
 
+// Exit codes returned by the command.
+const (
+	exitOK    = 0 // Success
+	exitError = 1 // The command failed
+	exitUsage = 2 // The command was invoked incorrectly
+)
+
+// command is a subcommand of the CLI.
+type command struct {
+	name    string
+	usage   string // Arguments shown after the command name
+	summary string // One-line description for the command list
+	help    string // Longer description shown by "arxiv help <command>"
+
+	// setup defines the command's flags on fs and returns the function that
+	// runs the command once they have been parsed.
+	setup func(fs *flag.FlagSet) func(ctx context.Context, a *app, args []string) error
+}
+
+// app holds the state shared by all commands.
+type app struct {
+	stdout    io.Writer
+	stderr    io.Writer
+	env       func(key string) string // Looks up environment variables
+	settings  *settings               // Loaded before a command runs
+	newClient func() *arxiv.Client
+}
+
+// usageError reports that a command was invoked incorrectly.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func usageErrorf(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		searchCommand,
+		getCommand,
+		exportCommand,
+		harvestCommand,
+		trendCommand,
+		downloadCommand,
+		watchCommand,
+		tuiCommand,
+		categoriesCommand,
+		configCommand,
+	}
+}
+
 func main() {
-	query := flag.String("query", "", "Search query")
-	idList := flag.String("id-list", "", "Comma-separated list of IDs")
-	start := flag.Int("start", 0, "Start index for results")
-	maxResults := flag.Int("max-results", 0, "Maximum number of results")
-	sortBy := flag.String("sort-by", "", "Field to sort results by (relevance, lastUpdatedDate, submittedDate)")
-	sortOrder := flag.String("sort-order", "", "Sort order (ascending, descending)")
-
-	flag.Parse()
-
-	params := arxiv.SearchParams{
-		Query:      *query,
-		IdList:     strings.Split(*idList, ","),
-		Start:      *start,
-		MaxResults: *maxResults,
-		SortBy:     arxiv.SortBy(*sortBy),
-		SortOrder:  arxiv.SortOrder(*sortOrder),
-	}
-
-	client := arxiv.NewClient()
-	ctx := context.Background()
-	response, err := client.Search(ctx, params)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	a := &app{
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		env:    os.Getenv,
+	}
+	a.newClient = a.newConfiguredClient
+	code := a.run(ctx, os.Args[1:])
+	stop()
+	os.Exit(code)
+}
+
+// run executes the command named by args[0] and returns the exit code.
+func (a *app) run(ctx context.Context, args []string) int {
+	if len(args) == 0 {
+		a.printUsage(a.stderr)
+		return exitUsage
+	}
+
+	name, args := args[0], args[1:]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		return a.help(args)
+	}
+
+	cmd := lookupCommand(name)
+	if cmd == nil {
+		fmt.Fprintf(a.stderr, "arxiv: unknown command %q\nRun 'arxiv help' for usage.\n", name)
+		return exitUsage
+	}
+
+	fs := cmd.flagSet(a.stderr)
+	runCommand := cmd.setup(fs)
+	fs.SetOutput(io.Discard)
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		fs.SetOutput(a.stdout)
+		fs.Usage()
+		return exitOK
+	}
 	if err != nil {
-		fmt.Println("Error searching arXiv:", err)
-		return
-	}
-
-	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
-	fmt.Fprintln(w, "Title\tAuthor\tYear\tDOI")
-	fmt.Fprintln(w, "-----\t----\t----\t---")
-	for _, result := range response.Entries {
-		fmt.Fprintf(
-			w,
-			"%.50s\t%.20s\t%d\t%.20s\n",
-			result.Title,
-			result.Authors[0].Name,
-			result.Published.Year(),
-			result.DOI,
-		)
-	}
-	w.Flush()
+		err = &usageError{msg: err.Error()}
+	} else {
+		a.settings, err = a.loadSettings(fs.Lookup("config").Value.String(), fs.Lookup("profile").Value.String())
+		if err == nil {
+			err = runCommand(ctx, a, fs.Args())
+		}
+	}
+
+	var usageErr *usageError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &usageErr):
+		fmt.Fprintf(a.stderr, "arxiv %s: %v\nRun 'arxiv help %s' for usage.\n", cmd.name, err, cmd.name)
+		return exitUsage
+	default:
+		fmt.Fprintf(a.stderr, "arxiv %s: %v\n", cmd.name, err)
+		return exitError
+	}
+}
+
+// help prints general usage or the help for one command.
+func (a *app) help(args []string) int {
+	if len(args) == 0 {
+		a.printUsage(a.stdout)
+		return exitOK
+	}
+	cmd := lookupCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(a.stderr, "arxiv help: unknown command %q\n", args[0])
+		return exitUsage
+	}
+	fs := cmd.flagSet(a.stdout)
+	cmd.setup(fs)
+	fs.Usage()
+	return exitOK
+}
+
+func (a *app) printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: arxiv <command> [flags] [arguments]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, "\nRun 'arxiv help <command>' for more information on a command.\n")
+}
+
+func lookupCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// flagSet returns a flag set holding the flags common to all commands, whose
+// usage message includes the command's help text.
+func (cmd *command) flagSet(output io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.String("config", "", "config file (default $ARXIV_CONFIG or ~/.config/arxiv/config.toml)")
+	fs.String("profile", "", "configuration profile (default $ARXIV_PROFILE or the config file's profile)")
+	fs.Usage = func() {
+		w := fs.Output()
+		fmt.Fprintf(w, "Usage: arxiv %s %s\n\n%s\n", cmd.name, cmd.usage, strings.TrimSpace(cmd.help))
+		fmt.Fprintf(w, "\nFlags:\n")
+		fs.PrintDefaults()
+	}
+	return fs
 }
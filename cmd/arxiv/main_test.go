@@ -0,0 +1,856 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+const testFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:opensearch="http://a9.com/-/spec/opensearch/1.1/">
+  <opensearch:totalResults>2</opensearch:totalResults>
+  <opensearch:startIndex>0</opensearch:startIndex>
+  <opensearch:itemsPerPage>10</opensearch:itemsPerPage>
+  <entry>
+    <id>http://arxiv.org/abs/2401.00001v1</id>
+    <published>2024-01-01T00:00:00Z</published>
+    <title>A Paper With
+      Authors</title>
+    <author><name>Ada Lovelace</name></author>
+    <author><name>Alan Turing</name></author>
+  </entry>
+  <entry>
+    <id>http://arxiv.org/abs/cond-mat/0102536v2</id>
+    <published>2001-02-28T00:00:00Z</published>
+    <title>An Anonymous Paper</title>
+  </entry>
+</feed>`
+
+// testApp returns an app whose clients talk to a server answering every
+// request with testFeed, and the server's record of request queries.
+func testApp(t *testing.T) (*app, *bytes.Buffer, *bytes.Buffer, *[]string) {
+	t.Helper()
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		fmt.Fprint(w, testFeed)
+	}))
+	t.Cleanup(server.Close)
+
+	var stdout, stderr bytes.Buffer
+	a := &app{
+		stdout: &stdout,
+		stderr: &stderr,
+		newClient: func() *arxiv.Client {
+			return arxiv.NewClient(arxiv.WithBaseURL(server.URL), arxiv.WithRateLimit(0))
+		},
+	}
+	return a, &stdout, &stderr, &queries
+}
+
+func TestRunExitCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"no command", nil, exitUsage},
+		{"unknown command", []string{"frobnicate"}, exitUsage},
+		{"help", []string{"help"}, exitOK},
+		{"help for command", []string{"help", "search"}, exitOK},
+		{"help for unknown command", []string{"help", "frobnicate"}, exitUsage},
+		{"command -h", []string{"search", "-h"}, exitOK},
+		{"unknown flag", []string{"search", "-bogus"}, exitUsage},
+		{"search without query", []string{"search"}, exitUsage},
+		{"get without IDs", []string{"get"}, exitUsage},
+		{"get with only commas", []string{"get", ","}, exitUsage},
+		{"invalid sort", []string{"search", "-sort-by", "title", "all:x"}, exitUsage},
+		{"invalid query", []string{"search", "foo:bar"}, exitUsage},
+		{"unknown category", []string{"search", "cat:cs.XX"}, exitOK},
+		{"unsupported export format", []string{"export", "-format", "ris", "2401.00001"}, exitUsage},
+		{"harvest without out", []string{"harvest", "all:x"}, exitUsage},
+		{"trend without from", []string{"trend", "all:x"}, exitUsage},
+		{"trend without query", []string{"trend", "-from", "2024"}, exitUsage},
+		{"trend with bad date", []string{"trend", "-from", "January", "all:x"}, exitUsage},
+		{"trend with bad period", []string{"trend", "-from", "2024", "-period", "decade", "all:x"}, exitUsage},
+		{"search", []string{"search", "all:electron"}, exitOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _, stderr, _ := testApp(t)
+			if got := a.run(context.Background(), tt.args); got != tt.want {
+				t.Errorf("run(%q) = %d; want %d (stderr: %s)", tt.args, got, tt.want, stderr)
+			}
+		})
+	}
+}
+
+func TestSearchWarnsOfUnknownCategories(t *testing.T) {
+	for _, args := range [][]string{{"search", "cat:cs.XX"}, {"trend", "-from", "2024-01", "-to", "2024-02", "-cat", "cs.XX"}} {
+		a, _, stderr, _ := testApp(t)
+		if code := a.run(context.Background(), args); code != exitOK {
+			t.Fatalf("%q exited with %d: %s", args, code, stderr)
+		}
+		if !strings.Contains(stderr.String(), "warning: unknown category: cs.XX") {
+			t.Errorf("%q stderr = %q, want a warning", args, stderr)
+		}
+	}
+
+	a, _, stderr, _ := testApp(t)
+	a.run(context.Background(), []string{"search", "cat:cs.LG"})
+	if strings.Contains(stderr.String(), "warning") {
+		t.Errorf("stderr = %q, want no warning for a known category", stderr)
+	}
+}
+
+func TestRunReportsErrorsOnStderr(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	a := &app{
+		stdout: &stdout,
+		stderr: &stderr,
+		newClient: func() *arxiv.Client {
+			return arxiv.NewClient(arxiv.WithBaseURL("http://127.0.0.1:1"), arxiv.WithRateLimit(0))
+		},
+	}
+	if got := a.run(context.Background(), []string{"search", "all:electron"}); got != exitError {
+		t.Errorf("run() = %d; want %d", got, exitError)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("stdout = %q; want nothing", stdout.String())
+	}
+	if !strings.HasPrefix(stderr.String(), "arxiv search: ") {
+		t.Errorf("stderr = %q; want error prefixed with command", stderr.String())
+	}
+}
+
+func TestSearchTable(t *testing.T) {
+	a, stdout, _, queries := testApp(t)
+	if got := a.run(context.Background(), []string{"search", "-max-results", "5", "ti:paper"}); got != exitOK {
+		t.Fatalf("run() = %d; want %d", got, exitOK)
+	}
+	out := stdout.String()
+	for _, want := range []string{"2401.00001v1", "A Paper With Authors", "Ada Lovelace et al.", "2024", "cond-mat/0102536v2"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if len(*queries) != 1 || !strings.Contains((*queries)[0], "max_results=5") {
+		t.Errorf("queries = %v; want one request with max_results=5", *queries)
+	}
+}
+
+func TestGetSplitsIDs(t *testing.T) {
+	a, _, _, queries := testApp(t)
+	if got := a.run(context.Background(), []string{"get", "2401.00001,", "cond-mat/0102536"}); got != exitOK {
+		t.Fatalf("run() = %d; want %d", got, exitOK)
+	}
+	if len(*queries) != 1 || !strings.Contains((*queries)[0], "id_list=2401.00001%2Ccond-mat%2F0102536&") {
+		t.Errorf("queries = %v; want id_list of both IDs", *queries)
+	}
+}
+
+func TestExportBibTeX(t *testing.T) {
+	a, stdout, _, _ := testApp(t)
+	if got := a.run(context.Background(), []string{"export", "--format", "bibtex", "2401.00001"}); got != exitOK {
+		t.Fatalf("run() = %d; want %d", got, exitOK)
+	}
+	if got := strings.Count(stdout.String(), "@misc{"); got != 2 {
+		t.Errorf("export wrote %d records; want 2:\n%s", got, stdout)
+	}
+}
+
+func TestHarvestWritesEntries(t *testing.T) {
+	a, _, stderr, _ := testApp(t)
+	dir := filepath.Join(t.TempDir(), "out")
+	if got := a.run(context.Background(), []string{"harvest", "--out", dir, "all:electron"}); got != exitOK {
+		t.Fatalf("run() = %d; want %d (stderr: %s)", got, exitOK, stderr)
+	}
+	for _, name := range []string{"2401.00001.json", "cond-mat_0102536.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("missing harvested file %s: %v", name, err)
+		}
+	}
+	if !strings.Contains(stderr.String(), "wrote 2 entries") {
+		t.Errorf("stderr = %q; want summary", stderr.String())
+	}
+}
+
+func TestTrend(t *testing.T) {
+	a, stdout, stderr, queries := testApp(t)
+	args := []string{"trend", "-from", "2024-01", "-to", "2024-04", "-output", "csv", "-cat", "cs.CL,cs.LG", "all:electron"}
+	if got := a.run(context.Background(), args); got != exitOK {
+		t.Fatalf("run() = %d; want %d (stderr: %s)", got, exitOK, stderr)
+	}
+	want := "start,end,cs.CL,cs.LG\n" +
+		"2024-01-01,2024-02-01,2,2\n" +
+		"2024-02-01,2024-03-01,2,2\n" +
+		"2024-03-01,2024-04-01,2,2\n"
+	if stdout.String() != want {
+		t.Errorf("stdout =\n%s\nwant\n%s", stdout, want)
+	}
+	if len(*queries) != 6 {
+		t.Fatalf("made %d requests, want 6", len(*queries))
+	}
+	for _, q := range *queries {
+		if !strings.Contains(q, "max_results=1&") || !strings.Contains(q, "submittedDate") {
+			t.Errorf("query = %s, want one result in a date range", q)
+		}
+	}
+
+	stdout.Reset()
+	args = []string{"trend", "-from", "2024-01", "-to", "2024-03", "-ascii", "-term", "electron", "-term", `ti:"muon decay"`}
+	if got := a.run(context.Background(), args); got != exitOK {
+		t.Fatalf("run() = %d; want %d (stderr: %s)", got, exitOK, stderr)
+	}
+	for _, want := range []string{"2024-01-01 to 2024-03-01", "electron", `ti:"muon decay"`, "@@  min 2  max 2  total 4"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("stdout = %q; want %q", stdout, want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("héllo wörld", 5); got != "héll…" {
+		t.Errorf("truncate() = %q; want %q", got, "héll…")
+	}
+	if got := truncate("short", 10); got != "short" {
+		t.Errorf("truncate() = %q; want %q", got, "short")
+	}
+}
+
+func TestOutputFormats(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "json",
+			args: []string{"-output", "json"},
+			want: []string{"[\n  {\n", `"id": "http://arxiv.org/abs/2401.00001v1"`},
+		},
+		{
+			name: "jsonl",
+			args: []string{"-output", "jsonl"},
+			want: []string{"{\"title\":\"A Paper With\\n      Authors\",\"id\":\"http://arxiv.org/abs/2401.00001v1\""},
+		},
+		{
+			name: "csv with columns",
+			args: []string{"-output", "csv", "-columns", "id,authors,year"},
+			want: []string{"id,authors,year\n2401.00001v1,\"Ada Lovelace, Alan Turing\",2024\ncond-mat/0102536v2,,2001\n"},
+		},
+		{
+			name: "bibtex",
+			args: []string{"-output", "bibtex"},
+			want: []string{"@misc{lovelace2024paper,", "@misc{2001anonymous,"},
+		},
+		{
+			name: "markdown",
+			args: []string{"-output", "markdown"},
+			want: []string{"- A Paper With Authors — Ada Lovelace, Alan Turing (2024). arXiv:2401.00001v1\n"},
+		},
+		{
+			name: "template",
+			args: []string{"-template", "{{.BaseID}}: {{join .AuthorNames \"; \"}}"},
+			want: []string{"2401.00001: Ada Lovelace; Alan Turing\ncond-mat/0102536: \n"},
+		},
+		{
+			name: "table with columns",
+			args: []string{"-columns", "year,id"},
+			want: []string{"Year  ID\n----  --\n2024  2401.00001v1\n2001  cond-mat/0102536v2\n"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, stdout, stderr, _ := testApp(t)
+			args := append(append([]string{"search"}, tt.args...), "all:electron")
+			if got := a.run(context.Background(), args); got != exitOK {
+				t.Fatalf("run(%q) = %d; want %d (stderr: %s)", args, got, exitOK, stderr)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(stdout.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, stdout)
+				}
+			}
+		})
+	}
+}
+
+func TestOutputFlagErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"-output", "xml"},
+		{"-columns", "id,nope"},
+		{"-output", "template"},
+		{"-template", "{{.Nope"},
+		{"-output", "json", "-template", "{{.Title}}"},
+	} {
+		a, _, _, _ := testApp(t)
+		args := append(append([]string{"search"}, args...), "all:electron")
+		if got := a.run(context.Background(), args); got != exitUsage {
+			t.Errorf("run(%q) = %d; want %d", args, got, exitUsage)
+		}
+	}
+}
+
+func TestTableFitsWidth(t *testing.T) {
+	entries := []arxiv.EntryMetadata{{
+		ID:      "http://arxiv.org/abs/2401.00001v1",
+		Title:   strings.Repeat("Long Title ", 20),
+		Authors: []arxiv.Author{{Name: "Ada Lovelace"}, {Name: "Alan Turing"}},
+	}}
+	cols, err := parseColumns(defaultTableColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeTable(&buf, entries, cols, 80); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+		if n := len([]rune(strings.TrimRight(line, " "))); n > 80 {
+			t.Errorf("line is %d characters wide; want at most 80:\n%s", n, line)
+		}
+	}
+	if !strings.Contains(buf.String(), "…") {
+		t.Error("long title should be truncated with an ellipsis")
+	}
+
+	buf.Reset()
+	if err := writeTable(&buf, entries, cols, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), strings.TrimSpace(strings.Repeat("Long Title ", 20))) {
+		t.Error("title should not be truncated without a width")
+	}
+}
+
+// pagedFeed answers each request with one page of a query whose results are
+// numbered 2401.00000 upwards.
+func pagedFeed(t *testing.T, total, pageSize int) *arxiv.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := 0
+		fmt.Sscan(r.URL.Query().Get("start"), &start)
+		fmt.Fprintf(w, `<feed xmlns="http://www.w3.org/2005/Atom" xmlns:opensearch="http://a9.com/-/spec/opensearch/1.1/">
+  <opensearch:totalResults>%d</opensearch:totalResults>
+  <opensearch:startIndex>%d</opensearch:startIndex>
+  <opensearch:itemsPerPage>%d</opensearch:itemsPerPage>`, total, start, pageSize)
+		for i := start; i < min(start+pageSize, total); i++ {
+			fmt.Fprintf(w, `<entry><id>http://arxiv.org/abs/2401.%05dv1</id><title>Paper %d</title>
+  <summary>Abstract of paper %d.</summary><arxiv:comment xmlns:arxiv="http://arxiv.org/schemas/atom">%d pages</arxiv:comment>
+  <link href="http://arxiv.org/abs/2401.%05dv1" rel="alternate" type="text/html"/></entry>`, i, i, i, i, i)
+		}
+		fmt.Fprint(w, `</feed>`)
+	}))
+	t.Cleanup(server.Close)
+	return arxiv.NewClient(arxiv.WithBaseURL(server.URL), arxiv.WithRateLimit(0))
+}
+
+func TestBrowser(t *testing.T) {
+	ctx := context.Background()
+	var opened []string
+	m := &browser{
+		client:     pagedFeed(t, 5, 2),
+		selected:   make(map[string]arxiv.EntryMetadata),
+		width:      60,
+		height:     20,
+		exportFile: filepath.Join(t.TempDir(), "export.bib"),
+		export:     arxiv.WriteBibTeX,
+		open: func(url string) error {
+			opened = append(opened, url)
+			return nil
+		},
+	}
+	if err := m.load(ctx, arxiv.SearchParams{Query: "all:test", MaxResults: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := func(keys ...string) {
+		t.Helper()
+		for _, key := range keys {
+			if m.handleKey(ctx, key) {
+				t.Fatalf("key %q quit the browser", key)
+			}
+		}
+	}
+	current := func() string {
+		entry, _ := m.current()
+		return entry.ArxivID()
+	}
+
+	keys("down", "down")
+	if got := current(); got != "2401.00001v1" {
+		t.Errorf("cursor did not stop at the last entry: %s", got)
+	}
+	keys("space", "n")
+	if m.page.StartIndex != 2 || current() != "2401.00002v1" {
+		t.Errorf("next page: start %d, entry %s", m.page.StartIndex, current())
+	}
+	keys("n", "n")
+	if m.page.StartIndex != 4 || m.status != "Already on the last page" {
+		t.Errorf("last page: start %d, status %q", m.page.StartIndex, m.status)
+	}
+	keys("space", "p", "o")
+	if m.page.StartIndex != 2 || len(opened) != 1 || opened[0] != "http://arxiv.org/abs/2401.00002v1" {
+		t.Errorf("previous page and open: start %d, opened %v", m.page.StartIndex, opened)
+	}
+
+	var screen bytes.Buffer
+	m.render(&screen)
+	for _, want := range []string{"all:test — 3–4 of 5 — 2 selected", "[ ] 2401.00002v1", "Abstract of paper 2.", "Comment: 2 pages"} {
+		if !strings.Contains(screen.String(), want) {
+			t.Errorf("screen does not contain %q:\n%s", want, screen.String())
+		}
+	}
+
+	keys("e")
+	data, err := os.ReadFile(m.exportFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(data), "@misc{"); got != 2 {
+		t.Errorf("exported %d entries, want 2:\n%s", got, data)
+	}
+	if !strings.Contains(string(data), "2401.00001") || !strings.Contains(string(data), "2401.00004") {
+		t.Errorf("export is missing selected entries:\n%s", data)
+	}
+	if !m.handleKey(ctx, parseKey([]byte("q"))) {
+		t.Error("q did not quit the browser")
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	got := wrapText("the quick brown fox jumps over the lazy dog", 10)
+	want := []string{"the quick", "brown fox", "jumps over", "the lazy", "dog"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("wrapText = %q, want %q", got, want)
+	}
+}
+
+const testConfig = `
+profile = "slow"
+timeout = "30s"
+
+[retry]
+max_attempts = 5
+
+[profiles.slow]
+rate_limit = "10s"
+
+[profiles.mirror]
+base_url = "http://mirror.example/api/query"
+rate_limit = "0s"
+
+[queries]
+llm = 'cat:cs.CL AND abs:"large language model"'
+`
+
+func TestLoadSettings(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "arxiv"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "arxiv", "config.toml")
+	if err := os.WriteFile(path, []byte(testConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		env       map[string]string
+		profile   string
+		baseURL   string
+		timeout   time.Duration
+		rateLimit time.Duration
+		attempts  int // 0 when retry is disabled
+		sources   map[string]string
+		wantErr   string
+	}{
+		{
+			name:      "no config",
+			baseURL:   "http://export.arxiv.org/api/query",
+			timeout:   10 * time.Second,
+			rateLimit: arxiv.DefaultRateLimit,
+		},
+		{
+			name:      "default profile from file",
+			env:       map[string]string{"XDG_CONFIG_HOME": dir},
+			baseURL:   "http://export.arxiv.org/api/query",
+			timeout:   30 * time.Second,
+			rateLimit: 10 * time.Second,
+			attempts:  5,
+			sources:   map[string]string{"timeout": "config file", "rate_limit": "profile slow", "retry": "config file"},
+		},
+		{
+			name:      "profile flag",
+			env:       map[string]string{"ARXIV_CONFIG": path, "ARXIV_PROFILE": "slow"},
+			profile:   "mirror",
+			baseURL:   "http://mirror.example/api/query",
+			timeout:   30 * time.Second,
+			rateLimit: 0,
+			attempts:  5,
+			sources:   map[string]string{"base_url": "profile mirror", "rate_limit": "profile mirror"},
+		},
+		{
+			name: "environment overrides profile",
+			env: map[string]string{
+				"ARXIV_CONFIG":             path,
+				"ARXIV_PROFILE":            "mirror",
+				"ARXIV_BASE_URL":           "http://localhost:9999",
+				"ARXIV_TIMEOUT":            "1m",
+				"ARXIV_RETRY_MAX_ATTEMPTS": "2",
+			},
+			baseURL:   "http://localhost:9999",
+			timeout:   time.Minute,
+			rateLimit: 0,
+			attempts:  2,
+			sources:   map[string]string{"base_url": "environment", "timeout": "environment", "retry": "environment"},
+		},
+		{
+			name:    "unknown profile",
+			env:     map[string]string{"ARXIV_CONFIG": path},
+			profile: "nope",
+			wantErr: `unknown profile "nope"`,
+		},
+		{
+			name:    "missing explicit config",
+			env:     map[string]string{"ARXIV_CONFIG": filepath.Join(dir, "missing.toml")},
+			wantErr: "reading config",
+		},
+		{
+			name:    "invalid environment value",
+			env:     map[string]string{"ARXIV_RATE_LIMIT": "fast"},
+			wantErr: `environment: invalid rate_limit "fast"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &app{env: func(key string) string { return tt.env[key] }}
+			s, err := a.loadSettings("", tt.profile)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadSettings error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.baseURL != tt.baseURL || s.timeout != tt.timeout || s.rateLimit != tt.rateLimit {
+				t.Errorf("settings = %s %v %v, want %s %v %v", s.baseURL, s.timeout, s.rateLimit, tt.baseURL, tt.timeout, tt.rateLimit)
+			}
+			attempts := 0
+			if s.retry != nil {
+				attempts = s.retry.MaxAttempts
+			}
+			if attempts != tt.attempts {
+				t.Errorf("retry attempts = %d, want %d", attempts, tt.attempts)
+			}
+			for name, want := range tt.sources {
+				if got := s.sources[name]; got != want {
+					t.Errorf("source of %s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSavedQueriesAndConfigShow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(testConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	a, stdout, stderr, queries := testApp(t)
+
+	if code := a.run(context.Background(), []string{"search", "-config", path, "@llm"}); code != exitOK {
+		t.Fatalf("search exited with %d: %s", code, stderr)
+	}
+	if len(*queries) != 1 || !strings.Contains((*queries)[0], "large+language+model") {
+		t.Errorf("saved query was not expanded: %v", *queries)
+	}
+	if code := a.run(context.Background(), []string{"search", "-config", path, "@nope"}); code != exitUsage {
+		t.Errorf("unknown saved query exited with %d, want %d", code, exitUsage)
+	}
+
+	stdout.Reset()
+	if code := a.run(context.Background(), []string{"config", "-config", path, "-profile", "mirror", "show"}); code != exitOK {
+		t.Fatalf("config show exited with %d: %s", code, stderr)
+	}
+	for _, want := range []string{"Profile: mirror", "http://mirror.example/api/query  (profile mirror)", "5 attempts", "@llm"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("config show output does not contain %q:\n%s", want, stdout)
+		}
+	}
+}
+
+func TestCategories(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    []string
+		notWant []string
+	}{
+		{[]string{"categories"}, []string{"Computer Science\n", "  cs.LG ", "Machine Learning", "math.IT", "(alias of cs.IT)"}, []string{"solv-int"}},
+		{[]string{"categories", "-deprecated"}, []string{"solv-int", "(merged into nlin.SI)"}, nil},
+		{[]string{"categories", "econ", "stat.M?"}, []string{"Economics\n", "econ.EM", "Statistics\n", "stat.ME", "stat.ML"}, []string{"cs.LG", "stat.AP"}},
+		{[]string{"categories", "-group", "quantitative finance"}, []string{"q-fin.TR"}, []string{"Computer Science", "econ.EM"}},
+	}
+	for _, tt := range tests {
+		a, stdout, stderr, _ := testApp(t)
+		if code := a.run(context.Background(), tt.args); code != exitOK {
+			t.Fatalf("%q exited with %d: %s", tt.args, code, stderr)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(stdout.String(), want) {
+				t.Errorf("%q output does not contain %q:\n%s", tt.args, want, stdout)
+			}
+		}
+		for _, notWant := range tt.notWant {
+			if strings.Contains(stdout.String(), notWant) {
+				t.Errorf("%q output contains %q:\n%s", tt.args, notWant, stdout)
+			}
+		}
+	}
+
+	for _, args := range [][]string{{"categories", "bogus.*"}, {"categories", "-group", "Chemistry"}} {
+		a, _, _, _ := testApp(t)
+		if code := a.run(context.Background(), args); code != exitUsage {
+			t.Errorf("%q exited with %d, want %d", args, code, exitUsage)
+		}
+	}
+}
+
+// submissionServer serves the entries in *ids, the last one submitted on
+// 2024-02-01 and each earlier one a day later, sorted by submission date in
+// the requested order and limited to the submittedDate range of the query,
+// if any. It records the start of each request.
+func submissionServer(t *testing.T, ids *[]string, starts *[]int) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("sortBy") != "submittedDate" {
+			t.Errorf("unexpected sort: %s", r.URL.RawQuery)
+		}
+		var start, max int
+		fmt.Sscan(q.Get("start"), &start)
+		fmt.Sscan(q.Get("max_results"), &max)
+		*starts = append(*starts, start)
+
+		type submission struct {
+			id        string
+			published time.Time
+		}
+		var since time.Time
+		if _, rng, ok := strings.Cut(q.Get("search_query"), "submittedDate:["); ok {
+			since, _ = time.Parse("200601021504", rng[:12])
+		}
+		var matched []submission
+		oldest := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+		for i, id := range *ids {
+			if published := oldest.AddDate(0, 0, len(*ids)-1-i); !published.Before(since) {
+				matched = append(matched, submission{id, published})
+			}
+		}
+		if q.Get("sortOrder") == "ascending" {
+			slices.Reverse(matched)
+		}
+
+		fmt.Fprintf(w, `<feed xmlns="http://www.w3.org/2005/Atom" xmlns:opensearch="http://a9.com/-/spec/opensearch/1.1/">
+  <opensearch:totalResults>%d</opensearch:totalResults>
+  <opensearch:startIndex>%d</opensearch:startIndex>
+  <opensearch:itemsPerPage>%d</opensearch:itemsPerPage>`, len(matched), start, max)
+		for _, m := range matched[min(start, len(matched)):min(start+max, len(matched))] {
+			fmt.Fprintf(w, `<entry><id>http://arxiv.org/abs/%sv1</id><published>%s</published><title>Paper %s</title></entry>`,
+				m.id, m.published.Format(time.RFC3339), m.id)
+		}
+		fmt.Fprint(w, `</feed>`)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestWatch(t *testing.T) {
+	ids := []string{"2403.00003", "2403.00002", "2403.00001", "2402.00009", "2402.00008"}
+	var starts []int
+	baseURL := submissionServer(t, &ids, &starts)
+
+	state := filepath.Join(t.TempDir(), "watch.json")
+	a, stdout, stderr, _ := testApp(t)
+	a.newClient = func() *arxiv.Client {
+		return arxiv.NewClient(arxiv.WithBaseURL(baseURL), arxiv.WithRateLimit(0))
+	}
+	run := func(args ...string) {
+		t.Helper()
+		stdout.Reset()
+		starts = nil
+		args = append([]string{"watch", "-state", state, "-output", "csv", "-columns", "id", "-max-results", "2"}, args...)
+		if code := a.run(context.Background(), args); code != exitOK {
+			t.Fatalf("%v exited with %d: %s", args, code, stderr)
+		}
+	}
+	reported := func() string {
+		return strings.Join(strings.Fields(stdout.String())[1:], " ")
+	}
+
+	run("add", "new", "cat:cs.LG")
+	run()
+	if got := reported(); got != "2403.00003v1 2403.00002v1" {
+		t.Errorf("first run reported %q, want one page", got)
+	}
+
+	ids = append([]string{"2403.00005", "2403.00004"}, ids...)
+	run("run", "new")
+	if got := reported(); got != "2403.00005v1 2403.00004v1" {
+		t.Errorf("second run reported %q", got)
+	}
+	if len(starts) != 2 || starts[1] != 2 {
+		t.Errorf("second run requested pages %v, want it to stop on the second page", starts)
+	}
+
+	run()
+	if got := reported(); got != "" {
+		t.Errorf("run without new submissions reported %q", got)
+	}
+
+	run("list")
+	if !strings.Contains(stdout.String(), "new  2024-02-07  cat:cs.LG") {
+		t.Errorf("list output = %q", stdout)
+	}
+
+	ids = append([]string{"2403.00006"}, ids...)
+	var payloads []webhookPayload
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p webhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Error(err)
+		}
+		payloads = append(payloads, p)
+		if len(payloads) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer hook.Close()
+	args := []string{"watch", "-state", state, "-webhook", hook.URL}
+	if code := a.run(context.Background(), args); code != exitError {
+		t.Errorf("failed webhook exited with %d, want %d", code, exitError)
+	}
+	run("-webhook", hook.URL)
+	if len(payloads) != 2 || payloads[1].Watch != "new" || len(payloads[1].Entries) != 1 ||
+		payloads[1].Entries[0].ArxivID() != "2403.00006v1" {
+		t.Errorf("webhook payloads = %+v, want the new entry to be redelivered", payloads)
+	}
+
+	run("remove", "new")
+	run("list")
+	if stdout.Len() != 0 {
+		t.Errorf("list after remove = %q", stdout)
+	}
+}
+
+func TestWatchMaxNew(t *testing.T) {
+	ids := []string{"2402.00002", "2402.00001"}
+	var starts []int
+	baseURL := submissionServer(t, &ids, &starts)
+
+	state := filepath.Join(t.TempDir(), "watch.json")
+	a, stdout, stderr, _ := testApp(t)
+	a.newClient = func() *arxiv.Client {
+		return arxiv.NewClient(arxiv.WithBaseURL(baseURL), arxiv.WithRateLimit(0))
+	}
+	run := func(args ...string) string {
+		t.Helper()
+		stdout.Reset()
+		stderr.Reset()
+		starts = nil
+		args = append([]string{"watch", "-state", state, "-output", "csv", "-columns", "id", "-max-results", "2", "-max-new", "2"}, args...)
+		if code := a.run(context.Background(), args); code != exitOK {
+			t.Fatalf("%v exited with %d: %s", args, code, stderr)
+		}
+		if fields := strings.Fields(stdout.String()); len(fields) > 0 {
+			return strings.Join(fields[1:], " ")
+		}
+		return ""
+	}
+
+	run("add", "new", "cat:cs.LG")
+	run()
+	ids = append([]string{"2403.00005", "2403.00004", "2403.00003", "2403.00002", "2403.00001"}, ids...)
+
+	// Five new papers come out over three runs, oldest first, none lost.
+	for i, want := range []string{"2403.00002v1 2403.00001v1", "2403.00004v1 2403.00003v1", "2403.00005v1", ""} {
+		if got := run(); got != want {
+			t.Errorf("run reported %q, want %q", got, want)
+		}
+		// Paging stops with -max-new papers rather than reading all six
+		// submitted since the watermark.
+		if i == 0 && !slices.Equal(starts, []int{0, 2}) {
+			t.Errorf("first run requested pages %v, want [0 2]", starts)
+		}
+	}
+
+	ids = append([]string{"2403.00008", "2403.00007", "2403.00006"}, ids...)
+	run()
+	if !strings.Contains(stderr.String(), "new: 2 new, 1 more next run") {
+		t.Errorf("stderr = %q, want the held back count", stderr)
+	}
+}
+
+func TestDownload(t *testing.T) {
+	var downloads []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := strings.CutPrefix(r.URL.Path, "/pdf/"); ok {
+			downloads = append(downloads, id)
+			w.Header().Set("Content-Type", "application/pdf")
+			fmt.Fprintf(w, "%%PDF %s", id)
+			return
+		}
+		fmt.Fprint(w, testFeed)
+	}))
+	defer server.Close()
+
+	a, stdout, stderr, _ := testApp(t)
+	a.newClient = func() *arxiv.Client {
+		return arxiv.NewClient(arxiv.WithBaseURL(server.URL), arxiv.WithDownloadBaseURL(server.URL), arxiv.WithRateLimit(0))
+	}
+	dir := t.TempDir()
+	args := []string{"download", "-dir", dir, "-layout", "{{.Year}}/{{.BaseID}}{{.Version}}.{{.Ext}}", "2401.00001", "cond-mat/0102536"}
+	if code := a.run(context.Background(), args); code != exitOK {
+		t.Fatalf("download exited with %d: %s", code, stderr)
+	}
+	if strings.Join(downloads, ",") != "2401.00001v1,cond-mat/0102536v2" {
+		t.Errorf("downloaded %v", downloads)
+	}
+	for _, file := range []string{"2024/2401.00001v1.pdf", "2001/cond-mat_0102536v2.pdf"} {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if _, err := os.Stat(path); err != nil {
+			t.Error(err)
+		}
+		if !strings.Contains(stdout.String(), path+"\t") {
+			t.Errorf("output does not report %s:\n%s", path, stdout)
+		}
+	}
+
+	downloads = nil
+	if code := a.run(context.Background(), args); code != exitOK {
+		t.Fatalf("second download exited with %d: %s", code, stderr)
+	}
+	if len(downloads) != 0 || !strings.Contains(stderr.String(), "exists, skipping") {
+		t.Errorf("existing files were downloaded again: %v", downloads)
+	}
+
+	args = []string{"download", "-dir", dir, "-layout", "../{{.ID}}.pdf", "2401.00001"}
+	if code := a.run(context.Background(), args); code != exitError || !strings.Contains(stderr.String(), "invalid path") {
+		t.Errorf("layout escaping -dir exited with %d: %s", code, stderr)
+	}
+}
@@ -0,0 +1,412 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+// outputFormats lists the values accepted by -output.
+var outputFormats = []string{"table", "json", "jsonl", "csv", "bibtex", "markdown", "template"}
+
+// column is a field of an entry that can be shown in table or CSV output.
+type column struct {
+	name   string
+	header string
+	flex   bool // Whether the column may be truncated to fit the terminal
+	value  func(entry arxiv.EntryMetadata) string
+}
+
+var columns = []column{
+	{"id", "ID", false, func(e arxiv.EntryMetadata) string { return e.ArxivID() }},
+	{"title", "Title", true, func(e arxiv.EntryMetadata) string { return oneLine(e.Title) }},
+	{"author", "Author", true, authorSummary},
+	{"authors", "Authors", true, func(e arxiv.EntryMetadata) string { return strings.Join(e.AuthorNames(), ", ") }},
+	{"year", "Year", false, year},
+	{"published", "Published", false, func(e arxiv.EntryMetadata) string { return formatDate(e.Published) }},
+	{"updated", "Updated", false, func(e arxiv.EntryMetadata) string { return formatDate(e.Updated) }},
+	{"primary", "Primary", false, func(e arxiv.EntryMetadata) string { return e.PrimaryCategory.Term }},
+	{"categories", "Categories", true, categories},
+	{"doi", "DOI", false, func(e arxiv.EntryMetadata) string { return e.DOI }},
+	{"journal", "Journal", true, func(e arxiv.EntryMetadata) string { return oneLine(e.JournalReference) }},
+	{"comment", "Comment", true, func(e arxiv.EntryMetadata) string { return oneLine(e.Comment) }},
+	{"abs", "Abstract URL", false, func(e arxiv.EntryMetadata) string { return e.AbstractUrl }},
+	{"pdf", "PDF URL", false, func(e arxiv.EntryMetadata) string { return e.PDFUrl }},
+	{"summary", "Summary", true, func(e arxiv.EntryMetadata) string { return oneLine(e.Summary) }},
+}
+
+const (
+	defaultTableColumns = "id,title,author,year,doi"
+	defaultCSVColumns   = "id,title,authors,published,updated,primary,categories,doi,journal,comment,abs,pdf"
+)
+
+// outputFlags are the flags shared by commands that print entries.
+type outputFlags struct {
+	format   string
+	columns  string
+	template string
+	width    int
+}
+
+func (of *outputFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&of.format, "output", "table", "output format: "+strings.Join(outputFormats, ", "))
+	fs.StringVar(&of.columns, "columns", "", "comma-separated columns for table and csv output: "+columnNames()+
+		"\n(default for table: "+defaultTableColumns+")")
+	fs.StringVar(&of.template, "template", "", "Go template applied to each entry; implies -output template")
+	fs.IntVar(&of.width, "width", 0, "table width in characters (default: terminal width, unlimited when piped)")
+}
+
+// writer validates the flags and returns a function that writes entries in
+// the selected format.
+func (of *outputFlags) writer() (func(w io.Writer, entries []arxiv.EntryMetadata) error, error) {
+	format := of.format
+	if of.template != "" {
+		if format != "table" && format != "template" {
+			return nil, usageErrorf("-template cannot be used with -output %s", format)
+		}
+		format = "template"
+	}
+
+	switch format {
+	case "table", "csv":
+		spec := of.columns
+		if spec == "" {
+			spec = defaultTableColumns
+			if format == "csv" {
+				spec = defaultCSVColumns
+			}
+		}
+		cols, err := parseColumns(spec)
+		if err != nil {
+			return nil, err
+		}
+		if format == "csv" {
+			return func(w io.Writer, entries []arxiv.EntryMetadata) error {
+				return writeCSV(w, entries, cols)
+			}, nil
+		}
+		return func(w io.Writer, entries []arxiv.EntryMetadata) error {
+			width := of.width
+			if width == 0 {
+				width = terminalWidth(w)
+			}
+			return writeTable(w, entries, cols, width)
+		}, nil
+	case "json":
+		return writeJSON, nil
+	case "jsonl":
+		return writeJSONL, nil
+	case "bibtex":
+		return arxiv.WriteBibTeX, nil
+	case "markdown":
+		return writeMarkdown, nil
+	case "template":
+		if of.template == "" {
+			return nil, usageErrorf("-output template requires -template")
+		}
+		tmpl, err := template.New("entry").Funcs(templateFuncs).Parse(of.template)
+		if err != nil {
+			return nil, usageErrorf("invalid template: %v", err)
+		}
+		return func(w io.Writer, entries []arxiv.EntryMetadata) error {
+			return writeTemplate(w, entries, tmpl)
+		}, nil
+	default:
+		return nil, usageErrorf("unknown output format %q", of.format)
+	}
+}
+
+func columnNames() string {
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.name
+	}
+	return strings.Join(names, ", ")
+}
+
+func parseColumns(spec string) ([]column, error) {
+	var cols []column
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, col := range columns {
+			if col.name == name {
+				cols = append(cols, col)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, usageErrorf("unknown column %q", name)
+		}
+	}
+	if len(cols) == 0 {
+		return nil, usageErrorf("no columns selected")
+	}
+	return cols, nil
+}
+
+// terminalWidth returns the width of the terminal w writes to, or 0 if w is
+// not a terminal.
+func terminalWidth(w io.Writer) int {
+	f, ok := w.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return 0
+	}
+	if width, _, err := term.GetSize(int(f.Fd())); err == nil && width > 0 {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return 80
+}
+
+// tableGap is the space between table columns.
+const tableGap = 2
+
+// minFlexWidth is the narrowest a truncated column is allowed to become.
+const minFlexWidth = 8
+
+// writeTable prints entries as an aligned table. If width is positive, flexible
+// columns such as the title are shortened so that rows fit within it.
+func writeTable(w io.Writer, entries []arxiv.EntryMetadata, cols []column, width int) error {
+	rows := make([][]string, len(entries))
+	natural := make([]int, len(cols))
+	for i, col := range cols {
+		natural[i] = len([]rune(col.header))
+	}
+	for r, entry := range entries {
+		rows[r] = make([]string, len(cols))
+		for i, col := range cols {
+			rows[r][i] = col.value(entry)
+			natural[i] = max(natural[i], len([]rune(rows[r][i])))
+		}
+	}
+
+	limits := fitColumns(cols, natural, width)
+	tw := tabwriter.NewWriter(w, 0, 8, tableGap, ' ', 0)
+	writeRow := func(cells []string) {
+		for i, cell := range cells {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprint(tw, truncate(cell, limits[i]))
+		}
+		fmt.Fprint(tw, "\n")
+	}
+
+	headers := make([]string, len(cols))
+	rules := make([]string, len(cols))
+	for i, col := range cols {
+		headers[i] = col.header
+		rules[i] = strings.Repeat("-", min(len([]rune(col.header)), limits[i]))
+	}
+	writeRow(headers)
+	writeRow(rules)
+	for _, row := range rows {
+		writeRow(row)
+	}
+	return tw.Flush()
+}
+
+// fitColumns returns the maximum width of each column so that the table fits
+// in width. Fixed columns keep their natural width and the remaining space is
+// shared evenly among flexible columns, with narrow columns giving the space
+// they do not need to wider ones.
+func fitColumns(cols []column, natural []int, width int) []int {
+	limits := append([]int(nil), natural...)
+	total := tableGap * (len(cols) - 1)
+	for _, n := range natural {
+		total += n
+	}
+	if width <= 0 || total <= width {
+		return limits
+	}
+
+	available := width - tableGap*(len(cols)-1)
+	var flex []int
+	for i, col := range cols {
+		if col.flex {
+			flex = append(flex, i)
+		} else {
+			available -= natural[i]
+		}
+	}
+	slices.SortStableFunc(flex, func(a, b int) int { return natural[a] - natural[b] })
+	for n, i := range flex {
+		share := available / (len(flex) - n)
+		limits[i] = min(natural[i], max(share, minFlexWidth))
+		available -= limits[i]
+	}
+	return limits
+}
+
+func writeCSV(w io.Writer, entries []arxiv.EntryMetadata, cols []column) error {
+	cw := csv.NewWriter(w)
+	record := make([]string, len(cols))
+	for i, col := range cols {
+		record[i] = col.name
+	}
+	if err := cw.Write(record); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		for i, col := range cols {
+			record[i] = col.value(entry)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeJSON(w io.Writer, entries []arxiv.EntryMetadata) error {
+	if entries == nil {
+		entries = []arxiv.EntryMetadata{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+func writeJSONL(w io.Writer, entries []arxiv.EntryMetadata) error {
+	enc := json.NewEncoder(w)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markdownEscaper escapes characters that would otherwise be read as
+// Markdown link or emphasis syntax.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, `[`, `\[`, `]`, `\]`, `*`, `\*`, `_`, `\_`, "`", "\\`",
+)
+
+// writeMarkdown prints entries as a Markdown list linking each title to its
+// abstract page.
+func writeMarkdown(w io.Writer, entries []arxiv.EntryMetadata) error {
+	for _, entry := range entries {
+		title := markdownEscaper.Replace(oneLine(entry.Title))
+		if entry.AbstractUrl != "" {
+			title = fmt.Sprintf("[%s](%s)", title, entry.AbstractUrl)
+		}
+		line := "- " + title
+		if authors := entry.AuthorNames(); len(authors) > 0 {
+			line += " — " + markdownEscaper.Replace(strings.Join(authors, ", "))
+		}
+		if y := year(entry); y != "" {
+			line += " (" + y + ")"
+		}
+		line += ". arXiv:" + entry.ArxivID()
+		if entry.PrimaryCategory.Term != "" {
+			line += " [" + entry.PrimaryCategory.Term + "]"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateFuncs are the functions available to -template.
+var templateFuncs = template.FuncMap{
+	"join":    strings.Join,
+	"oneline": oneLine,
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"bibtex":  arxiv.BibTeX,
+}
+
+// writeTemplate executes tmpl for each entry, ending each result with a
+// newline unless the template already does.
+func writeTemplate(w io.Writer, entries []arxiv.EntryMetadata, tmpl *template.Template) error {
+	for _, entry := range entries {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, entry); err != nil {
+			return err
+		}
+		out := b.String()
+		if !strings.HasSuffix(out, "\n") {
+			out += "\n"
+		}
+		if _, err := io.WriteString(w, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// authorSummary returns the first author's name, followed by "et al." if
+// there are more.
+func authorSummary(entry arxiv.EntryMetadata) string {
+	switch len(entry.Authors) {
+	case 0:
+		return ""
+	case 1:
+		return entry.Authors[0].Name
+	default:
+		return entry.Authors[0].Name + " et al."
+	}
+}
+
+func year(entry arxiv.EntryMetadata) string {
+	if entry.Published.IsZero() {
+		return ""
+	}
+	return fmt.Sprint(entry.Published.Year())
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func categories(entry arxiv.EntryMetadata) string {
+	terms := make([]string, len(entry.Categories))
+	for i, category := range entry.Categories {
+		terms[i] = category.Term
+	}
+	return strings.Join(terms, " ")
+}
+
+// oneLine collapses the line breaks and repeated spaces arXiv leaves in
+// titles and abstracts.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	if n <= 1 {
+		return string(runes[:n])
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+var searchCommand = &command{
+	name:    "search",
+	usage:   "[flags] [query]",
+	summary: "search arXiv and print matching entries",
+	help: `
+Search runs a query against the arXiv API and prints one page of results.
+The query may be given with -query or as the remaining arguments, using the
+arXiv query syntax, for example:
+
+	arxiv search 'ti:"graph neural" AND cat:cs.LG'
+	arxiv search -max-results 50 -sort-by submittedDate au:Hinton
+
+Categories in cat: terms are checked against arXiv's taxonomy, with a warning
+for any it does not list; run 'arxiv categories' to list them.
+
+Results are printed as a table sized to the terminal by default. Use -output
+to choose another format, and -columns to pick table or CSV columns:
+
+	arxiv search -output csv -columns id,title,published cat:cs.CL > cl.csv
+	arxiv search -output jsonl cat:cs.LG | jq .title
+	arxiv search -template '{{.ArxivID}} {{oneline .Title}}' cat:cs.AI
+
+Templates receive each entry as an arxiv.EntryMetadata and may use the
+functions join, oneline, upper, lower and bibtex.`,
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, a *app, args []string) error {
+		var sf searchFlags
+		var of outputFlags
+		sf.register(fs)
+		of.register(fs)
+		return func(ctx context.Context, a *app, args []string) error {
+			write, err := of.writer()
+			if err != nil {
+				return err
+			}
+			params, err := sf.params(a, args)
+			if err != nil {
+				return err
+			}
+			if params.Query == "" {
+				return usageErrorf("a query is required")
+			}
+			response, err := a.newClient().Search(ctx, params)
+			if err != nil {
+				return err
+			}
+			return write(a.stdout, response.Entries)
+		}
+	},
+}
+
+var getCommand = &command{
+	name:    "get",
+	usage:   "<id>...",
+	summary: "fetch entries by arXiv ID",
+	help: `
+Get fetches the entries with the given arXiv IDs. IDs may be separated by
+spaces or commas and may include a version, for example:
+
+	arxiv get 2408.03982 2408.03988v2
+	arxiv get cond-mat/0102536,hep-th/9901001
+	arxiv get -output bibtex 2408.03982
+
+See 'arxiv help search' for the output formats.`,
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, a *app, args []string) error {
+		var of outputFlags
+		of.register(fs)
+		return func(ctx context.Context, a *app, args []string) error {
+			write, err := of.writer()
+			if err != nil {
+				return err
+			}
+			ids := splitIDs(args)
+			if len(ids) == 0 {
+				return usageErrorf("at least one arXiv ID is required")
+			}
+			response, err := a.newClient().Search(ctx, arxiv.SearchParams{
+				IdList:     ids,
+				MaxResults: len(ids),
+			})
+			if err != nil {
+				return err
+			}
+			return write(a.stdout, response.Entries)
+		}
+	},
+}
+
+// searchFlags are the flags shared by commands that run a search query.
+type searchFlags struct {
+	query      string
+	start      int
+	maxResults int
+	sortBy     string
+	sortOrder  string
+}
+
+func (sf *searchFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&sf.query, "query", "", "search query in arXiv syntax (default: remaining arguments)")
+	fs.IntVar(&sf.start, "start", 0, "index of the first result")
+	fs.IntVar(&sf.maxResults, "max-results", 10, "maximum number of results per request (at most 2000)")
+	fs.StringVar(&sf.sortBy, "sort-by", "", "sort field: relevance, lastUpdatedDate or submittedDate")
+	fs.StringVar(&sf.sortOrder, "sort-order", "", "sort order: ascending or descending")
+}
+
+// params builds search parameters from the flags, taking the query from the
+// remaining arguments if -query was not given. A query of the form "@name"
+// is replaced by the saved query of that name.
+func (sf *searchFlags) params(a *app, args []string) (arxiv.SearchParams, error) {
+	query := sf.query
+	if query == "" {
+		query = strings.Join(args, " ")
+	} else if len(args) > 0 {
+		return arxiv.SearchParams{}, usageErrorf("unexpected arguments with -query: %s", strings.Join(args, " "))
+	}
+	query, err := a.settings.query(strings.TrimSpace(query))
+	if err != nil {
+		return arxiv.SearchParams{}, err
+	}
+
+	params := arxiv.SearchParams{
+		Query:      strings.TrimSpace(query),
+		Start:      sf.start,
+		MaxResults: sf.maxResults,
+		SortBy:     arxiv.SortBy(sf.sortBy),
+		SortOrder:  arxiv.SortOrder(sf.sortOrder),
+	}
+	switch params.SortBy {
+	case "", arxiv.SortByRelevance, arxiv.SortByLastUpdatedDate, arxiv.SortBySubmittedDate:
+	default:
+		return arxiv.SearchParams{}, usageErrorf("invalid -sort-by %q", sf.sortBy)
+	}
+	switch params.SortOrder {
+	case "", arxiv.SortOrderAscending, arxiv.SortOrderDescending:
+	default:
+		return arxiv.SearchParams{}, usageErrorf("invalid -sort-order %q", sf.sortOrder)
+	}
+	if params.Query != "" {
+		q, err := arxiv.ParseSearchQuery(params.Query)
+		if err != nil {
+			return arxiv.SearchParams{}, usageErrorf("invalid query: %v", err)
+		}
+		warnCategories(a, q)
+	}
+	if err := params.Validate(); err != nil {
+		return arxiv.SearchParams{}, usageErrorf("%v", err)
+	}
+	return params, nil
+}
+
+// warnCategories prints a warning if a category of q is not in arXiv's
+// taxonomy. The query is still sent, since the taxonomy may lag behind arXiv.
+func warnCategories(a *app, q *arxiv.SearchQuery) {
+	if err := q.Validate(); err != nil {
+		fmt.Fprintf(a.stderr, "warning: %v\n", err)
+	}
+}
+
+// splitIDs splits arguments on commas and drops empty IDs.
+func splitIDs(args []string) []string {
+	var ids []string
+	for _, arg := range args {
+		for _, id := range strings.Split(arg, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
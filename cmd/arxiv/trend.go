@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+var trendCommand = &command{
+	name:    "trend",
+	usage:   "-from <date> [flags] [query]",
+	summary: "count papers matching a query over time",
+	help: `
+Trend counts the papers submitted in each period that match a query, without
+downloading them: each count is one request for a single result. Counts may
+be split by category with -cat and by term with -term, for example:
+
+	arxiv trend -from 2023-01 -cat cs.CL 'abs:"large language model"'
+	arxiv trend -from 2020 -period year -cat cs.CL,cs.LG -term transformer -term 'ti:diffusion'
+
+Dates are given as YYYY, YYYY-MM or YYYY-MM-DD. Terms in query syntax are
+used as is; others are searched for in all fields. The output is a sparkline
+for each series by default, or CSV with -output csv:
+
+	arxiv trend -from 2024-01 -output csv cat:cs.CL > cl.csv`,
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, a *app, args []string) error {
+		query := fs.String("query", "", "search query in arXiv syntax (default: remaining arguments)")
+		from := fs.String("from", "", "start date (required)")
+		to := fs.String("to", "", "end date, exclusive (default now)")
+		period := fs.String("period", "month", "period to count by: day, week, month, quarter or year")
+		cats := fs.String("cat", "", "comma-separated categories to count separately")
+		var terms stringsFlag
+		fs.Var(&terms, "term", "term to count separately (may be repeated)")
+		output := fs.String("output", "spark", "output format: spark or csv")
+		ascii := fs.Bool("ascii", false, "draw sparklines with ASCII characters only")
+		return func(ctx context.Context, a *app, args []string) error {
+			if *output != "spark" && *output != "csv" {
+				return usageErrorf("unsupported output %q", *output)
+			}
+			q := *query
+			if q == "" {
+				q = strings.Join(args, " ")
+			} else if len(args) > 0 {
+				return usageErrorf("unexpected arguments with -query: %s", strings.Join(args, " "))
+			}
+			q, err := a.settings.query(strings.TrimSpace(q))
+			if err != nil {
+				return err
+			}
+			var searchQuery *arxiv.SearchQuery
+			if q = strings.TrimSpace(q); q != "" {
+				if searchQuery, err = arxiv.ParseSearchQuery(q); err != nil {
+					return usageErrorf("invalid query: %v", err)
+				}
+				warnCategories(a, searchQuery)
+			}
+
+			opts := arxiv.TrendOptions{Categories: splitIDs([]string{*cats}), Terms: terms}
+			for _, category := range opts.Categories {
+				warnCategories(a, arxiv.NewSearchQuery().Category(category))
+			}
+			if opts.Period, err = arxiv.ParsePeriod(*period); err != nil {
+				return usageErrorf("%v", err)
+			}
+			if *from == "" {
+				return usageErrorf("-from is required")
+			}
+			if opts.Start, err = parseTrendDate(*from); err != nil {
+				return usageErrorf("invalid -from: %v", err)
+			}
+			if *to != "" {
+				if opts.End, err = parseTrendDate(*to); err != nil {
+					return usageErrorf("invalid -to: %v", err)
+				}
+			}
+			if searchQuery == nil && len(opts.Categories) == 0 && len(opts.Terms) == 0 {
+				return usageErrorf("a query, -cat or -term is required")
+			}
+
+			trend, err := a.newClient().Trend(ctx, searchQuery, opts)
+			if err != nil {
+				return err
+			}
+			if *output == "csv" {
+				return trend.WriteCSV(a.stdout)
+			}
+			ramp := arxiv.SparkBlocks
+			if *ascii {
+				ramp = arxiv.SparkASCII
+			}
+			return writeSparklines(a, trend, ramp)
+		}
+	},
+}
+
+// writeSparklines prints a line for each series of a trend: its label, a
+// sparkline of its counts, and their range and total.
+func writeSparklines(a *app, trend arxiv.Trend, ramp string) error {
+	if len(trend.Windows) > 0 {
+		fmt.Fprintf(a.stdout, "%s to %s\n", trend.Windows[0].Start.Format(time.DateOnly),
+			trend.Windows[len(trend.Windows)-1].End.Format(time.DateOnly))
+	}
+	tw := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	for _, s := range trend.Series {
+		low, high := 0, 0
+		if len(s.Counts) > 0 {
+			low, high = s.Counts[0], s.Counts[0]
+		}
+		for _, n := range s.Counts {
+			low, high = min(low, n), max(high, n)
+		}
+		fmt.Fprintf(tw, "%s\t%s\tmin %d\tmax %d\ttotal %d\n", s.Label, arxiv.Sparkline(s.Counts, ramp), low, high, s.Total())
+	}
+	return tw.Flush()
+}
+
+// parseTrendDate parses a date given as YYYY, YYYY-MM or YYYY-MM-DD, in UTC.
+func parseTrendDate(s string) (time.Time, error) {
+	for _, layout := range []string{time.DateOnly, "2006-01", "2006"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not a date of the form YYYY, YYYY-MM or YYYY-MM-DD", s)
+}
+
+// stringsFlag is a flag that may be repeated, collecting its values.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
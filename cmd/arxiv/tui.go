@@ -0,0 +1,474 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+)
+
+var tuiCommand = &command{
+	name:    "tui",
+	usage:   "[flags] [query]",
+	summary: "browse search results interactively",
+	help: `
+Tui opens an interactive browser for the results of a query. The upper pane
+lists one page of results and the lower pane shows the abstract, authors,
+categories and comment of the highlighted entry.
+
+Keys:
+	j, down      next entry          k, up       previous entry
+	n, right     next page           p, left     previous page
+	g            first entry         G           last entry
+	space        select entry        a           select all on page
+	o, enter     open abstract page  e           export selected entries
+	q, ctrl-c    quit
+
+Export writes the selected entries, or the highlighted one if none are
+selected, to -export-file in -export-format.`,
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, a *app, args []string) error {
+		var sf searchFlags
+		sf.register(fs)
+		exportFile := fs.String("export-file", "arxiv-export.bib", "file written by the export key")
+		exportFormat := fs.String("export-format", "bibtex", "format written by the export key: bibtex, json, jsonl, csv or markdown")
+		return func(ctx context.Context, a *app, args []string) error {
+			params, err := sf.params(a, args)
+			if err != nil {
+				return err
+			}
+			if params.Query == "" {
+				return usageErrorf("a query is required")
+			}
+			of := outputFlags{format: *exportFormat}
+			if of.format == "table" || of.format == "template" {
+				return usageErrorf("-export-format cannot be %s", of.format)
+			}
+			write, err := of.writer()
+			if err != nil {
+				return err
+			}
+
+			stdin, stdout := os.Stdin, os.Stdout
+			if !term.IsTerminal(int(stdin.Fd())) || !term.IsTerminal(int(stdout.Fd())) {
+				return errors.New("tui requires an interactive terminal")
+			}
+
+			m := &browser{
+				client:     a.newClient(),
+				exportFile: *exportFile,
+				export:     write,
+				open:       openURL,
+				selected:   make(map[string]arxiv.EntryMetadata),
+			}
+			m.width, m.height = terminalSize(stdout)
+			if err := m.load(ctx, params); err != nil {
+				return err
+			}
+			return m.loop(ctx, stdin, stdout)
+		}
+	},
+}
+
+// browser is the state of the interactive result browser.
+type browser struct {
+	client     *arxiv.Client
+	page       arxiv.SearchResults
+	cursor     int // Index of the highlighted entry on the page
+	offset     int // Index of the first entry shown in the list pane
+	selected   map[string]arxiv.EntryMetadata
+	order      []string // Selected IDs in the order they were selected
+	status     string
+	width      int
+	height     int
+	exportFile string
+	export     func(w io.Writer, entries []arxiv.EntryMetadata) error
+	open       func(url string) error
+}
+
+// loop puts the terminal in raw mode, then reads and handles keys until the
+// user quits.
+func (m *browser) loop(ctx context.Context, in *os.File, out *os.File) error {
+	state, err := term.MakeRaw(int(in.Fd()))
+	if err != nil {
+		return err
+	}
+	defer term.Restore(int(in.Fd()), state)
+
+	w := bufio.NewWriter(out)
+	fmt.Fprint(w, "\x1b[?1049h\x1b[?25l") // Alternate screen, hide cursor
+	defer func() {
+		fmt.Fprint(w, "\x1b[?25h\x1b[?1049l")
+		w.Flush()
+	}()
+
+	buf := make([]byte, 16)
+	for {
+		m.width, m.height = terminalSize(out)
+		m.render(w)
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		n, err := in.Read(buf)
+		if err != nil {
+			return err
+		}
+		if m.handleKey(ctx, parseKey(buf[:n])) {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// parseKey turns the bytes of one key press into a key name.
+func parseKey(b []byte) string {
+	switch string(b) {
+	case "\x1b[A", "\x1bOA":
+		return "up"
+	case "\x1b[B", "\x1bOB":
+		return "down"
+	case "\x1b[C", "\x1bOC":
+		return "right"
+	case "\x1b[D", "\x1bOD":
+		return "left"
+	case "\x1b[5~":
+		return "pgup"
+	case "\x1b[6~":
+		return "pgdown"
+	case "\r", "\n":
+		return "enter"
+	case " ":
+		return "space"
+	case "\x03":
+		return "ctrl-c"
+	}
+	return string(b)
+}
+
+// handleKey applies a key press and reports whether the browser should exit.
+func (m *browser) handleKey(ctx context.Context, key string) bool {
+	m.status = ""
+	entries := m.page.Entries
+	switch key {
+	case "q", "ctrl-c":
+		return true
+	case "j", "down":
+		m.moveCursor(1)
+	case "k", "up":
+		m.moveCursor(-1)
+	case "g":
+		m.moveCursor(-len(entries))
+	case "G":
+		m.moveCursor(len(entries))
+	case "n", "right", "pgdown":
+		if !arxiv.SearchHasMoreResults(m.page) {
+			m.status = "Already on the last page"
+			break
+		}
+		m.turnPage(ctx, m.client.SearchNext)
+	case "p", "left", "pgup":
+		if !arxiv.SearchHasPreviousResults(m.page) {
+			m.status = "Already on the first page"
+			break
+		}
+		m.turnPage(ctx, m.client.SearchPrevious)
+	case "space":
+		if entry, ok := m.current(); ok {
+			m.toggle(entry)
+		}
+	case "a":
+		for _, entry := range entries {
+			if _, ok := m.selected[entry.ArxivID()]; !ok {
+				m.toggle(entry)
+			}
+		}
+	case "o", "enter":
+		entry, ok := m.current()
+		if !ok || entry.AbstractUrl == "" {
+			m.status = "No abstract page for this entry"
+			break
+		}
+		if err := m.open(entry.AbstractUrl); err != nil {
+			m.status = "Could not open browser: " + err.Error()
+		} else {
+			m.status = "Opened " + entry.AbstractUrl
+		}
+	case "e":
+		m.exportSelected()
+	}
+	return false
+}
+
+// load runs the first search.
+func (m *browser) load(ctx context.Context, params arxiv.SearchParams) error {
+	page, err := m.client.Search(ctx, params)
+	if err != nil {
+		return err
+	}
+	m.page = page
+	return nil
+}
+
+// turnPage replaces the page with the one returned by fetch, keeping the
+// current page if the request fails.
+func (m *browser) turnPage(ctx context.Context, fetch func(context.Context, arxiv.SearchResults) (arxiv.SearchResults, error)) {
+	page, err := fetch(ctx, m.page)
+	if err != nil {
+		m.status = "Error: " + err.Error()
+		return
+	}
+	m.page = page
+	m.cursor, m.offset = 0, 0
+}
+
+func (m *browser) moveCursor(delta int) {
+	m.cursor = max(0, min(m.cursor+delta, len(m.page.Entries)-1))
+}
+
+func (m *browser) current() (arxiv.EntryMetadata, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.page.Entries) {
+		return arxiv.EntryMetadata{}, false
+	}
+	return m.page.Entries[m.cursor], true
+}
+
+func (m *browser) toggle(entry arxiv.EntryMetadata) {
+	id := entry.ArxivID()
+	if _, ok := m.selected[id]; ok {
+		delete(m.selected, id)
+		for i, selected := range m.order {
+			if selected == id {
+				m.order = append(m.order[:i], m.order[i+1:]...)
+				break
+			}
+		}
+		return
+	}
+	m.selected[id] = entry
+	m.order = append(m.order, id)
+}
+
+// exportSelected writes the selected entries, or the highlighted entry if
+// none are selected, to the export file.
+func (m *browser) exportSelected() {
+	var entries []arxiv.EntryMetadata
+	for _, id := range m.order {
+		entries = append(entries, m.selected[id])
+	}
+	if len(entries) == 0 {
+		entry, ok := m.current()
+		if !ok {
+			m.status = "Nothing to export"
+			return
+		}
+		entries = append(entries, entry)
+	}
+
+	f, err := os.Create(m.exportFile)
+	if err == nil {
+		err = m.export(f, entries)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		m.status = "Export failed: " + err.Error()
+		return
+	}
+	m.status = fmt.Sprintf("Exported %d entries to %s", len(entries), m.exportFile)
+}
+
+// Layout of the screen, from top to bottom: a header line, the list pane,
+// a separator, the detail pane and a status line.
+func (m *browser) listHeight() int {
+	return max(3, (m.height-3)*2/5)
+}
+
+func (m *browser) detailHeight() int {
+	return max(1, m.height-3-m.listHeight())
+}
+
+// render draws the whole screen to w.
+func (m *browser) render(w io.Writer) {
+	var lines []string
+	lines = append(lines, reverse(pad(m.header(), m.width)))
+
+	listHeight := m.listHeight()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+listHeight {
+		m.offset = m.cursor - listHeight + 1
+	}
+	for i := m.offset; i < m.offset+listHeight; i++ {
+		if i >= len(m.page.Entries) {
+			lines = append(lines, "")
+			continue
+		}
+		line := pad(m.listLine(i), m.width)
+		if i == m.cursor {
+			line = reverse(line)
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, strings.Repeat("─", max(m.width, 1)))
+	detail := m.detailLines()
+	for i := 0; i < m.detailHeight(); i++ {
+		if i < len(detail) {
+			lines = append(lines, truncate(detail[i], m.width))
+		} else {
+			lines = append(lines, "")
+		}
+	}
+
+	status := m.status
+	if status == "" {
+		status = "j/k move  n/p page  space select  o open  e export  q quit"
+	}
+	lines = append(lines, truncate(status, m.width))
+
+	fmt.Fprint(w, "\x1b[H")
+	for i, line := range lines {
+		fmt.Fprint(w, line, "\x1b[K")
+		if i < len(lines)-1 {
+			fmt.Fprint(w, "\r\n")
+		}
+	}
+	fmt.Fprint(w, "\x1b[J")
+}
+
+func (m *browser) header() string {
+	page := m.page
+	if len(page.Entries) == 0 {
+		return fmt.Sprintf(" %s — no results", page.Params.Query)
+	}
+	first := page.StartIndex + 1
+	last := page.StartIndex + len(page.Entries)
+	header := fmt.Sprintf(" %s — %d–%d of %d", page.Params.Query, first, last, page.TotalResults)
+	if len(m.selected) > 0 {
+		header += fmt.Sprintf(" — %d selected", len(m.selected))
+	}
+	return header
+}
+
+func (m *browser) listLine(i int) string {
+	entry := m.page.Entries[i]
+	mark := "[ ]"
+	if _, ok := m.selected[entry.ArxivID()]; ok {
+		mark = "[x]"
+	}
+	prefix := fmt.Sprintf("%s %-18s %4s  ", mark, entry.ArxivID(), year(entry))
+	return prefix + truncate(oneLine(entry.Title), max(m.width-len([]rune(prefix)), 1))
+}
+
+// detailLines returns the contents of the detail pane for the highlighted entry.
+func (m *browser) detailLines() []string {
+	entry, ok := m.current()
+	if !ok {
+		return nil
+	}
+	width := max(m.width, 20)
+
+	var lines []string
+	lines = append(lines, wrapText(oneLine(entry.Title), width)...)
+	lines = append(lines, "")
+	add := func(label, value string) {
+		if value != "" {
+			lines = append(lines, wrapText(label+": "+value, width)...)
+		}
+	}
+	add("Authors", strings.Join(entry.AuthorNames(), ", "))
+	terms := []string{}
+	if entry.PrimaryCategory.Term != "" {
+		terms = append(terms, entry.PrimaryCategory.Term)
+	}
+	for _, category := range entry.Categories {
+		if category.Term != entry.PrimaryCategory.Term {
+			terms = append(terms, category.Term)
+		}
+	}
+	add("Categories", strings.Join(terms, ", "))
+	add("Published", formatDate(entry.Published))
+	if !entry.Updated.Equal(entry.Published) {
+		add("Updated", formatDate(entry.Updated))
+	}
+	add("Comment", oneLine(entry.Comment))
+	add("Journal", oneLine(entry.JournalReference))
+	add("DOI", entry.DOI)
+	add("URL", entry.AbstractUrl)
+	lines = append(lines, "")
+	lines = append(lines, wrapText(oneLine(entry.Summary), width)...)
+	return lines
+}
+
+// wrapText breaks s into lines of at most width runes at word boundaries.
+func wrapText(s string, width int) []string {
+	var lines []string
+	var line []rune
+	for _, word := range strings.Fields(s) {
+		w := []rune(word)
+		if len(line) > 0 && len(line)+1+len(w) > width {
+			lines = append(lines, string(line))
+			line = nil
+		}
+		if len(line) > 0 {
+			line = append(line, ' ')
+		}
+		line = append(line, w...)
+	}
+	if len(line) > 0 {
+		lines = append(lines, string(line))
+	}
+	return lines
+}
+
+// pad extends s with spaces to width runes so that highlighting covers the
+// whole line.
+func pad(s string, width int) string {
+	s = truncate(s, width)
+	if n := len([]rune(s)); n < width {
+		s += strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+func reverse(s string) string {
+	return "\x1b[7m" + s + "\x1b[0m"
+}
+
+// terminalSize returns the size of the terminal f is connected to, falling
+// back to 80x24.
+func terminalSize(f *os.File) (int, int) {
+	width, height, err := term.GetSize(int(f.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return 80, 24
+	}
+	return width, height
+}
+
+// openURL opens url in the user's web browser.
+func openURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	return cmd.Start()
+}
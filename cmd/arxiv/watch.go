@@ -0,0 +1,380 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Epistemic-Technology/arxiv/arxiv"
+	"github.com/Epistemic-Technology/arxiv/internal/lockfile"
+)
+
+var watchCommand = &command{
+	name:    "watch",
+	usage:   "[flags] [add <name> <query> | remove <name> | list | run [name...]]",
+	summary: "report new papers for saved queries",
+	help: `
+Watch keeps a list of saved queries in a state file and reports the papers
+submitted since the last run of each:
+
+	arxiv watch add llm 'cat:cs.CL AND abs:"large language model"'
+	arxiv watch add mine @mine      # a saved query from the config file
+	arxiv watch                     # same as 'arxiv watch run'
+	arxiv watch -output jsonl run llm
+	arxiv watch remove llm
+
+The first run of a query reports one page of the newest results. Later runs
+page through the papers submitted since, oldest first, and stop after
+-max-new of them, leaving the rest for the next run. New entries are printed
+in the -output format, or posted to -webhook as a JSON object
+{"watch": ..., "query": ..., "entries": [...]} per query. A query is only marked as seen once its entries have been
+delivered, so failed runs are retried next time. Flags go before the action.
+
+The state file defaults to $XDG_STATE_HOME/arxiv/watch.json
+(~/.local/state/arxiv/watch.json).`,
+	setup: func(fs *flag.FlagSet) func(ctx context.Context, a *app, args []string) error {
+		var of outputFlags
+		of.register(fs)
+		statePath := fs.String("state", "", "state file (default ~/.local/state/arxiv/watch.json)")
+		pageSize := fs.Int("max-results", 50, "entries requested per page")
+		maxNew := fs.Int("max-new", 500, "maximum entries reported per query and run")
+		webhook := fs.String("webhook", "", "URL to POST new entries to instead of printing them")
+		return func(ctx context.Context, a *app, args []string) error {
+			path := *statePath
+			if path == "" {
+				path = a.defaultStatePath()
+			}
+			if path == "" {
+				return usageErrorf("-state is required when $HOME is not set")
+			}
+			// Hold the lock from reading the state to saving it, so that
+			// overlapping runs do not lose each other's updates.
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+			lock, err := lockfile.Lock(path + ".lock")
+			if err != nil {
+				return fmt.Errorf("locking %s: %w", path, err)
+			}
+			defer lock.Close()
+			state, err := loadWatchState(path)
+			if err != nil {
+				return err
+			}
+
+			action := "run"
+			if len(args) > 0 {
+				action, args = args[0], args[1:]
+			}
+			switch action {
+			case "add":
+				if len(args) < 2 {
+					return usageErrorf("add requires a name and a query")
+				}
+				name := args[0]
+				query, err := a.settings.query(strings.TrimSpace(strings.Join(args[1:], " ")))
+				if err != nil {
+					return err
+				}
+				if !arxiv.IsValidSearchQuery(query) {
+					_, err := arxiv.ParseSearchQuery(query)
+					return usageErrorf("invalid query: %v", err)
+				}
+				if w, ok := state.Watches[name]; ok && w.Query == query {
+					return nil
+				}
+				state.Watches[name] = &watch{Query: query}
+				return state.save(path)
+			case "remove":
+				if len(args) != 1 {
+					return usageErrorf("remove requires a name")
+				}
+				if _, ok := state.Watches[args[0]]; !ok {
+					return fmt.Errorf("no watch named %q", args[0])
+				}
+				delete(state.Watches, args[0])
+				return state.save(path)
+			case "list":
+				tw := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+				for _, name := range state.names() {
+					w := state.Watches[name]
+					newest := "never run"
+					if !w.Newest.IsZero() {
+						newest = formatDate(w.Newest)
+					}
+					fmt.Fprintf(tw, "%s\t%s\t%s\n", name, newest, w.Query)
+				}
+				return tw.Flush()
+			case "run":
+			default:
+				return usageErrorf("unknown action %q", action)
+			}
+
+			write, err := of.writer()
+			if err != nil {
+				return err
+			}
+			names := args
+			if len(names) == 0 {
+				names = state.names()
+			}
+			if len(names) == 0 {
+				return usageErrorf("no watches; add one with 'arxiv watch add <name> <query>'")
+			}
+
+			client := a.newClient()
+			var all []arxiv.EntryMetadata
+			var updated []func()
+			var errs []error
+			for _, name := range names {
+				w, ok := state.Watches[name]
+				if !ok {
+					errs = append(errs, fmt.Errorf("no watch named %q", name))
+					continue
+				}
+				entries, held, err := w.newEntries(ctx, client, *pageSize, *maxNew)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", name, err))
+					continue
+				}
+				if held > 0 {
+					fmt.Fprintf(a.stderr, "%s: %d new, %d more next run\n", name, len(entries), held)
+				} else {
+					fmt.Fprintf(a.stderr, "%s: %d new\n", name, len(entries))
+				}
+				if *webhook != "" && len(entries) > 0 {
+					if err := postWebhook(ctx, *webhook, name, w.Query, entries); err != nil {
+						errs = append(errs, fmt.Errorf("%s: %w", name, err))
+						continue
+					}
+				}
+				all = append(all, entries...)
+				updated = append(updated, func() { w.markSeen(entries) })
+			}
+
+			if *webhook == "" {
+				if err := write(a.stdout, dedupeEntries(all)); err != nil {
+					return err
+				}
+			}
+			for _, markSeen := range updated {
+				markSeen()
+			}
+			if len(updated) > 0 {
+				if err := state.save(path); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			return errors.Join(errs...)
+		}
+	},
+}
+
+// watchState is the contents of the watch state file.
+type watchState struct {
+	Watches map[string]*watch `json:"watches"`
+}
+
+// watch is a saved query and the newest submission reported for it.
+type watch struct {
+	Query   string    `json:"query"`
+	Newest  time.Time `json:"newest"`         // Publication time of the newest entry reported
+	Seen    []string  `json:"seen,omitempty"` // Base IDs of the reported entries published at Newest
+	LastRun time.Time `json:"lastRun"`
+}
+
+func loadWatchState(path string) (*watchState, error) {
+	state := &watchState{Watches: make(map[string]*watch)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	if state.Watches == nil {
+		state.Watches = make(map[string]*watch)
+	}
+	return state, nil
+}
+
+// save writes the state to path, replacing the previous file atomically.
+func (s *watchState) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *watchState) names() []string {
+	names := make([]string, 0, len(s.Watches))
+	for name := range s.Watches {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// defaultStatePath returns the path of the watch state file used when -state
+// is not given, or "" if the user's state directory is unknown.
+func (a *app) defaultStatePath() string {
+	dir := a.getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home := a.getenv("HOME")
+		if home == "" {
+			return ""
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "arxiv", "watch.json")
+}
+
+// seen reports whether the entry was reported by an earlier run.
+func (w *watch) seen(entry arxiv.EntryMetadata) bool {
+	if entry.Published.After(w.Newest) {
+		return false
+	}
+	return entry.Published.Before(w.Newest) || slices.Contains(w.Seen, entry.BaseID())
+}
+
+// newEntries returns the entries of the query not reported before, newest
+// first. A watch that has never run returns the newest page. Later runs page
+// through the submissions since the newest one reported, oldest first, and
+// stop once they have limit entries, leaving the others for the next run;
+// held is the number left.
+func (w *watch) newEntries(ctx context.Context, client *arxiv.Client, pageSize, limit int) (entries []arxiv.EntryMetadata, held int, err error) {
+	if w.Newest.IsZero() {
+		page, err := client.Search(ctx, arxiv.SearchParams{
+			Query:      w.Query,
+			MaxResults: pageSize,
+			SortBy:     arxiv.SortBySubmittedDate,
+			SortOrder:  arxiv.SortOrderDescending,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+		entries = page.Entries
+		if limit > 0 && len(entries) > limit {
+			held = len(entries) - limit
+			entries = entries[held:]
+		}
+		return entries, held, nil
+	}
+
+	// Submission dates in queries have minute precision, so the range
+	// starts at the minute of the watermark and seen skips what was
+	// already reported in it.
+	const minute = "200601021504"
+	page, err := client.Search(ctx, arxiv.SearchParams{
+		Query: fmt.Sprintf("(%s) AND submittedDate:[%s TO %s]",
+			w.Query, w.Newest.UTC().Format(minute), time.Now().UTC().AddDate(0, 0, 1).Format(minute)),
+		MaxResults: pageSize,
+		SortBy:     arxiv.SortBySubmittedDate,
+		SortOrder:  arxiv.SortOrderAscending,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+pages:
+	for {
+		for i, entry := range page.Entries {
+			if w.seen(entry) {
+				continue
+			}
+			entries = append(entries, entry)
+			if limit > 0 && len(entries) == limit {
+				held = max(page.TotalResults-(page.StartIndex+i+1), 0)
+				break pages
+			}
+		}
+		if len(page.Entries) == 0 || !arxiv.SearchHasMoreResults(page) {
+			break
+		}
+		if page, err = client.SearchNext(ctx, page); err != nil {
+			return nil, 0, err
+		}
+	}
+	slices.Reverse(entries)
+	return entries, held, nil
+}
+
+// markSeen advances the watermark past the reported entries.
+func (w *watch) markSeen(entries []arxiv.EntryMetadata) {
+	w.LastRun = time.Now().UTC()
+	for _, entry := range entries {
+		switch {
+		case entry.Published.After(w.Newest):
+			w.Newest = entry.Published
+			w.Seen = []string{entry.BaseID()}
+		case entry.Published.Equal(w.Newest):
+			if id := entry.BaseID(); !slices.Contains(w.Seen, id) {
+				w.Seen = append(w.Seen, id)
+			}
+		}
+	}
+}
+
+// webhookPayload is the body posted to -webhook for each query with new entries.
+type webhookPayload struct {
+	Watch   string                `json:"watch"`
+	Query   string                `json:"query"`
+	Entries []arxiv.EntryMetadata `json:"entries"`
+}
+
+func postWebhook(ctx context.Context, url, name, query string, entries []arxiv.EntryMetadata) error {
+	body, err := json.Marshal(webhookPayload{Watch: name, Query: query, Entries: entries})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook: %s", resp.Status)
+	}
+	return nil
+}
+
+// dedupeEntries drops entries whose base ID appeared earlier in the list.
+func dedupeEntries(entries []arxiv.EntryMetadata) []arxiv.EntryMetadata {
+	seen := make(map[string]bool, len(entries))
+	var unique []arxiv.EntryMetadata
+	for _, entry := range entries {
+		if id := entry.BaseID(); !seen[id] {
+			seen[id] = true
+			unique = append(unique, entry)
+		}
+	}
+	return unique
+}
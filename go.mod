@@ -2,4 +2,11 @@ module github.com/Epistemic-Technology/arxiv
 
 go 1.23.2
 
-require golang.org/x/time v0.6.0
+require (
+	github.com/BurntSushi/toml v1.4.0
+	go.etcd.io/bbolt v1.3.11
+	golang.org/x/term v0.25.0
+	golang.org/x/time v0.6.0
+)
+
+require golang.org/x/sys v0.26.0 // indirect
@@ -0,0 +1,21 @@
+// Package lockfile takes advisory locks on files, to keep processes sharing
+// a file from interleaving their updates.
+package lockfile
+
+import "os"
+
+// Lock opens path, creating it if needed, and blocks until it holds an
+// exclusive advisory lock on it. Closing the returned file releases the
+// lock. File locking is only supported on Unix; elsewhere the file is opened
+// without a lock, and concurrent processes are not kept apart.
+func Lock(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lock(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
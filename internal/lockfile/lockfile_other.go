@@ -0,0 +1,9 @@
+//go:build !unix
+
+package lockfile
+
+import "os"
+
+func lock(f *os.File) error {
+	return nil
+}